@@ -0,0 +1,100 @@
+package yaml
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SharedAliases configures the decoder to set pointers decoded from an
+// alias to the same pointer as the anchored value they refer to, instead of
+// a copy, so that the values are shared as in the document. Only pointers
+// to the same type are shared, in struct fields, slices, arrays and maps
+// with string keys.
+func SharedAliases() DecodeOpt {
+	return func(d *Decoder) {
+		d.sharedAliases = true
+	}
+}
+
+// shareAliases updates the pointers decoded from aliases in the node tree to
+// those decoded from their anchors.
+func shareAliases(n *yaml.Node, o interface{}) {
+	v := reflect.ValueOf(o)
+	if n == nil || !v.IsValid() {
+		return
+	}
+	shareAliasesAt(n, v, nil, make(map[*yaml.Node]reflect.Value))
+}
+
+// shareAliasesAt walks the node and the value decoded from it, recording
+// the pointers of anchored nodes. set replaces the value, if it can be.
+func shareAliasesAt(n *yaml.Node, v reflect.Value, set func(reflect.Value), anchors map[*yaml.Node]reflect.Value) {
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return
+		}
+		n = n.Content[0]
+	}
+	if n.Kind == yaml.AliasNode {
+		if p, ok := anchors[resolveAlias(n)]; ok && set != nil && p.Type() == v.Type() {
+			set(p)
+		}
+		return
+	}
+	if n.Anchor != "" && v.Kind() == reflect.Ptr && !v.IsNil() {
+		anchors[n] = v
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if unmarshalsJSON(v.Type()) {
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		plan := cachedTypePlan(v.Type())
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			f := plan.field(n.Content[i].Value)
+			if f == nil {
+				continue
+			}
+			if fv, ok := fieldByIndex(v, f.index); ok {
+				shareAliasesAt(n.Content[i+1], fv, settable(fv), anchors)
+			}
+		}
+	case v.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		kt := v.Type().Key()
+		if kt.Kind() != reflect.String {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := reflect.ValueOf(n.Content[i].Value).Convert(kt)
+			if ev := v.MapIndex(k); ev.IsValid() {
+				m := v
+				shareAliasesAt(n.Content[i+1], ev, func(p reflect.Value) { m.SetMapIndex(k, p) }, anchors)
+			}
+		}
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && n.Kind == yaml.SequenceNode:
+		for i, c := range n.Content {
+			if i >= v.Len() {
+				break
+			}
+			ev := v.Index(i)
+			shareAliasesAt(c, ev, settable(ev), anchors)
+		}
+	}
+}
+
+// settable returns a function that sets the value, or nil if it cannot be
+// set.
+func settable(v reflect.Value) func(reflect.Value) {
+	if !v.CanSet() {
+		return nil
+	}
+	return v.Set
+}
@@ -0,0 +1,49 @@
+package yaml
+
+import "testing"
+
+type aliasNode struct {
+	Name string     `json:"name"`
+	Next *aliasNode `json:"next,omitempty"`
+}
+
+type aliasGraph struct {
+	Root   *aliasNode            `json:"root"`
+	Same   *aliasNode            `json:"same"`
+	List   []*aliasNode          `json:"list"`
+	ByName map[string]*aliasNode `json:"byName"`
+	Value  aliasNode             `json:"value"`
+}
+
+func TestSharedAliases(t *testing.T) {
+	y := []byte(`root: &root
+  name: a
+  next: &b {name: b}
+same: *root
+list: [*b, *root, {name: c}]
+byName: {b: *b}
+value: *root
+`)
+	var g aliasGraph
+	if err := UnmarshalWithOptions(y, &g); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if g.Same == g.Root || g.List[0] == g.Root.Next {
+		t.Errorf("UnmarshalWithOptions() shared pointers without SharedAliases")
+	}
+
+	g = aliasGraph{}
+	if err := UnmarshalWithOptions(y, &g, SharedAliases()); err != nil {
+		t.Fatalf("UnmarshalWithOptions(SharedAliases()) = %v", err)
+	}
+	if g.Same != g.Root || g.List[1] != g.Root || g.List[0] != g.Root.Next || g.ByName["b"] != g.Root.Next {
+		t.Errorf("UnmarshalWithOptions(SharedAliases()) = %+v; want shared pointers", g)
+	}
+	if g.List[2].Name != "c" || g.Value.Name != "a" || g.Value.Next == g.Root.Next {
+		t.Errorf("UnmarshalWithOptions(SharedAliases()) = %+v", g)
+	}
+	g.Root.Name = "changed"
+	if g.Same.Name != "changed" {
+		t.Errorf("Same.Name = %q; want changed", g.Same.Name)
+	}
+}
@@ -0,0 +1,121 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AnchorError is returned by the strict anchor checks when an alias refers
+// to an anchor that has not been defined, or when an anchor is defined but
+// never referenced. Line and Column are zero when the position is unknown.
+type AnchorError struct {
+	Anchor string
+	Line   int
+	Column int
+	Unused bool // true if the anchor is never referenced
+}
+
+func (e *AnchorError) Error() string {
+	if e.Unused {
+		return positionPrefix(e.Line, e.Column) + fmt.Sprintf("anchor &%s is defined but never used", e.Anchor)
+	}
+	return positionPrefix(e.Line, e.Column) + fmt.Sprintf("alias *%s refers to an undefined anchor", e.Anchor)
+}
+
+// CheckAliases configures the decoder to report aliases to undefined
+// anchors with an *AnchorError giving the position of the alias, instead of
+// the parser error.
+func CheckAliases() DecodeOpt {
+	return func(d *Decoder) {
+		d.checkAliases = true
+	}
+}
+
+// DisallowUnusedAnchors configures the decoder to return an *AnchorError
+// when a document defines an anchor that is never referenced, which is
+// usually a sign of a copy-paste mistake.
+func DisallowUnusedAnchors() DecodeOpt {
+	return func(d *Decoder) {
+		d.unusedAnchors = true
+	}
+}
+
+// unknownAnchor matches the go-yaml error for an undefined alias.
+var unknownAnchor = regexp.MustCompile(`unknown anchor '([^']*)' referenced`)
+
+// undefinedAliasError converts the parser error for an undefined alias into
+// an *AnchorError, using the source read so far to find the alias.
+func undefinedAliasError(err error, src []byte) error {
+	m := unknownAnchor.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	e := &AnchorError{Anchor: m[1]}
+	e.Line, e.Column = findAlias(src, m[1])
+	return e
+}
+
+// findAlias returns the position of the first reference to the anchor that
+// is not preceded by its definition.
+func findAlias(src []byte, name string) (int, int) {
+	defined := false
+	for i, line := range bytes.Split(src, []byte("\n")) {
+		for j := 0; j < len(line); j++ {
+			c := line[j]
+			if c == '#' && (j == 0 || line[j-1] == ' ' || line[j-1] == '\t') {
+				break
+			}
+			if (c != '*' && c != '&') || (j > 0 && !isAliasBoundary(line[j-1])) {
+				continue
+			}
+			end := j + 1 + len(name)
+			if !bytes.HasPrefix(line[j+1:], []byte(name)) || (end < len(line) && !isAliasBoundary(line[end])) {
+				continue
+			}
+			if c == '&' {
+				defined = true
+			} else if !defined {
+				return i + 1, j + 1
+			}
+		}
+	}
+	return 0, 0
+}
+
+func isAliasBoundary(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', ',', '[', ']', '{', '}', ':':
+		return true
+	}
+	return false
+}
+
+// checkUnusedAnchors returns an error for the first anchor in the node tree
+// that is never referenced by an alias.
+func checkUnusedAnchors(n *yaml.Node) error {
+	var anchors []*yaml.Node
+	used := make(map[*yaml.Node]bool)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.AliasNode {
+			used[n.Alias] = true
+			return
+		}
+		if n.Anchor != "" {
+			anchors = append(anchors, n)
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(n)
+	for _, a := range anchors {
+		if !used[a] {
+			return &AnchorError{Anchor: a.Anchor, Line: a.Line, Column: a.Column, Unused: true}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckAliases(t *testing.T) {
+	y := []byte("base: &base\n  a: 1\n# *other in a comment\nitems: [*base, *other]\n")
+	var v interface{}
+	err := UnmarshalWithOptions(y, &v, CheckAliases())
+	var ae *AnchorError
+	if !errors.As(err, &ae) {
+		t.Fatalf("UnmarshalWithOptions() = %v; want *AnchorError", err)
+	}
+	want := AnchorError{Anchor: "other", Line: 4, Column: 16}
+	if *ae != want {
+		t.Errorf("UnmarshalWithOptions() = %+v; want %+v", *ae, want)
+	}
+	if ae.Error() != "line 4, column 16: alias *other refers to an undefined anchor" {
+		t.Errorf("AnchorError.Error() = %q", ae.Error())
+	}
+
+	// Without the option the parser error is returned.
+	if err := UnmarshalWithOptions(y, &v); err == nil || errors.As(err, &ae) {
+		t.Errorf("UnmarshalWithOptions() = %v; want parser error", err)
+	}
+}
+
+func TestDisallowUnusedAnchors(t *testing.T) {
+	cases := []struct {
+		yaml string
+		want *AnchorError
+	}{
+		{"a: &x 1\nb: *x\n", nil},
+		{"base: &base {a: 1}\nc:\n  <<: *base\n", nil},
+		{"a: &x 1\nb: &y 2\nc: *x\n", &AnchorError{Anchor: "y", Line: 2, Column: 4, Unused: true}},
+	}
+	for _, c := range cases {
+		var v interface{}
+		err := UnmarshalWithOptions([]byte(c.yaml), &v, DisallowUnusedAnchors())
+		if c.want == nil {
+			if err != nil {
+				t.Errorf("UnmarshalWithOptions(%q) = %v; want nil", c.yaml, err)
+			}
+			continue
+		}
+		var ae *AnchorError
+		if !errors.As(err, &ae) || *ae != *c.want {
+			t.Errorf("UnmarshalWithOptions(%q) = %v; want %v", c.yaml, err, c.want)
+		}
+	}
+}
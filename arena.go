@@ -0,0 +1,134 @@
+package yaml
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// minArenaSlice is the smallest block of interface values allocated by an
+// arena.
+const minArenaSlice = 256
+
+// Arena holds scratch memory that is reused across decodes of similar
+// documents, such as the manifests handled by a webhook, to reduce the
+// work of the garbage collector in hot loops.
+//
+// The maps and slices created by the decoder for interface{} values are
+// taken from the arena, and remain owned by it: they are only valid until
+// Reset is called, after which they are cleared and handed out again by
+// later decodes. Copy anything that must outlive the next Reset. Documents
+// that can't be decoded in a single pass only reuse the arena's buffer for
+// the intermediate JSON. An Arena must not be used by more than one
+// goroutine at a time.
+type Arena struct {
+	maps  []map[string]interface{}
+	nmaps int           // maps handed out since the last reset
+	vals  []interface{} // block that slices are cut from
+	nvals int           // values of the block handed out
+	buf   bytes.Buffer  // intermediate JSON
+	dec   directDecoder
+}
+
+// NewArena provides an empty arena.
+func NewArena() *Arena {
+	return new(Arena)
+}
+
+// UseArena configures the decoder to take its scratch memory from the
+// arena. See Arena for the lifetime of the values decoded.
+func UseArena(a *Arena) DecodeOpt {
+	return func(d *Decoder) {
+		d.arena = a
+	}
+}
+
+// Reset releases everything handed out by the arena so it can be reused.
+func (a *Arena) Reset() {
+	for _, m := range a.maps[:a.nmaps] {
+		for k := range m {
+			delete(m, k)
+		}
+	}
+	vals := a.vals[:a.nvals]
+	for i := range vals {
+		vals[i] = nil
+	}
+	a.nmaps, a.nvals = 0, 0
+	a.buf.Reset()
+}
+
+// newMap provides an empty map, allocating one when the arena is nil.
+func (a *Arena) newMap(size int) map[string]interface{} {
+	if a == nil {
+		return make(map[string]interface{}, size)
+	}
+	if a.nmaps == len(a.maps) {
+		a.maps = append(a.maps, make(map[string]interface{}, size))
+	}
+	m := a.maps[a.nmaps]
+	a.nmaps++
+	return m
+}
+
+// newSlice provides a slice of n nil values, allocating one when the arena
+// is nil. Appending to the slice never overwrites other slices.
+func (a *Arena) newSlice(n int) []interface{} {
+	if a == nil {
+		return make([]interface{}, n)
+	}
+	if len(a.vals)-a.nvals < n {
+		// Earlier blocks are left to the slices cut from them, and only
+		// the latest is kept for reuse.
+		size := 2 * len(a.vals)
+		if size < minArenaSlice {
+			size = minArenaSlice
+		}
+		if size < n {
+			size = n
+		}
+		a.vals, a.nvals = make([]interface{}, size), 0
+	}
+	s := a.vals[a.nvals : a.nvals+n : a.nvals+n]
+	a.nvals += n
+	return s
+}
+
+// object builds the generic value for the mapping node.
+func (a *Arena) object(n *yaml.Node) map[string]interface{} {
+	m := a.newMap(len(n.Content) / 2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		m[n.Content[i].Value] = a.value(n.Content[i+1])
+	}
+	return m
+}
+
+// array builds the generic value for the sequence node.
+func (a *Arena) array(n *yaml.Node) []interface{} {
+	s := a.newSlice(len(n.Content))
+	for i, e := range n.Content {
+		s[i] = a.value(e)
+	}
+	return s
+}
+
+// value builds the generic value for the node, as encoding/json would
+// decode it into an empty interface.
+func (a *Arena) value(n *yaml.Node) interface{} {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return a.object(n)
+	case yaml.SequenceNode:
+		return a.array(n)
+	}
+	switch l := literalOf(n); l.kind {
+	case nullLiteral:
+		return nil
+	case boolLiteral:
+		return l.b
+	case stringLiteral:
+		return l.s
+	default:
+		return l.float()
+	}
+}
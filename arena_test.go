@@ -0,0 +1,87 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArena(t *testing.T) {
+	y := []byte(`kind: Deployment
+metadata: {name: web, labels: {app: web, tier: frontend}}
+spec:
+  replicas: 3
+  ports: [80, 443]
+  containers:
+    - {name: nginx, args: [-g, daemon off]}
+`)
+	type manifest struct {
+		Kind     string                 `json:"kind"`
+		Metadata map[string]interface{} `json:"metadata"`
+		Spec     interface{}            `json:"spec"`
+	}
+	targets := []func() interface{}{
+		func() interface{} { var v interface{}; return &v },
+		func() interface{} { var m map[string]interface{}; return &m },
+		func() interface{} { return &manifest{} },
+	}
+	a := NewArena()
+	for _, target := range targets {
+		want := target()
+		if err := Unmarshal(y, want); err != nil {
+			t.Fatalf("Unmarshal() = %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			a.Reset()
+			got := target()
+			if err := UnmarshalWithOptions(y, got, UseArena(a)); err != nil {
+				t.Fatalf("UnmarshalWithOptions(%T) = %v", got, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("UnmarshalWithOptions(%T) = %v; want %v", got, reflect.ValueOf(got).Elem(), reflect.ValueOf(want).Elem())
+			}
+		}
+	}
+}
+
+func TestArenaReuse(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	a := NewArena()
+	var first map[string]interface{}
+	if err := UnmarshalWithOptions([]byte("a: {b: 1}\nc: [1, 2]\n"), &first, UseArena(a)); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	inner := reflect.ValueOf(first["a"]).Pointer()
+
+	// Without a reset, values are not shared between decodes.
+	var second map[string]interface{}
+	if err := UnmarshalWithOptions([]byte("a: {b: 2}\n"), &second, UseArena(a)); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if first["a"].(map[string]interface{})["b"] != 1.0 {
+		t.Errorf("UnmarshalWithOptions() overwrote an earlier value: %v", first)
+	}
+
+	a.Reset()
+	if len(first) != 0 {
+		t.Errorf("Reset() left %v; want the maps cleared", first)
+	}
+	var third map[string]interface{}
+	if err := UnmarshalWithOptions([]byte("x: {y: z}\n"), &third, UseArena(a)); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if reflect.ValueOf(third).Pointer() != reflect.ValueOf(first).Pointer() ||
+		reflect.ValueOf(third["x"]).Pointer() != inner {
+		t.Errorf("UnmarshalWithOptions() did not reuse the maps after Reset()")
+	}
+
+	// Appending to a slice from the arena never overwrites its neighbors.
+	s := a.newSlice(1)
+	next := a.newSlice(1)
+	next[0] = "next"
+	_ = append(s, "appended")
+	if next[0] != "next" {
+		t.Errorf("append() overwrote the next slice: %v", next)
+	}
+}
@@ -0,0 +1,43 @@
+package yaml
+
+import "gopkg.in/yaml.v3"
+
+// QuoteBoolKeys quotes every mapping key that a YAML 1.1 parser would read
+// as a boolean, such as on, yes or y, so that files like GitHub Actions
+// workflows are read the same way everywhere. Keys taken from Go values are
+// normally quoted already, but this also covers those added by options such
+// as KeepEmpty.
+func QuoteBoolKeys() EncodeOpt {
+	return func(e *encoder) {
+		e.quoteBoolKeys = true
+	}
+}
+
+// bool11 holds the booleans of YAML 1.1.
+var bool11 = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"true": true, "True": true, "TRUE": true,
+	"false": true, "False": true, "FALSE": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+}
+
+// quoteBoolKeys quotes the plain keys in the node tree that look like
+// booleans.
+func quoteBoolKeys(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			if k.Kind == yaml.ScalarNode && k.Style == 0 && k.ShortTag() == "!!str" && bool11[k.Value] {
+				k.Style = yaml.DoubleQuotedStyle
+			}
+			quoteBoolKeys(n.Content[i+1])
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			quoteBoolKeys(c)
+		}
+	}
+}
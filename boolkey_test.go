@@ -0,0 +1,37 @@
+package yaml
+
+import "testing"
+
+func TestQuoteBoolKeys(t *testing.T) {
+	type workflow struct {
+		Name string              `json:"name"`
+		On   map[string][]string `json:"on,omitempty"`
+		Y    []int               `json:"y,omitempty"`
+	}
+	tests := []struct {
+		v    interface{}
+		opts []EncodeOpt
+		want string
+	}{
+		{map[string]interface{}{"on": map[string]int{"yes": 1, "y": 2}, "other": "on"}, nil, "\"on\":\n    \"y\": 2\n    \"yes\": 1\nother: \"on\"\n"},
+		{workflow{Name: "ci", On: map[string][]string{}, Y: []int{}}, []EncodeOpt{KeepEmpty()}, "name: ci\n\"on\": {}\n\"y\": []\n"},
+		{[]map[string]bool{{"Off": true, "offset": false}}, nil, "- \"Off\": true\n  offset: false\n"},
+	}
+	for _, test := range tests {
+		y, err := MarshalWithOptions(test.v, append(test.opts, QuoteBoolKeys())...)
+		if err != nil {
+			t.Fatalf("MarshalWithOptions() = %v", err)
+		}
+		if string(y) != test.want {
+			t.Errorf("MarshalWithOptions(%+v) = %q; want %q", test.v, y, test.want)
+		}
+	}
+
+	y, err := MarshalWithOptions(workflow{Name: "ci", On: map[string][]string{}, Y: []int{}}, KeepEmpty())
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	if want := "name: ci\non: {}\ny: []\n"; string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+}
@@ -0,0 +1,87 @@
+package yaml
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BudgetError is returned when decoding a document would need more memory
+// than allowed by MemoryBudget. Line and Column give the node at which the
+// budget ran out, and are zero when it ran out while reading the input.
+type BudgetError struct {
+	Budget int64
+	Line   int
+	Column int
+}
+
+func (e *BudgetError) Error() string {
+	return positionPrefix(e.Line, e.Column) + fmt.Sprintf("document exceeds the memory budget of %d bytes", e.Budget)
+}
+
+// MemoryBudget configures the decoder to reject documents that would need
+// more than the given number of bytes to decode with a *BudgetError, so one
+// oversized document can't exhaust the memory of a shared service. The
+// memory is estimated from the input read for the document and from the
+// node tree, counting a fixed cost per node plus the length of its value.
+// Aliases are counted again wherever they are used, as the conversion
+// expands them.
+func MemoryBudget(bytes int64) DecodeOpt {
+	return func(d *Decoder) {
+		d.budget = bytes
+	}
+}
+
+// nodeCost is the estimated memory used by each node besides its value.
+var nodeCost = int64(reflect.TypeOf(yaml.Node{}).Size())
+
+// budgetReader fails once more than the budget has been read since the
+// count was last reset.
+type budgetReader struct {
+	r      io.Reader
+	budget int64
+	n      int64
+	err    error
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	if b.n > b.budget {
+		b.err = &BudgetError{Budget: b.budget}
+		return 0, b.err
+	}
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// checkBudget returns a *BudgetError if the estimated memory needed by
+// the tree exceeds the budget.
+func checkBudget(n *yaml.Node, budget int64) error {
+	used := int64(0)
+	var visit func(n *yaml.Node) *yaml.Node
+	visit = func(n *yaml.Node) *yaml.Node {
+		used += nodeCost + int64(len(n.Value))
+		if used > budget {
+			return n
+		}
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			// Report the alias rather than the node it refers to.
+			if visit(n.Alias) != nil {
+				return n
+			}
+			return nil
+		}
+		for _, c := range n.Content {
+			if at := visit(c); at != nil {
+				return at
+			}
+		}
+		return nil
+	}
+	if at := visit(n); at != nil {
+		return &BudgetError{Budget: budget, Line: at.Line, Column: at.Column}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	laughs := `a: &a ["lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol", "lol"]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+`
+	tests := []struct {
+		name   string
+		y      string
+		budget int64
+		line   int // of the error, or -1 for none
+	}{
+		{"small", "a: 1\nb: [1, 2]\n", 100 * nodeCost, -1},
+		{"tree", "a: 1\nb: [1, 2, 3, 4]\n", 6 * nodeCost, 2},
+		{"aliases", laughs, 1 << 20, 4},
+		{"input", "a: " + strings.Repeat("x", 64<<10) + "\n", 4096, 0},
+	}
+	for _, test := range tests {
+		var v interface{}
+		err := UnmarshalWithOptions([]byte(test.y), &v, MemoryBudget(test.budget))
+		if test.line < 0 {
+			if err != nil {
+				t.Errorf("%s: UnmarshalWithOptions() = %v; want nil", test.name, err)
+			}
+			continue
+		}
+		var be *BudgetError
+		if !errors.As(err, &be) {
+			t.Errorf("%s: UnmarshalWithOptions() = %v; want *BudgetError", test.name, err)
+			continue
+		}
+		if be.Budget != test.budget || be.Line != test.line {
+			t.Errorf("%s: UnmarshalWithOptions() = %+v; want budget %d at line %d", test.name, be, test.budget, test.line)
+		}
+	}
+}
+
+func TestMemoryBudgetPerDocument(t *testing.T) {
+	doc := "a: [" + strings.Repeat("1, ", 20) + "1]\n"
+	y := strings.Repeat("---\n"+doc, 200)
+	d := NewDecoder(bytes.NewReader([]byte(y)), MemoryBudget(40*nodeCost))
+	n := 0
+	for {
+		var v interface{}
+		err := d.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() = %v after %d documents", err, n)
+		}
+		n++
+	}
+	if n != 200 {
+		t.Errorf("Decode() read %d documents; want 200", n)
+	}
+}
@@ -0,0 +1,221 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Builder constructs a YAML document one node at a time, for example:
+//
+//	y, err := yaml.NewDoc().Map().
+//		Key("kind").Value("Deployment").
+//		Key("spec").Map().Comment("Desired state").
+//		Key("replicas").Value(3).
+//		Key("ports").Seq().Style(yaml.FlowStyle).Value(80).Value(443).
+//		Bytes()
+//
+// Map and Seq start a collection, which End closes; collections still open
+// are closed by Node and Bytes. Keys are added with Key, and every other
+// value with Value, Map, Seq or Alias. Anchor, Tag, Style, Comment and
+// LineComment change the node added last, which may be a key.
+//
+// Errors, such as a key outside of a mapping, are reported by Node and
+// Bytes, and any calls in between are ignored.
+type Builder struct {
+	doc   *yaml.Node
+	stack []*yaml.Node // open collections
+	last  *yaml.Node
+	err   error
+}
+
+// NewDoc returns a Builder for a new document.
+func NewDoc() *Builder {
+	return &Builder{doc: &yaml.Node{Kind: yaml.DocumentNode}}
+}
+
+var (
+	errBuildKey       = errors.New("yaml: key added outside of a mapping")
+	errBuildValue     = errors.New("yaml: mapping value added without a key")
+	errBuildKeyNeeded = errors.New("yaml: mapping key must be added with Key")
+	errBuildRoot      = errors.New("yaml: document already has a root node")
+	errBuildEnd       = errors.New("yaml: no collection to end")
+	errBuildEmpty     = errors.New("yaml: document has no root node")
+	errBuildNoNode    = errors.New("yaml: no node to change")
+)
+
+// Map starts a mapping.
+func (b *Builder) Map() *Builder {
+	return b.open(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+}
+
+// Seq starts a sequence.
+func (b *Builder) Seq() *Builder {
+	return b.open(&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"})
+}
+
+// End closes the collection started last.
+func (b *Builder) End() *Builder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.stack) == 0 {
+		b.err = errBuildEnd
+		return b
+	}
+	if top := b.stack[len(b.stack)-1]; top.Kind == yaml.MappingNode && len(top.Content)%2 == 1 {
+		b.err = errBuildValue
+		return b
+	}
+	b.stack = b.stack[:len(b.stack)-1]
+	return b
+}
+
+// Key adds a key to the open mapping.
+func (b *Builder) Key(k string) *Builder {
+	if b.err != nil {
+		return b
+	}
+	top := b.top()
+	if top == nil || top.Kind != yaml.MappingNode {
+		b.err = errBuildKey
+		return b
+	}
+	if len(top.Content)%2 == 1 {
+		b.err = errBuildValue
+		return b
+	}
+	b.last = newScalarNode(k)
+	top.Content = append(top.Content, b.last)
+	return b
+}
+
+// Value adds a value converted using the same rules as Marshal, so that
+// structs and maps become collections and strings are quoted as needed.
+func (b *Builder) Value(v interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+	n, err := StructToNode(v)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	return b.add(n)
+}
+
+// Alias adds an alias to the anchor.
+func (b *Builder) Alias(anchor string) *Builder {
+	return b.add(&yaml.Node{Kind: yaml.AliasNode, Value: anchor})
+}
+
+// Anchor defines an anchor on the node added last.
+func (b *Builder) Anchor(name string) *Builder {
+	return b.change(func(n *yaml.Node) { n.Anchor = name })
+}
+
+// Tag sets the tag of the node added last, such as "!!str" or "!custom".
+func (b *Builder) Tag(tag string) *Builder {
+	return b.change(func(n *yaml.Node) {
+		n.Tag = tag
+		n.Style |= yaml.TaggedStyle
+	})
+}
+
+// Style sets the style of the node added last, for example
+// yaml.DoubleQuotedStyle for a scalar or yaml.FlowStyle for a collection.
+func (b *Builder) Style(s yaml.Style) *Builder {
+	return b.change(func(n *yaml.Node) { n.Style = s | n.Style&yaml.TaggedStyle })
+}
+
+// Comment sets the comment written on the lines before the node added
+// last. For a mapping entry, set it on the key.
+func (b *Builder) Comment(c string) *Builder {
+	return b.change(func(n *yaml.Node) { n.HeadComment = c })
+}
+
+// LineComment sets the comment written at the end of the line of the node
+// added last.
+func (b *Builder) LineComment(c string) *Builder {
+	return b.change(func(n *yaml.Node) { n.LineComment = c })
+}
+
+// Node closes any open collections and returns the document node.
+func (b *Builder) Node() (*yaml.Node, error) {
+	for b.err == nil && len(b.stack) > 0 {
+		b.End()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.doc.Content) == 0 {
+		return nil, errBuildEmpty
+	}
+	return b.doc, nil
+}
+
+// Bytes closes any open collections and returns the document written as
+// Marshal would write it.
+func (b *Builder) Bytes() ([]byte, error) {
+	n, err := b.Node()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := encodeYAML(&buf, n); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Builder) top() *yaml.Node {
+	if len(b.stack) == 0 {
+		return nil
+	}
+	return b.stack[len(b.stack)-1]
+}
+
+// add adds a value in the current position.
+func (b *Builder) add(n *yaml.Node) *Builder {
+	if b.err != nil {
+		return b
+	}
+	top := b.top()
+	switch {
+	case top == nil:
+		if len(b.doc.Content) > 0 {
+			b.err = errBuildRoot
+			return b
+		}
+		b.doc.Content = append(b.doc.Content, n)
+	case top.Kind == yaml.MappingNode && len(top.Content)%2 == 0:
+		b.err = errBuildKeyNeeded
+		return b
+	default:
+		top.Content = append(top.Content, n)
+	}
+	b.last = n
+	return b
+}
+
+// open adds a collection and makes it the current position.
+func (b *Builder) open(n *yaml.Node) *Builder {
+	if b.add(n); b.err == nil {
+		b.stack = append(b.stack, n)
+	}
+	return b
+}
+
+// change updates the node added last.
+func (b *Builder) change(fn func(n *yaml.Node)) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if b.last == nil {
+		b.err = errBuildNoNode
+		return b
+	}
+	fn(b.last)
+	return b
+}
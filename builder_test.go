@@ -0,0 +1,75 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuilder(t *testing.T) {
+	y, err := NewDoc().Map().
+		Key("kind").Value("Deployment").
+		Key("spec").Comment("Desired state").Map().
+		Key("replicas").Value(3).LineComment("scaled by hand").
+		Key("ports").Seq().Style(yaml.FlowStyle).Value(80).Value(443).End().
+		Key("labels").Value(map[string]string{"app": "web"}).Anchor("labels").
+		Key("selector").Alias("labels").
+		Key("version").Value("1.10").
+		Key("raw").Value("x").Tag("!custom").
+		Key("script").Value("a\nb\n").Style(yaml.LiteralStyle).
+		Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() = %v", err)
+	}
+	want := `kind: Deployment
+# Desired state
+spec:
+    replicas: 3 # scaled by hand
+    ports: [80, 443]
+    labels: &labels
+        app: web
+    selector: *labels
+    version: "1.10"
+    raw: !custom x
+    script: |
+        a
+        b
+`
+	if string(y) != want {
+		t.Errorf("Bytes() = %s; want %s", y, want)
+	}
+
+	n, err := NewDoc().Seq().Value(1).Map().Key("a").Value(nil).Node()
+	if err != nil {
+		t.Fatalf("Node() = %v", err)
+	}
+	if n.Kind != yaml.DocumentNode || len(n.Content[0].Content) != 2 {
+		t.Errorf("Node() = %+v", n)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	tests := []struct {
+		b    *Builder
+		want error
+	}{
+		{NewDoc().Key("a"), errBuildKey},
+		{NewDoc().Map().Value(1), errBuildKeyNeeded},
+		{NewDoc().Map().Key("a").Key("b"), errBuildValue},
+		{NewDoc().Map().Key("a").End(), errBuildValue},
+		{NewDoc().Value(1).Value(2), errBuildRoot},
+		{NewDoc().Value(1).End(), errBuildEnd},
+		{NewDoc(), errBuildEmpty},
+		{NewDoc().Anchor("a"), errBuildNoNode},
+		{NewDoc().Key("a").Map().Key("b").Value(1), errBuildKey},
+	}
+	for i, test := range tests {
+		if _, err := test.b.Bytes(); !errors.Is(err, test.want) {
+			t.Errorf("%d: Bytes() = %v; want %v", i, err, test.want)
+		}
+	}
+	if _, err := NewDoc().Value(make(chan int)).Node(); err == nil {
+		t.Errorf("Node() = nil; want marshal error")
+	}
+}
@@ -0,0 +1,280 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GenerateStructs infers Go type definitions, with json tags compatible
+// with this package, from one or more sample YAML streams. The shapes of
+// every document are merged so that fields missing from some samples are
+// marked omitempty, nullable values become pointers, mixed integer and
+// float values become float64, and conflicting types fall back to
+// interface{}. Nested mappings are defined as separate types named after
+// their parent and key. The formatted source of the declarations is
+// returned without a package clause.
+func GenerateStructs(name string, samples ...[]byte) ([]byte, error) {
+	root := new(shape)
+	for i, y := range samples {
+		dec := yaml.NewDecoder(bytes.NewReader(y))
+		for {
+			var v interface{}
+			if err := dec.Decode(&v); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("error parsing sample %d: %v", i, err)
+			}
+			v, err := convertToJSONableObject(v, nil)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing sample %d: %v", i, err)
+			}
+			root.add(v)
+		}
+	}
+
+	g := &structGenerator{names: make(map[string]bool)}
+	g.declare(exportedName(name), root)
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+type shapeKind int
+
+const (
+	shapeUnknown shapeKind = iota
+	shapeBool
+	shapeInt
+	shapeFloat
+	shapeString
+	shapeObject
+	shapeArray
+	shapeMixed
+)
+
+// shape holds the merged structure of all the values seen at a position.
+type shape struct {
+	kind     shapeKind
+	nullable bool
+	count    int // number of objects seen, to detect optional fields
+	keys     []string
+	fields   map[string]*fieldShape
+	elem     *shape
+}
+
+type fieldShape struct {
+	shape *shape
+	count int
+}
+
+func (s *shape) add(v interface{}) {
+	var k shapeKind
+	switch t := v.(type) {
+	case nil:
+		s.nullable = true
+		return
+	case bool:
+		k = shapeBool
+	case int, int64, uint64:
+		k = shapeInt
+	case float64:
+		k = shapeFloat
+	case string:
+		k = shapeString
+	case map[string]interface{}:
+		k = shapeObject
+		s.addObject(t)
+	case []interface{}:
+		k = shapeArray
+		if s.elem == nil {
+			s.elem = new(shape)
+		}
+		for _, e := range t {
+			s.elem.add(e)
+		}
+	}
+	switch {
+	case s.kind == shapeUnknown || s.kind == k:
+		s.kind = k
+	case (s.kind == shapeInt && k == shapeFloat) || (s.kind == shapeFloat && k == shapeInt):
+		s.kind = shapeFloat
+	default:
+		s.kind = shapeMixed
+	}
+}
+
+func (s *shape) addObject(m map[string]interface{}) {
+	if s.fields == nil {
+		s.fields = make(map[string]*fieldShape)
+	}
+	s.count++
+	// Maintain a stable order for new keys, as maps are unordered.
+	var added []string
+	for k := range m {
+		if _, ok := s.fields[k]; !ok {
+			added = append(added, k)
+			s.fields[k] = &fieldShape{shape: new(shape)}
+		}
+	}
+	sort.Strings(added)
+	s.keys = append(s.keys, added...)
+	for k, v := range m {
+		f := s.fields[k]
+		f.count++
+		f.shape.add(v)
+	}
+}
+
+type structGenerator struct {
+	buf   bytes.Buffer
+	names map[string]bool
+	queue []pendingType
+}
+
+type pendingType struct {
+	name  string
+	shape *shape
+}
+
+func (g *structGenerator) declare(name string, s *shape) {
+	g.names[name] = true
+	g.queue = append(g.queue, pendingType{name, s})
+	for len(g.queue) > 0 {
+		p := g.queue[0]
+		g.queue = g.queue[1:]
+		if g.buf.Len() > 0 {
+			g.buf.WriteString("\n")
+		}
+		if p.shape.kind == shapeObject {
+			g.writeStruct(p.name, p.shape)
+		} else {
+			fmt.Fprintf(&g.buf, "type %s %s\n", p.name, g.typeOf(p.name, p.shape, false))
+		}
+	}
+}
+
+func (g *structGenerator) writeStruct(name string, s *shape) {
+	fmt.Fprintf(&g.buf, "type %s struct {\n", name)
+	used := make(map[string]bool)
+	for _, k := range s.keys {
+		f := s.fields[k]
+		fn := exportedName(k)
+		for i := 2; used[fn]; i++ {
+			fn = exportedName(k) + strconv.Itoa(i)
+		}
+		used[fn] = true
+		optional := f.count < s.count || f.shape.nullable
+		tag := k
+		if optional {
+			tag += ",omitempty"
+		}
+		typ := g.typeOf(name+fn, f.shape, true)
+		fmt.Fprintf(&g.buf, "\t%s %s `json:%s`\n", fn, typ, strconv.Quote(tag))
+	}
+	g.buf.WriteString("}\n")
+}
+
+// typeOf returns the Go type for the shape, queuing nested struct types.
+func (g *structGenerator) typeOf(name string, s *shape, field bool) string {
+	var t string
+	switch s.kind {
+	case shapeBool:
+		t = "bool"
+	case shapeInt:
+		t = "int"
+	case shapeFloat:
+		t = "float64"
+	case shapeString:
+		t = "string"
+	case shapeObject:
+		if !field {
+			return "struct{}"
+		}
+		for i := 2; g.names[name]; i++ {
+			name = strings.TrimRight(name, "0123456789") + strconv.Itoa(i)
+		}
+		g.names[name] = true
+		g.queue = append(g.queue, pendingType{name, s})
+		return "*" + name
+	case shapeArray:
+		if s.elem == nil || s.elem.kind == shapeUnknown {
+			return "[]interface{}"
+		}
+		return "[]" + strings.TrimPrefix(g.typeOf(singular(name), s.elem, true), "*")
+	default:
+		return "interface{}"
+	}
+	if s.nullable && field {
+		return "*" + t
+	}
+	return t
+}
+
+// commonInitialisms are kept upper case in generated names.
+var commonInitialisms = map[string]bool{
+	"API": true, "DNS": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "TLS": true, "UI": true, "URI": true, "URL": true, "UUID": true, "YAML": true,
+}
+
+// exportedName converts a key into an exported Go identifier.
+func exportedName(k string) string {
+	words := strings.FieldsFunc(k, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		// Split camelCase words so initialisms can be detected.
+		for _, p := range splitCamel(w) {
+			if u := strings.ToUpper(p); commonInitialisms[u] {
+				b.WriteString(u)
+				continue
+			}
+			r := []rune(p)
+			r[0] = unicode.ToUpper(r[0])
+			b.WriteString(string(r))
+		}
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		s = "Field" + s
+	}
+	return s
+}
+
+func splitCamel(s string) []string {
+	var out []string
+	r := []rune(s)
+	start := 0
+	for i := 1; i < len(r); i++ {
+		if unicode.IsUpper(r[i]) && !unicode.IsUpper(r[i-1]) {
+			out = append(out, string(r[start:i]))
+			start = i
+		}
+	}
+	return append(out, string(r[start:]))
+}
+
+// singular makes a basic attempt to name the items of a list.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ses"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name + "Item"
+}
@@ -0,0 +1,93 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestGenerateStructs(t *testing.T) {
+	a := []byte(`
+name: web
+replicas: 2
+ratio: 1
+apiURL: https://example.com
+containers:
+  - name: app
+    ports: [80]
+`)
+	b := []byte(`
+name: api
+replicas: 3
+ratio: 0.5
+labels:
+  tier: back
+containers:
+  - name: proxy
+    env:
+      - name: A
+        value: null
+---
+name: worker
+replicas: 1
+ratio: 2
+tags: []
+`)
+	want := "type Config struct {\n" +
+		"\tAPIURL     string            `json:\"apiURL,omitempty\"`\n" +
+		"\tContainers []ConfigContainer `json:\"containers,omitempty\"`\n" +
+		"\tName       string            `json:\"name\"`\n" +
+		"\tRatio      float64           `json:\"ratio\"`\n" +
+		"\tReplicas   int               `json:\"replicas\"`\n" +
+		"\tLabels     *ConfigLabels     `json:\"labels,omitempty\"`\n" +
+		"\tTags       []interface{}     `json:\"tags,omitempty\"`\n" +
+		"}\n\n" +
+		"type ConfigContainer struct {\n" +
+		"\tName  string                   `json:\"name\"`\n" +
+		"\tPorts []int                    `json:\"ports,omitempty\"`\n" +
+		"\tEnv   []ConfigContainerEnvItem `json:\"env,omitempty\"`\n" +
+		"}\n\n" +
+		"type ConfigLabels struct {\n" +
+		"\tTier string `json:\"tier\"`\n" +
+		"}\n\n" +
+		"type ConfigContainerEnvItem struct {\n" +
+		"\tName  string      `json:\"name\"`\n" +
+		"\tValue interface{} `json:\"value,omitempty\"`\n" +
+		"}\n"
+
+	src, err := GenerateStructs("config", a, b)
+	if err != nil {
+		t.Fatalf("GenerateStructs() = %v", err)
+	}
+	if string(src) != want {
+		t.Errorf("GenerateStructs() =\n%s\nwant:\n%s", src, want)
+	}
+}
+
+func TestGenerateStructsScalars(t *testing.T) {
+	cases := []struct {
+		samples []string
+		want    string
+	}{
+		{[]string{"- 1\n- 2.5\n"}, "type List []float64\n"},
+		{[]string{"- 1\n- a\n"}, "type List []interface{}\n"},
+		{[]string{"a: 1\n", "a: null\n"}, "type List struct {\n\tA *int `json:\"a,omitempty\"`\n}\n"},
+		{[]string{"my-key: x\n2fa: true\n"}, "type List struct {\n\tField2fa bool   `json:\"2fa\"`\n\tMyKey    string `json:\"my-key\"`\n}\n"},
+	}
+	for _, c := range cases {
+		var samples [][]byte
+		for _, s := range c.samples {
+			samples = append(samples, []byte(s))
+		}
+		got, err := GenerateStructs("list", samples...)
+		if err != nil {
+			t.Errorf("GenerateStructs(%q) = %v", c.samples, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("GenerateStructs(%q) = %q; want %q", c.samples, got, c.want)
+		}
+	}
+
+	if _, err := GenerateStructs("bad", []byte("a: [")); err == nil {
+		t.Errorf("GenerateStructs(invalid) = nil; want error")
+	}
+}
@@ -0,0 +1,310 @@
+package yaml
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommentedValue wraps a value so that Marshal will emit it alongside the
+// provided comments. The wrapper is transparent when converted to JSON, so it
+// can be used anywhere in a structure, including as a struct field, map value
+// or slice item. Comments may optionally include the leading "#".
+type CommentedValue struct {
+	Value interface{}
+	Head  string // placed on the lines before the value's key
+	Line  string // placed at the end of the value's line
+	Foot  string // placed on the lines after the value
+}
+
+// MarshalJSON outputs the wrapped value only.
+func (c CommentedValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Value)
+}
+
+// UnmarshalJSON decodes into the wrapped value, which should be a pointer if
+// a specific type is expected.
+func (c *CommentedValue) UnmarshalJSON(data []byte) error {
+	if c.Value != nil {
+		return json.Unmarshal(data, c.Value)
+	}
+	return json.Unmarshal(data, &c.Value)
+}
+
+var (
+	commentedValueType = reflect.TypeOf(CommentedValue{})
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType  = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// pathComments stores the comments found at a given path inside a value.
+type pathComments struct {
+	path     []string
+	comments CommentedValue
+}
+
+// collectComments returns the comments of every CommentedValue found in
+// the value along with the path to its position in the resulting document.
+func collectComments(v reflect.Value) []pathComments {
+	var out []pathComments
+	walkValue(v, func(path []string, v reflect.Value, _ *field) bool {
+		if v.Type() != commentedValueType {
+			return true
+		}
+		c := v.Interface().(CommentedValue)
+		if c.Head != "" || c.Line != "" || c.Foot != "" {
+			out = append(out, pathComments{
+				path:     append([]string(nil), path...),
+				comments: c,
+			})
+		}
+		return true
+	})
+	return out
+}
+
+// walkValue traverses the value following the same rules as encoding/json,
+// calling fn for each value found along with its path in the resulting
+// document and, for struct fields, the field's definition. The walk will
+// not descend into a value if fn returns false. CommentedValue wrappers
+// are transparent, while other JSON and text marshalers are not traversed
+// as it is impossible to know where nested values will end up.
+func walkValue(v reflect.Value, fn func(path []string, v reflect.Value, f *field) bool) {
+	walkValueAt(v, nil, nil, fn)
+}
+
+func walkValueAt(v reflect.Value, path []string, f *field, fn func([]string, reflect.Value, *field) bool) { //nolint:gocyclo
+	if !v.IsValid() {
+		return
+	}
+	if !fn(path, v, f) {
+		return
+	}
+	if v.Type() == commentedValueType {
+		walkValueAt(reflect.ValueOf(v.Interface().(CommentedValue).Value), path, nil, fn)
+		return
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == commentedValueType {
+		if !v.IsNil() {
+			walkValueAt(v.Elem(), path, f, fn)
+		}
+		return
+	}
+	if v.Type().Implements(jsonMarshalerType) || implementsMarshalerTo(v.Type()) || v.Type().Implements(textMarshalerType) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			walkValueAt(v.Elem(), path, f, fn)
+		}
+	case reflect.Struct:
+		fields := cachedTypeFields(v.Type())
+		for i := range fields {
+			fv, ok := fieldByIndex(v, fields[i].index)
+			if !ok {
+				continue
+			}
+			walkValueAt(fv, append(path, fields[i].name), &fields[i], fn)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			k, ok := mapKeyString(iter.Key())
+			if !ok {
+				continue
+			}
+			walkValueAt(iter.Value(), append(path, k), nil, fn)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return // encoded as base64
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkValueAt(v.Index(i), append(path, strconv.Itoa(i)), nil, fn)
+		}
+	}
+}
+
+// fieldByIndex returns the nested field corresponding to index, or false
+// if one of the embedded structs along the way is a nil pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// mapKeyString converts a map key into the string encoding/json would use.
+func mapKeyString(k reflect.Value) (string, bool) {
+	if k.Kind() == reflect.String {
+		return k.String(), true
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err == nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
+
+// applyComments copies the comments onto the matching nodes of the tree.
+func applyComments(n *yaml.Node, comments []pathComments) {
+	for _, pc := range comments {
+		target, key := n, (*yaml.Node)(nil)
+		for _, p := range pc.path {
+			target, key = childNode(target, p)
+			if target == nil {
+				break
+			}
+		}
+		if target == nil {
+			continue
+		}
+		setNodeComments(target, key, pc.comments)
+	}
+}
+
+// childNode finds the child of a mapping or sequence node identified by
+// the path segment, returning the value node and, for mappings, its key.
+func childNode(n *yaml.Node, p string) (*yaml.Node, *yaml.Node) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return childNode(n.Content[0], p)
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == p {
+				return n.Content[i+1], n.Content[i]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, ok := arrayIndex(p); ok && i < len(n.Content) {
+			return n.Content[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// setNodeComments places comments where go-yaml expects to find them: on
+// the key for mapping entries, or on the value itself otherwise.
+func setNodeComments(value, key *yaml.Node, c CommentedValue) {
+	if key == nil {
+		value.HeadComment = c.Head
+		value.LineComment = c.Line
+		value.FootComment = c.Foot
+		return
+	}
+	key.HeadComment = c.Head
+	key.FootComment = c.Foot
+	if value.Kind == yaml.ScalarNode || value.Kind == yaml.AliasNode {
+		value.LineComment = c.Line
+	} else {
+		key.LineComment = c.Line
+	}
+}
+
+// Comment holds the comments found around a value in a document, without
+// the leading "#" and the space following it on each line.
+type Comment struct {
+	Head string // on the lines before the value, or its key
+	Line string // at the end of the value's line
+	Foot string // on the lines after the value
+}
+
+// CommentsOf reads the comments of the first document in the YAML stream,
+// for example to generate documentation from the descriptions given in a
+// configuration file. The comments are keyed by the path to the value
+// they belong to, in the form used by Query such as "spec.ports[0]", with
+// those placed before or after the whole document at the empty path. The
+// comments of mapping entries are collected from both the key and the
+// value. Values without comments are left out.
+func CommentsOf(data []byte) (map[string]Comment, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(data, &n); err != nil {
+		return nil, err
+	}
+	out := make(map[string]Comment)
+	collectNodeComments(out, &n, nil, nil)
+	return out, nil
+}
+
+// collectNodeComments adds the comments of the value, and its key if in
+// a mapping, and of the values it holds.
+func collectNodeComments(out map[string]Comment, n, key *yaml.Node, path []interface{}) {
+	var c Comment
+	for _, m := range []*yaml.Node{key, n} {
+		if m != nil {
+			c.Head = joinComments(c.Head, commentText(m.HeadComment))
+			c.Line = joinComments(c.Line, commentText(m.LineComment))
+			c.Foot = joinComments(c.Foot, commentText(m.FootComment))
+		}
+	}
+	if c != (Comment{}) {
+		// The document and its root node share the empty path.
+		p := formatPath(path)
+		prev := out[p]
+		out[p] = Comment{
+			Head: joinComments(prev.Head, c.Head),
+			Line: joinComments(prev.Line, c.Line),
+			Foot: joinComments(prev.Foot, c.Foot),
+		}
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			collectNodeComments(out, c, nil, path)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i]
+			collectNodeComments(out, n.Content[i+1], k, append(path[:len(path):len(path)], k.Value))
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			collectNodeComments(out, c, nil, append(path[:len(path):len(path)], i))
+		}
+	}
+}
+
+// commentText removes the comment markers from each line of the comment.
+func commentText(comment string) string {
+	if comment == "" {
+		return ""
+	}
+	lines := strings.Split(comment, "\n")
+	for i, l := range lines {
+		l = strings.TrimPrefix(strings.TrimSpace(l), "#")
+		lines[i] = strings.TrimPrefix(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinComments adds the text of a comment on a new line.
+func joinComments(text, comment string) string {
+	switch {
+	case comment == "":
+		return text
+	case text == "":
+		return comment
+	}
+	return text + "\n" + comment
+}
@@ -0,0 +1,97 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarshalCommentedValue(t *testing.T) {
+	type Spec struct {
+		Replicas interface{}       `json:"replicas"`
+		Labels   map[string]string `json:"labels"`
+		Ports    []interface{}     `json:"ports"`
+	}
+	type Config struct {
+		Name CommentedValue `json:"name"`
+		Spec CommentedValue `json:"spec"`
+	}
+	c := Config{
+		Name: CommentedValue{Value: "app", Head: "Application name", Line: "required"},
+		Spec: CommentedValue{
+			Value: Spec{
+				Replicas: CommentedValue{Value: 3, Line: "# scaled by HPA"},
+				Labels:   map[string]string{"tier": "web"},
+				Ports:    []interface{}{80, CommentedValue{Value: 443, Head: "TLS"}},
+			},
+			Line: "deployment spec",
+		},
+	}
+	want := `# Application name
+name: app # required
+spec: # deployment spec
+    labels:
+        tier: web
+    ports:
+        - 80
+        # TLS
+        - 443
+    replicas: 3 # scaled by HPA
+`
+	y, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+
+	// Comments are dropped when unmarshaling.
+	var out struct {
+		Name CommentedValue `json:"name"`
+	}
+	if err := Unmarshal(y, &out); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if out.Name.Value != "app" {
+		t.Errorf("Unmarshal() = %+v; want app", out.Name)
+	}
+}
+
+func TestCommentsOf(t *testing.T) {
+	src := `# Service configuration.
+#
+#   Indented text is kept.
+
+# The name of the service.
+name: web # short
+spec:
+  # Ports to listen on.
+  ports:
+    - 80 # http
+    # Only with TLS.
+    - 443
+  tls: {} #no space
+---
+# second document
+other: 1
+`
+	got, err := CommentsOf([]byte(src))
+	if err != nil {
+		t.Fatalf("CommentsOf() = %v", err)
+	}
+	want := map[string]Comment{
+		"":              {Head: "Service configuration.\n\n  Indented text is kept."},
+		"name":          {Head: "The name of the service.", Line: "short"},
+		"spec.ports":    {Head: "Ports to listen on."},
+		"spec.ports[0]": {Line: "http"},
+		"spec.ports[1]": {Head: "Only with TLS."},
+		"spec.tls":      {Line: "no space"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommentsOf() = %#v; want %#v", got, want)
+	}
+
+	if _, err := CommentsOf([]byte("a: [1")); err == nil {
+		t.Errorf("CommentsOf() = nil error; want error")
+	}
+}
@@ -0,0 +1,198 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source provides one layer of configuration for Load.
+type Source struct {
+	name string
+	load func(t reflect.Type) (*yaml.Node, error)
+}
+
+// FromDefaults uses the object, usually a struct holding the default
+// values, as a source of configuration.
+func FromDefaults(o interface{}) Source {
+	return Source{name: "defaults", load: func(reflect.Type) (*yaml.Node, error) {
+		n, err := StructToNode(o)
+		if err != nil {
+			return nil, err
+		}
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{n}}, nil
+	}}
+}
+
+// FromBytes uses the YAML document as a source of configuration. The name
+// is used in errors.
+func FromBytes(name string, y []byte) Source {
+	return Source{name: name, load: func(reflect.Type) (*yaml.Node, error) {
+		return parseDocument(y)
+	}}
+}
+
+// FromFile reads the configuration from the file at the path, which must
+// exist.
+func FromFile(path string) Source {
+	return Source{name: path, load: func(reflect.Type) (*yaml.Node, error) {
+		y, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return parseDocument(y)
+	}}
+}
+
+// FromOptionalFile behaves like FromFile but skips the file when it does
+// not exist.
+func FromOptionalFile(path string) Source {
+	return Source{name: path, load: func(reflect.Type) (*yaml.Node, error) {
+		y, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parseDocument(y)
+	}}
+}
+
+// FromEnv overrides fields of the struct being loaded with environment
+// variables. Each variable is named after the prefix followed by the path
+// to the field, using the JSON names in upper case joined with
+// underscores, so the field at database.host with the prefix "APP_" is set
+// by APP_DATABASE_HOST. Values are read as YAML, except for string fields
+// which take the value as is.
+func FromEnv(prefix string) Source {
+	return Source{name: "environment", load: func(t reflect.Type) (*yaml.Node, error) {
+		root := &yaml.Node{Kind: yaml.MappingNode}
+		if err := envNodes(root, t, prefix, nil, make(map[reflect.Type]bool)); err != nil {
+			return nil, err
+		}
+		if len(root.Content) == 0 {
+			return nil, nil
+		}
+		return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, nil
+	}}
+}
+
+// Load merges the sources in order, so that later sources take precedence
+// over earlier ones, and decodes the result into the object in the same
+// way as Unmarshal. The merge options control how the layers are combined.
+func Load(o interface{}, sources []Source, opts ...MergeOpt) error {
+	t := reflect.TypeOf(o)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var dst *yaml.Node
+	for _, s := range sources {
+		doc, err := s.load(t)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+		if doc == nil || len(doc.Content) == 0 {
+			continue
+		}
+		if dst == nil {
+			dst = doc
+			continue
+		}
+		if err := MergeNodes(dst, doc, opts...); err != nil {
+			return fmt.Errorf("%s: %w", s.name, err)
+		}
+	}
+	if dst == nil {
+		return nil
+	}
+	return NodeToStruct(dst, o)
+}
+
+// envNodes adds the values of the environment variables for the fields of
+// the struct type to the mapping. Types that contain themselves are only
+// expanded once.
+func envNodes(m *yaml.Node, t reflect.Type, prefix string, path []string, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	for _, f := range cachedTypeFields(t) {
+		ft := f.typ
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		p := append(path[:len(path):len(path)], f.name)
+		if ft.Kind() == reflect.Struct && !unmarshalsJSON(ft) && !reflect.PtrTo(ft).Implements(textUnmarshalerType) {
+			if err := envNodes(m, ft, prefix, p, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		v, ok := os.LookupEnv(envName(prefix, p))
+		if !ok {
+			continue
+		}
+		var n *yaml.Node
+		if ft.Kind() == reflect.String {
+			n = newScalarNode(v)
+		} else {
+			doc, err := parseDocument([]byte(v))
+			if err != nil {
+				return fmt.Errorf("%s: %w", envName(prefix, p), err)
+			}
+			if len(doc.Content) == 0 {
+				continue
+			}
+			n = doc.Content[0]
+		}
+		setMappingPath(m, p, n)
+	}
+	return nil
+}
+
+// envName provides the name of the environment variable for the path.
+func envName(prefix string, path []string) string {
+	name := strings.ToUpper(strings.Join(path, "_"))
+	return prefix + strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// setMappingPath places the node at the path of keys below the mapping,
+// adding the mappings in between as needed.
+func setMappingPath(m *yaml.Node, path []string, n *yaml.Node) {
+	for i, key := range path {
+		var v *yaml.Node
+		for j := 0; j+1 < len(m.Content); j += 2 {
+			if m.Content[j].Value == key {
+				v = m.Content[j+1]
+				break
+			}
+		}
+		if i == len(path)-1 {
+			if v != nil {
+				*v = *n
+			} else {
+				m.Content = append(m.Content, newScalarNode(key), n)
+			}
+			return
+		}
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.MappingNode}
+			m.Content = append(m.Content, newScalarNode(key), v)
+		}
+		m = v
+	}
+}
@@ -0,0 +1,80 @@
+package yaml
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type configTarget struct {
+	Name     string `json:"name"`
+	Debug    bool   `json:"debug"`
+	Database struct {
+		Host    string        `json:"host"`
+		Port    int           `json:"port"`
+		Timeout time.Duration `json:"timeout"`
+	} `json:"database"`
+	Tags  []string      `json:"tags"`
+	Token *string       `json:"api_token"`
+	Next  *configTarget `json:"next"`
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(path, []byte("name: web\ndatabase:\n  host: db\ntags: [a, b]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_DATABASE_PORT", "6543")
+	t.Setenv("APP_NAME", "123")
+	t.Setenv("APP_TAGS", "[c]")
+	t.Setenv("APP_API_TOKEN", "secret")
+
+	defaults := configTarget{Debug: true}
+	defaults.Database.Host = "localhost"
+	defaults.Database.Port = 5432
+
+	var c configTarget
+	err := Load(&c, []Source{
+		FromDefaults(defaults),
+		FromFile(path),
+		FromOptionalFile(filepath.Join(dir, "missing.yaml")),
+		FromBytes("override", []byte("database:\n  timeout: 5\n")),
+		FromEnv("APP_"),
+	})
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	want := configTarget{Name: "123", Debug: true, Tags: []string{"c"}}
+	want.Database.Host = "db"
+	want.Database.Port = 6543
+	want.Database.Timeout = 5
+	token := "secret"
+	want.Token = &token
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Load() = %+v; want %+v", c, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	var c configTarget
+	err := Load(&c, []Source{FromFile(filepath.Join(t.TempDir(), "missing.yaml"))})
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Load() = %v; want os.ErrNotExist", err)
+	}
+
+	err = Load(&c, []Source{FromBytes("base", []byte("name: a\n")), FromBytes("local", []byte("name: b\n"))}, ErrorOnConflict())
+	if !errors.Is(err, ErrMergeConflict) || !strings.HasPrefix(err.Error(), "local: ") {
+		t.Errorf("Load() = %v; want ErrMergeConflict from local", err)
+	}
+
+	t.Setenv("APP_DATABASE_PORT", "x")
+	var te *TypeError
+	if err := Load(&c, []Source{FromEnv("APP_")}); !errors.As(err, &te) {
+		t.Errorf("Load() = %v; want *TypeError", err)
+	}
+}
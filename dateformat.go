@@ -0,0 +1,97 @@
+package yaml
+
+import (
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats accepted in the format tag of string and time.Time fields, as
+// used by OpenAPI. A field tagged with `format:"date"` keeps dates such as
+// 2024-01-02 as they were written when decoded into a string, rather than
+// expanding them into a full timestamp, and time.Time values are written
+// with the date alone. Fields tagged with either format may also be
+// decoded from quoted dates and timestamps.
+const (
+	FormatDate     = "date"
+	FormatDateTime = "date-time"
+)
+
+// holdsFormats reports whether values of the type may contain a field with
+// a format tag that applies to it.
+func holdsFormats(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t == timeType || t.Implements(jsonMarshalerType) || implementsMarshalerTo(t) || t.Implements(textMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Array, reflect.Slice:
+		return holdsFormats(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range typeFields(t) {
+			if formatted(f) || holdsFormats(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatted reports whether the field has a format that applies to it.
+func formatted(f field) bool {
+	if f.format != FormatDate && f.format != FormatDateTime {
+		return false
+	}
+	t := f.typ
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == timeType || t.Kind() == reflect.String
+}
+
+// formatTag provides the tag for scalars decoded into fields with a
+// format tag, so that dates keep their text in string fields and quoted
+// dates can be decoded into time.Time fields.
+func formatTag(n *yaml.Node, t reflect.Type, f *field) string {
+	if f == nil || !formatted(*f) {
+		return ""
+	}
+	switch tag := n.ShortTag(); {
+	case t.Kind() == reflect.String && tag == "!!timestamp":
+		return "!!str"
+	case t == timeType && tag == "!!str" && parseFormatted(n.Value):
+		return "!!timestamp"
+	}
+	return ""
+}
+
+// parseFormatted reports whether the string holds a date or timestamp.
+func parseFormatted(s string) bool {
+	for _, layout := range []string{"2006-01-02", time.RFC3339Nano} {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFormats writes the time.Time fields with the date format as dates.
+func applyFormats(n *yaml.Node, v reflect.Value) {
+	walkValue(v, func(path []string, v reflect.Value, f *field) bool {
+		if f == nil || v.Type() != timeType || f.format != FormatDate {
+			return true
+		}
+		target := n
+		for _, p := range path {
+			if target, _ = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		replaceNode(target, newScalarNode(v.Interface().(time.Time).Format("2006-01-02")))
+		return false
+	})
+}
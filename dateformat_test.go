@@ -0,0 +1,82 @@
+package yaml
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type formatTarget struct {
+	Date      string     `json:"date" format:"date"`
+	Plain     string     `json:"plain"`
+	Day       time.Time  `json:"day" format:"date"`
+	Created   *time.Time `json:"created,omitempty" format:"date-time"`
+	Schedules []struct {
+		Start string `json:"start" format:"date"`
+	} `json:"schedules"`
+}
+
+func TestFormatDecode(t *testing.T) {
+	y := "date: 2024-01-02\nplain: 2024-01-02\nday: '2024-03-04'\ncreated: \"2024-05-06T07:08:09Z\"\nschedules:\n- start: 2024-07-08\n"
+	var s formatTarget
+	if err := Unmarshal([]byte(y), &s); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if s.Date != "2024-01-02" || s.Plain != "2024-01-02T00:00:00Z" {
+		t.Errorf("Unmarshal() = %q, %q; want the date kept only for the formatted field", s.Date, s.Plain)
+	}
+	if !s.Day.Equal(time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unmarshal() day = %v", s.Day)
+	}
+	if s.Created == nil || !s.Created.Equal(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)) {
+		t.Errorf("Unmarshal() created = %v", s.Created)
+	}
+	if len(s.Schedules) != 1 || s.Schedules[0].Start != "2024-07-08" {
+		t.Errorf("Unmarshal() schedules = %+v", s.Schedules)
+	}
+
+	var d formatTarget
+	if err := UnmarshalWithOptions([]byte(y), &d); err != nil || d.Date != s.Date || d.Schedules[0].Start != "2024-07-08" {
+		t.Errorf("UnmarshalWithOptions() = %+v, %v", d, err)
+	}
+
+	// The node tree is left as it was.
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(y), &n); err != nil {
+		t.Fatal(err)
+	}
+	if err := NodeToStruct(&n, &d); err != nil || d.Date != "2024-01-02" {
+		t.Errorf("NodeToStruct() = %+v, %v", d, err)
+	}
+	if tag := n.Content[0].Content[1].Tag; tag != "!!timestamp" {
+		t.Errorf("NodeToStruct() left tag %s; want !!timestamp", tag)
+	}
+}
+
+func TestFormatEncode(t *testing.T) {
+	s := formatTarget{Date: "2024-01-02", Day: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)}
+	y, err := Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := "date: \"2024-01-02\"\nday: \"2024-03-04\"\nplain: \"\"\nschedules: null\n"
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+
+	var back formatTarget
+	if err := Unmarshal(y, &back); err != nil || back.Date != s.Date || back.Day.Format("2006-01-02") != "2024-03-04" {
+		t.Errorf("Unmarshal() = %+v, %v", back, err)
+	}
+}
+
+func TestFormatSchema(t *testing.T) {
+	s := GenerateSchema(formatTarget{})
+	if f := s.Properties["date"].Format; f != "date" {
+		t.Errorf("GenerateSchema() date format = %q; want date", f)
+	}
+	if f := s.Properties["plain"].Format; f != "" {
+		t.Errorf("GenerateSchema() plain format = %q; want none", f)
+	}
+}
@@ -0,0 +1,427 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeOpt is an option for decoding from YAML format.
+type DecodeOpt func(*Decoder)
+
+// Decoder reads and decodes YAML documents from an input stream using the
+// same rules as Unmarshal.
+type Decoder struct {
+	dec           *yaml.Decoder
+	knownFields   bool
+	hooks         decodeHooks
+	includes      IncludeResolver
+	includeDepth  int
+	schema        *Schema
+	allErrors     bool
+	retag         retagOpts
+	verbatim      bool
+	normalizeKeys bool
+	reportTabs    bool
+	tabWidth      int
+	whitespace    whitespaceOpts
+	mergeKeys     MergeKeyMode
+	sharedAliases bool
+	normalizeTags bool
+	gzip          bool
+	snippets      bool
+	checkAliases  bool
+	unusedAnchors bool
+	warner        Warner
+	metrics       Metrics
+	arena         *Arena
+	budget        int64
+	maxScalar     int
+	jsonUnmarshal func(data []byte, v interface{}) error
+	jsonOpts      []JSONOpt
+	limit         *budgetReader
+	counter       *countingReader
+	src           *bytes.Buffer // source read so far, for snippets
+	err           error         // invalid option, returned when decoding
+}
+
+// NewDecoder returns a new decoder that reads from r, configured with the
+// options provided.
+func NewDecoder(r io.Reader, opts ...DecodeOpt) *Decoder {
+	d := &Decoder{includeDepth: DefaultMaxIncludeDepth}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.metrics == nil {
+		d.metrics = currentMetrics()
+	}
+	if d.gzip {
+		r = newGzipReader(r)
+	}
+	if d.metrics != nil {
+		d.counter = &countingReader{r: r}
+		r = d.counter
+	}
+	if d.budget > 0 {
+		d.limit = &budgetReader{r: r, budget: d.budget}
+		r = d.limit
+	}
+	if d.normalizeTags {
+		r = newDirectiveReader(r, d.warner)
+	}
+	if d.whitespace.set() {
+		r = newWhitespaceReader(r, d.whitespace)
+	}
+	if d.tabWidth > 0 {
+		r = newTabReader(r, d.tabWidth)
+	}
+	if d.snippets || d.checkAliases || d.reportTabs {
+		d.src = new(bytes.Buffer)
+		r = io.TeeReader(r, d.src)
+	}
+	d.dec = yaml.NewDecoder(r)
+	return d
+}
+
+// KnownFields ensures that keys in decoded mappings exist as fields in the
+// struct being decoded into.
+func (d *Decoder) KnownFields(enable bool) {
+	d.knownFields = enable
+}
+
+// JSONOptions configures the JSON decoder used for each document with the
+// same options accepted by Unmarshal, such as UseNumber and
+// DisallowUnknownFields.
+func JSONOptions(opts ...JSONOpt) DecodeOpt {
+	return func(d *Decoder) {
+		d.jsonOpts = append(d.jsonOpts, opts...)
+	}
+}
+
+// Decode reads the next YAML document from the input and stores it in the
+// object. At the end of the stream, io.EOF is returned.
+func (d *Decoder) Decode(o interface{}) error {
+	t := startDecode(d.metrics)
+	if t == nil {
+		return d.decode(o, nil)
+	}
+	read := d.counter.n
+	err := d.decode(o, t)
+	if !errors.Is(err, io.EOF) {
+		t.done(d.counter.n-read, err)
+	}
+	return err
+}
+
+func (d *Decoder) decode(o interface{}, t *decodeTracker) error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.limit != nil {
+		d.limit.n = 0
+	}
+	var n yaml.Node
+	if err := d.dec.Decode(&n); err != nil {
+		if errors.Is(err, io.EOF) {
+			return err
+		}
+		return d.withSnippet(fmt.Errorf("error converting YAML to JSON: %w", d.parseError(err)))
+	}
+	t.document(&n)
+	return d.decodeNode(&n, o)
+}
+
+// AllErrors configures the decoder to continue past type errors, unknown
+// fields and duplicate keys, returning every problem found in the document
+// joined together rather than stopping at the first. The values that caused
+// the errors are left unset.
+func AllErrors() DecodeOpt {
+	return func(d *Decoder) {
+		d.allErrors = true
+	}
+}
+
+// UnmarshalWithOptions behaves like Unmarshal but accepts the same options
+// as the Decoder.
+func UnmarshalWithOptions(y []byte, o interface{}, opts ...DecodeOpt) error {
+	err := NewDecoder(bytes.NewReader(y), opts...).Decode(o)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// UnmarshalStrict behaves like Unmarshal but returns an error for keys
+// that do not match a field of the struct being decoded into, as with the
+// Decoder's KnownFields.
+func UnmarshalStrict(y []byte, o interface{}) error {
+	d := NewDecoder(bytes.NewReader(y))
+	d.KnownFields(true)
+	err := d.Decode(o)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+// decodeNode converts the document node into the object, retrying without
+// the offending values when collecting all the errors.
+func (d *Decoder) decodeNode(n *yaml.Node, o interface{}) error {
+	if !d.allErrors {
+		return d.withSnippet(d.decodeNodeOnce(n, o))
+	}
+	// Warnings only need reporting on the first attempt.
+	defer func(w Warner) { d.warner = w }(d.warner)
+	var errs []error
+	for {
+		err := d.decodeNodeOnce(n, o)
+		if err == nil {
+			break
+		}
+		var pe *passErrors
+		if errors.As(err, &pe) {
+			for _, e := range pe.errs {
+				errs = append(errs, d.withSnippet(e))
+			}
+			if pe.stopped == nil {
+				break
+			}
+			for _, e := range pe.errs {
+				removeErrorNode(n, e)
+			}
+			err = pe.stopped
+		}
+		errs = append(errs, d.withSnippet(err))
+		if !removeErrorNode(n, err) {
+			break
+		}
+		d.warner = nil
+	}
+	return joinErrors(errs...)
+}
+
+// passErrors holds the errors found in a single pass over the document,
+// along with the error that stopped the pass early, if any. Only the
+// stopping error requires the document to be decoded again.
+type passErrors struct {
+	errs    []error
+	stopped error
+}
+
+func (e *passErrors) Error() string {
+	return joinErrors(append(e.errs, e.stopped)...).Error()
+}
+
+// parseError replaces parser errors with clearer ones where enabled.
+func (d *Decoder) parseError(err error) error {
+	if d.limit != nil && d.limit.err != nil {
+		return d.limit.err
+	}
+	if d.checkAliases {
+		err = undefinedAliasError(err, d.src.Bytes())
+	}
+	if d.reportTabs {
+		err = tabError(err, d.src.Bytes())
+	}
+	return err
+}
+
+// withSnippet adds an excerpt of the source to the error when enabled.
+func (d *Decoder) withSnippet(err error) error {
+	if err == nil || d.src == nil {
+		return err
+	}
+	return withSnippet(err, d.src.Bytes())
+}
+
+func (d *Decoder) decodeNodeOnce(n *yaml.Node, o interface{}) error {
+	vo := reflect.ValueOf(o)
+	if err := d.prepareNode(n, &vo); err != nil {
+		return err
+	}
+	defer retagTarget(n, reflect.TypeOf(o), d.retag)()
+
+	var collected []error
+	knownFields, direct := directOpts(d.jsonOpts)
+	if direct && len(d.hooks.paths) == 0 && d.hooks.err == nil && d.jsonUnmarshal == nil && canDecodeDirect(n, o) {
+		// When collecting all the errors, unknown fields and type errors
+		// are gathered in the same pass rather than one pass each.
+		cfg := directConfig{knownFields: d.knownFields || knownFields, arena: d.arena}
+		if d.allErrors {
+			cfg.collect = &collected
+		}
+		err := decodeDirect(n, o, cfg)
+		for i, e := range collected {
+			collected[i] = fmt.Errorf("error unmarshaling JSON: %w", e)
+		}
+		if err != nil {
+			err = fmt.Errorf("error unmarshaling JSON: %w", err)
+			if len(collected) == 0 {
+				return err
+			}
+			return &passErrors{errs: collected, stopped: err}
+		}
+	} else {
+		var b *bytes.Buffer
+		if d.arena != nil {
+			b = &d.arena.buf
+			b.Reset()
+		} else {
+			b = getBuffer()
+			defer putBuffer(b)
+		}
+		if err := d.convertNode(b, n, &vo); err != nil {
+			return err
+		}
+		j := b.Bytes()
+		if d.jsonUnmarshal != nil {
+			if d.knownFields || len(d.jsonOpts) > 0 {
+				return errors.New("UnmarshalJSONWith can't be combined with KnownFields or JSONOptions")
+			}
+			if err := d.jsonUnmarshal(j, o); err != nil {
+				return fmt.Errorf("error unmarshaling JSON: %w", err)
+			}
+		} else {
+			opts := d.jsonOpts[:len(d.jsonOpts):len(d.jsonOpts)]
+			if d.knownFields {
+				opts = append(opts, DisallowUnknownFields)
+			}
+			if err := jsonUnmarshal(j, n, o, opts...); err != nil {
+				return fmt.Errorf("error unmarshaling JSON: %w", err)
+			}
+		}
+	}
+	setPositions(n, o)
+	if d.sharedAliases {
+		shareAliases(n, o)
+	}
+	if err := d.hooks.applyTypes(vo); err != nil {
+		if len(collected) == 0 {
+			return err
+		}
+		return &passErrors{errs: collected, stopped: err}
+	}
+	if len(collected) > 0 {
+		return &passErrors{errs: collected}
+	}
+	return nil
+}
+
+// nodeToJSON prepares the document node according to the decoder's options
+// and converts it into JSON, coercing values into strings when required by
+// the target.
+func (d *Decoder) nodeToJSON(n *yaml.Node, target *reflect.Value) ([]byte, error) {
+	if err := d.prepareNode(n, target); err != nil {
+		return nil, err
+	}
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := d.convertNode(b, n, target); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b.Bytes()...), nil
+}
+
+// prepareNode resolves includes and runs the checks and warnings enabled
+// on the decoder against the document node.
+func (d *Decoder) prepareNode(n *yaml.Node, target *reflect.Value) error {
+	if d.includes != nil {
+		if err := resolveIncludes(n, d.includes, d.includeDepth, nil); err != nil {
+			return err
+		}
+	}
+	if d.maxScalar > 0 {
+		if err := checkScalarLength(n, d.maxScalar); err != nil {
+			return err
+		}
+	}
+	if d.budget > 0 {
+		if err := checkBudget(n, d.budget); err != nil {
+			return err
+		}
+	}
+	if len(d.hooks.nodes) > 0 {
+		if err := applyNodeHooks(n, d.hooks.nodes); err != nil {
+			return err
+		}
+	}
+	if d.unusedAnchors {
+		if err := checkUnusedAnchors(n); err != nil {
+			return err
+		}
+	}
+	if d.schema != nil {
+		if err := d.schema.ValidateNode(n); err != nil {
+			return err
+		}
+	}
+
+	if d.normalizeKeys {
+		normalizeKeys(n)
+	}
+	if d.normalizeTags {
+		normalizeTags(n, d.warner, nil)
+	}
+	if d.mergeKeys != MergeKeysResolve {
+		if err := applyMergeKeys(n, d.mergeKeys); err != nil {
+			return fmt.Errorf("error converting YAML to JSON: %w", err)
+		}
+	}
+	if err := checkDuplicateKeys(n); err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %w", err)
+	}
+	if d.warner != nil {
+		var t reflect.Type
+		if target != nil && target.IsValid() {
+			t = target.Type()
+		}
+		warnNode(d.warner, n, t, nil)
+	}
+	return nil
+}
+
+// convertNode writes the JSON for the prepared document node to the
+// buffer.
+func (d *Decoder) convertNode(b *bytes.Buffer, n *yaml.Node, target *reflect.Value) error {
+	yamlObj, err := decodeNodeObject(n)
+	if err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	jsonObj, err := convertToJSONableObject(yamlObj, target)
+	if err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	if d.verbatim {
+		jsonObj = verbatimNumbers(n, jsonObj)
+	}
+	if jsonObj, err = d.hooks.applyPaths(jsonObj); err != nil {
+		return err
+	}
+	if err := encodeJSON(b, jsonObj); err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	return nil
+}
+
+// YAMLToJSONWithOptions behaves like YAMLToJSON but accepts the same
+// options as the Decoder, for example to validate a schema or resolve
+// includes during the conversion.
+func YAMLToJSONWithOptions(y []byte, opts ...DecodeOpt) ([]byte, error) {
+	d := NewDecoder(bytes.NewReader(y), opts...)
+	if d.err != nil {
+		return nil, d.err
+	}
+	var n yaml.Node
+	if err := d.dec.Decode(&n); err != nil && !errors.Is(err, io.EOF) {
+		return nil, d.withSnippet(d.parseError(err))
+	}
+	if n.Kind == 0 {
+		n.Kind = yaml.DocumentNode
+	}
+	j, err := d.nodeToJSON(&n, nil)
+	return j, d.withSnippet(err)
+}
@@ -0,0 +1,112 @@
+package yaml
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(strings.NewReader("a: 1\n---\na: x\nb: 2\n"))
+	var got []UnmarshalStringStruct
+	for {
+		s := UnmarshalStringStruct{}
+		err := d.Decode(&s)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+		got = append(got, s)
+	}
+	if len(got) != 2 || got[0].A != "1" || got[1].A != "x" || got[1].B != "2" {
+		t.Errorf("Decode() = %+v", got)
+	}
+}
+
+func TestDecoderKnownFields(t *testing.T) {
+	d := NewDecoder(strings.NewReader("a: 1\nc: 2\n"))
+	d.KnownFields(true)
+	s := UnmarshalStringStruct{}
+	if err := d.Decode(&s); err == nil || !strings.Contains(err.Error(), `unknown field "c"`) {
+		t.Errorf("Decode() = %v; want unknown field error", err)
+	}
+}
+
+func TestDecoderJSONOptions(t *testing.T) {
+	y := "a: 1.50\nb: [2]\n"
+	want := map[string]interface{}{"a": json.Number("1.5"), "b": []interface{}{json.Number("2")}}
+	var v map[string]interface{}
+	if err := NewDecoder(strings.NewReader(y), JSONOptions(UseNumber)).Decode(&v); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Decode() = %#v; want %#v", v, want)
+	}
+	var u map[string]interface{}
+	if err := Unmarshal([]byte(y), &u, UseNumber); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("Unmarshal() = %#v; want %#v", u, want)
+	}
+
+	s := UnmarshalStringStruct{}
+	d := NewDecoder(strings.NewReader("a: 1\nc: 2\n"), JSONOptions(DisallowUnknownFields))
+	if err := d.Decode(&s); err == nil || !strings.Contains(err.Error(), `unknown field "c"`) {
+		t.Errorf("Decode() = %v; want unknown field error", err)
+	}
+
+	calls := 0
+	custom := func(d *json.Decoder) *json.Decoder {
+		calls++
+		return d
+	}
+	d = NewDecoder(strings.NewReader("a: 1\n---\na: 2\n"), JSONOptions(custom))
+	for {
+		if err := d.Decode(&s); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+	}
+	if calls != 2 || s.A != "2" {
+		t.Errorf("Decode() = %+v with %d calls; want 2 calls", s, calls)
+	}
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	s := UnmarshalStringStruct{}
+	if err := UnmarshalStrict([]byte(""), &s); err != nil {
+		t.Errorf("UnmarshalStrict(empty) = %v", err)
+	}
+	if err := UnmarshalStrict([]byte("a: 1\nc: 2\n"), &s); err == nil || !strings.Contains(err.Error(), `unknown field "c"`) {
+		t.Errorf("UnmarshalStrict() = %v; want unknown field error", err)
+	}
+	if err := UnmarshalStrict([]byte("a: 1\nb: true\n"), &s); err != nil {
+		t.Errorf("UnmarshalStrict() = %v", err)
+	}
+	if want := (UnmarshalStringStruct{A: "1", B: "true"}); s != want {
+		t.Errorf("UnmarshalStrict() = %+v; want %+v", s, want)
+	}
+}
+
+func TestUnmarshalWithOptions(t *testing.T) {
+	s := UnmarshalStringStruct{}
+	if err := UnmarshalWithOptions([]byte(""), &s); err != nil {
+		t.Errorf("UnmarshalWithOptions(empty) = %v", err)
+	}
+	if err := UnmarshalWithOptions([]byte("a: 1\na: 2"), &s); err == nil {
+		t.Errorf("UnmarshalWithOptions(duplicate) = nil; want error")
+	}
+	if err := UnmarshalWithOptions([]byte("a: 1\nb: true"), &s); err != nil {
+		t.Errorf("UnmarshalWithOptions() = %v", err)
+	}
+	if want := (UnmarshalStringStruct{A: "1", B: "true"}); s != want {
+		t.Errorf("UnmarshalWithOptions() = %+v; want %+v", s, want)
+	}
+}
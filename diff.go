@@ -0,0 +1,212 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Difference describes a single change found between two documents.
+type Difference struct {
+	Op   string      // either "add", "remove", or "replace"
+	Path string      // JSON Pointer to the value
+	From interface{} // original value, if any
+	To   interface{} // new value, if any
+}
+
+// String provides a human readable summary of the change.
+func (d Difference) String() string {
+	switch d.Op {
+	case "add":
+		return fmt.Sprintf("+ %s: %s", d.Path, summarizeValue(d.To))
+	case "remove":
+		return fmt.Sprintf("- %s: %s", d.Path, summarizeValue(d.From))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", d.Path, summarizeValue(d.From), summarizeValue(d.To))
+}
+
+// Diff compares the values of the two YAML documents, ignoring formatting,
+// comments, and key order, and returns the list of differences required to
+// turn a into b, ordered by path. Sequences are compared item by item.
+func Diff(a, b []byte) ([]Difference, error) {
+	av, bv, err := diffValues(a, b)
+	if err != nil {
+		return nil, err
+	}
+	return diffValue(nil, av, bv, nil), nil
+}
+
+// Equal reports whether the two YAML documents hold the same values,
+// ignoring formatting, comments, key order and anchors, as compared by
+// Diff. Numbers are compared by value, so 1, 1.0 and 0x1 are equal, while
+// integers too large for a float64, such as 64-bit IDs, are compared
+// exactly.
+func Equal(a, b []byte) (bool, error) {
+	av, bv, err := diffValues(a, b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(av, bv), nil
+}
+
+// DiffJSONPatch compares the two YAML documents and returns a JSON Patch
+// (RFC 6902) that will convert a into b, for use with JSONPatch.
+func DiffJSONPatch(a, b []byte) ([]byte, error) {
+	diffs, err := Diff(a, b)
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]PatchOperation, len(diffs))
+	for i, d := range diffs {
+		ops[i] = PatchOperation{Op: d.Op, Path: d.Path, Value: d.To}
+	}
+	return json.Marshal(ops)
+}
+
+// DiffMergePatch compares the two YAML documents and returns a JSON Merge
+// Patch (RFC 7386) that will convert a into b, for use with MergePatch.
+// Merge patches cannot express null values in b, which will be treated as
+// removals.
+func DiffMergePatch(a, b []byte) ([]byte, error) {
+	av, bv, err := diffValues(a, b)
+	if err != nil {
+		return nil, err
+	}
+	p, _ := mergePatchValue(av, bv)
+	return json.Marshal(p)
+}
+
+func diffValues(a, b []byte) (interface{}, interface{}, error) {
+	var av, bv interface{}
+	if err := Unmarshal(a, &av, UseNumber); err != nil {
+		return nil, nil, err
+	}
+	if err := Unmarshal(b, &bv, UseNumber); err != nil {
+		return nil, nil, err
+	}
+	return exactNumbers(av), exactNumbers(bv), nil
+}
+
+// exactNumbers replaces the json.Number values in the value decoded with
+// UseNumber by float64, so that numbers are compared by value, except for
+// integers that a float64 can't hold exactly. Those are kept as a
+// json.Number in decimal form, so that distinct ones don't compare equal.
+func exactNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = exactNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = exactNumbers(e)
+		}
+	case json.Number:
+		r, ok := new(big.Rat).SetString(string(t))
+		if !ok {
+			return v
+		}
+		f, exact := r.Float64()
+		if !exact && r.IsInt() {
+			return json.Number(r.RatString())
+		}
+		return f
+	}
+	return v
+}
+
+func diffValue(path []string, a, b interface{}, out []Difference) []Difference {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, k := range unionKeys(at, bt) {
+			av, aok := at[k]
+			bv, bok := bt[k]
+			p := append(path, k)
+			switch {
+			case !bok:
+				out = append(out, Difference{Op: "remove", Path: formatPointer(p), From: av})
+			case !aok:
+				out = append(out, Difference{Op: "add", Path: formatPointer(p), To: bv})
+			default:
+				out = diffValue(p, av, bv, out)
+			}
+		}
+		return out
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(at) && i < len(bt); i++ {
+			out = diffValue(append(path, strconv.Itoa(i)), at[i], bt[i], out)
+		}
+		for i := len(at); i < len(bt); i++ {
+			out = append(out, Difference{Op: "add", Path: formatPointer(append(path, strconv.Itoa(i))), To: bt[i]})
+		}
+		// Remove from the end so that indexes remain valid.
+		for i := len(at) - 1; i >= len(bt); i-- {
+			out = append(out, Difference{Op: "remove", Path: formatPointer(append(path, strconv.Itoa(i))), From: at[i]})
+		}
+		return out
+	}
+	if !reflect.DeepEqual(a, b) {
+		out = append(out, Difference{Op: "replace", Path: formatPointer(path), From: a, To: b})
+	}
+	return out
+}
+
+// mergePatchValue returns the merge patch required to convert a into b and
+// true if there are any changes.
+func mergePatchValue(a, b interface{}) (interface{}, bool) {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		return b, !reflect.DeepEqual(a, b)
+	}
+	p := make(map[string]interface{})
+	for _, k := range unionKeys(am, bm) {
+		bv, ok := bm[k]
+		if !ok {
+			p[k] = nil
+			continue
+		}
+		if v, changed := mergePatchValue(am[k], bv); changed {
+			p[k] = v
+		}
+	}
+	return p, len(p) > 0
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// summarizeValue provides a short single line representation of the value.
+func summarizeValue(v interface{}) string {
+	j, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	const max = 60
+	if len(j) > max {
+		return string(j[:max-3]) + "..."
+	}
+	return string(j)
+}
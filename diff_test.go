@@ -0,0 +1,119 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	diffA = `
+name: web
+replicas: 1
+ports: [80, 443, 8443]
+labels:
+  tier: web
+  old: x
+`
+	diffB = `
+# reordered and reformatted
+labels: {tier: api}
+name: web
+ports:
+  - 80
+  - 8080
+replicas: 3
+extra: true
+`
+)
+
+func TestDiff(t *testing.T) {
+	diffs, err := Diff([]byte(diffA), []byte(diffB))
+	if err != nil {
+		t.Fatalf("Diff() = %v", err)
+	}
+	var got []string
+	for _, d := range diffs {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"+ /extra: true",
+		"- /labels/old: \"x\"",
+		"~ /labels/tier: \"web\" -> \"api\"",
+		"~ /ports/1: 443 -> 8080",
+		"- /ports/2: 8443",
+		"~ /replicas: 1 -> 3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %q; want %q", got, want)
+	}
+
+	diffs, err = Diff([]byte("id: 9007199254740993\n"), []byte("id: 9007199254740992\n"))
+	if err != nil || len(diffs) != 1 || diffs[0].String() != "~ /id: 9007199254740993 -> 9007199254740992" {
+		t.Errorf("Diff(large integers) = %v, %v; want one replacement", diffs, err)
+	}
+
+	diffs, err = Diff([]byte(diffA), []byte(diffA+"\n# comment\n"))
+	if err != nil || len(diffs) != 0 {
+		t.Errorf("Diff(same) = %v, %v; want no differences", diffs, err)
+	}
+}
+
+func TestDiffPatches(t *testing.T) {
+	p, err := DiffJSONPatch([]byte(diffA), []byte(diffB))
+	if err != nil {
+		t.Fatalf("DiffJSONPatch() = %v", err)
+	}
+	y, err := JSONPatch([]byte(diffA), p)
+	if err != nil {
+		t.Fatalf("JSONPatch(%s) = %v", p, err)
+	}
+	if diffs, _ := Diff(y, []byte(diffB)); len(diffs) != 0 {
+		t.Errorf("JSONPatch(DiffJSONPatch()) left differences: %v", diffs)
+	}
+
+	mp, err := DiffMergePatch([]byte(diffA), []byte(diffB))
+	if err != nil {
+		t.Fatalf("DiffMergePatch() = %v", err)
+	}
+	if want := `{"extra":true,"labels":{"old":null,"tier":"api"},"ports":[80,8080],"replicas":3}`; string(mp) != want {
+		t.Errorf("DiffMergePatch() = %s; want %s", mp, want)
+	}
+	y, err = MergePatch([]byte(diffA), mp)
+	if err != nil {
+		t.Fatalf("MergePatch(%s) = %v", mp, err)
+	}
+	if diffs, _ := Diff(y, []byte(diffB)); len(diffs) != 0 {
+		t.Errorf("MergePatch(DiffMergePatch()) left differences: %v", diffs)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{diffA, diffA + "\n# comment\n", true},
+		{diffA, diffB, false},
+		{"a: 1\nb: [x, y]\n", "{b: ['x', \"y\"], a: 1.0}", true},
+		{"n: 0x1\n", "n: 1\n", true},
+		{"base: &b {x: 1}\nuse: *b\n", "base: {x: 1}\nuse: {x: 1}\n", true},
+		{"a: [1, 2]\n", "a: [2, 1]\n", false},
+		{"a: '1'\n", "a: 1\n", false},
+		{"a: null\n", "{}", false},
+		{"id: 9007199254740993\n", "id: 9007199254740992\n", false},
+		{"id: 12345678901234567891\n", "id: 12345678901234567892\n", false},
+	}
+	for _, test := range tests {
+		got, err := Equal([]byte(test.a), []byte(test.b))
+		if err != nil {
+			t.Errorf("Equal(%q, %q) = %v", test.a, test.b, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Equal(%q, %q) = %v; want %v", test.a, test.b, got, test.want)
+		}
+	}
+	if _, err := Equal([]byte("a: [1"), []byte("a: 1")); err == nil {
+		t.Errorf("Equal() = nil error; want error")
+	}
+}
@@ -0,0 +1,954 @@
+package yaml
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The direct decoder stores a node tree in a Go value following exactly the
+// rules of the JSON conversion: go-yaml resolves the scalars, the values
+// are coerced into strings where the target requires, and encoding/json
+// decodes the result. Doing all three in a single pass over the node tree
+// avoids building the intermediate objects and JSON text. As Go strings are
+// immutable, decoded strings also share their storage with the node values
+// instead of being copied, so no unsafe mode is needed to avoid the copies.
+//
+// Documents and types using features where reproducing those rules would
+// be fragile, such as aliases, explicit tags, or ",string" fields, are left
+// to the JSON conversion.
+
+// directDecoder holds the state of a single direct decode.
+type directDecoder struct {
+	directConfig
+	err  error       // first recoverable error
+	path []directKey // path to the node being decoded
+}
+
+// directConfig holds the options for a direct decode.
+type directConfig struct {
+	knownFields bool
+	collect     *[]error // receives every recoverable error, when set
+	arena       *Arena   // provides the generic maps and slices, when set
+}
+
+// directKey is an element of the path to the node being decoded, either a
+// mapping key or, when the key is nil, a sequence index.
+type directKey struct {
+	key   *yaml.Node
+	index int
+}
+
+// directOpts reports whether the JSON options can be honored by the direct
+// decoder, and if unknown fields should be rejected.
+func directOpts(opts []JSONOpt) (knownFields, ok bool) {
+	for _, opt := range opts {
+		if reflect.ValueOf(opt).Pointer() != reflect.ValueOf(DisallowUnknownFields).Pointer() {
+			return false, false
+		}
+		knownFields = true
+	}
+	return knownFields, true
+}
+
+// canDecodeDirect reports whether the node, which may be nil for an empty
+// document, can be decoded into the object without going through JSON.
+func canDecodeDirect(n *yaml.Node, o interface{}) bool {
+	if !directEnabled || o == nil || !directType(reflect.TypeOf(o)) {
+		return false
+	}
+	// Values already held in an interface are decoded into, so their
+	// types need checking as well.
+	v := reflect.ValueOf(o)
+	for v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Interface && !v.Elem().IsNil() {
+		v = v.Elem().Elem()
+		if !directType(v.Type()) {
+			return false
+		}
+	}
+	return n == nil || directNode(n)
+}
+
+// decodeDirect decodes the node into the object. Errors are reported in
+// the same way as when decoding the intermediate JSON, unless collect is
+// configured, in which case the errors that do not stop decoding are all
+// appended to it and only an error that stopped decoding is returned.
+func decodeDirect(n *yaml.Node, o interface{}, cfg directConfig) error {
+	if n != nil && n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			n = nil
+		} else {
+			n = n.Content[0]
+		}
+	}
+	if decodeGeneric(n, o, cfg.arena) {
+		return nil
+	}
+	var d *directDecoder
+	if cfg.arena != nil {
+		d = &cfg.arena.dec
+	} else {
+		d = directPool.Get().(*directDecoder)
+	}
+	d.directConfig = cfg
+	// encoding/json is given a pointer to the interface holding the object.
+	err := d.value(n, reflect.ValueOf(&o).Elem(), reflect.ValueOf(o))
+	if err == nil {
+		err = d.err
+	}
+	// Drop the references to the nodes before returning to the pool.
+	path := d.path[:cap(d.path)]
+	for i := range path {
+		path[i] = directKey{}
+	}
+	d.err, d.path, d.directConfig = nil, path[:0], directConfig{}
+	if cfg.arena == nil {
+		directPool.Put(d)
+	}
+	if err != nil {
+		return fmt.Errorf("while decoding JSON: %w", err)
+	}
+	return nil
+}
+
+// decodeGeneric decodes the node into pointers to the generic types that
+// encoding/json produces, which are the most common targets for converters,
+// without using reflection. It returns false if the general rules are
+// needed, for example to report a type error.
+func decodeGeneric(n *yaml.Node, o interface{}, a *Arena) bool {
+	// A null document leaves these targets unchanged.
+	null := n == nil || n.Kind == yaml.ScalarNode && literalOf(n).kind == nullLiteral
+	switch p := o.(type) {
+	case *interface{}:
+		if p == nil || *p != nil && reflect.TypeOf(*p).Kind() == reflect.Ptr {
+			return false
+		}
+		if !null {
+			*p = a.value(n)
+		}
+	case *map[string]interface{}:
+		if p == nil || !null && n.Kind != yaml.MappingNode {
+			return false
+		}
+		if null {
+			break
+		}
+		if *p == nil {
+			*p = a.newMap(len(n.Content) / 2)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			(*p)[n.Content[i].Value] = a.value(n.Content[i+1])
+		}
+	case *[]interface{}:
+		// Existing elements are decoded into, which may need reflection.
+		if p == nil || len(*p) > 0 || !null && n.Kind != yaml.SequenceNode {
+			return false
+		}
+		if null {
+			break
+		}
+		s := (*p)[:0]
+		if cap(s) < len(n.Content) {
+			s = a.newSlice(len(n.Content))[:0]
+		}
+		for _, e := range n.Content {
+			s = append(s, a.value(e))
+		}
+		if len(s) == 0 {
+			s = []interface{}{}
+		}
+		*p = s
+	default:
+		return false
+	}
+	return true
+}
+
+// unmarshalerError converts errors returned by the unmarshaler of the
+// value at the node, giving type errors the node's position.
+func (d *directDecoder) unmarshalerError(n *yaml.Node, err error) error {
+	if err == nil {
+		return nil
+	}
+	err = convertJSONError(err)
+	if te, ok := err.(*TypeError); ok && n != nil {
+		te.tokens = d.tokens()
+		te.Path, te.Line, te.Column = formatPath(te.tokens), n.Line, n.Column
+	}
+	return err
+}
+
+func (d *directDecoder) saveError(err error) {
+	if d.collect != nil {
+		*d.collect = append(*d.collect, fmt.Errorf("while decoding JSON: %w", err))
+		return
+	}
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// tokens returns the path to the node being decoded.
+func (d *directDecoder) tokens() []interface{} {
+	return keyTokens(d.path)
+}
+
+// keyTokens converts the path into the tokens used by the errors.
+func keyTokens(path []directKey) []interface{} {
+	tokens := make([]interface{}, len(path))
+	for i, p := range path {
+		if p.key != nil {
+			tokens[i] = p.key.Value
+		} else {
+			tokens[i] = p.index
+		}
+	}
+	return tokens
+}
+
+func (d *directDecoder) typeError(n *yaml.Node, value string, t reflect.Type) {
+	tokens := d.tokens()
+	te := &TypeError{Path: formatPath(tokens), Value: value, Type: t, tokens: tokens}
+	if n != nil {
+		te.Line, te.Column = n.Line, n.Column
+	}
+	d.saveError(te)
+}
+
+// value decodes the node into v, which may be invalid when the value is
+// to be skipped. The coercion target c is the value used by the JSON
+// conversion to decide when scalars become strings, and is usually, but
+// not always, the same as v.
+func (d *directDecoder) value(n *yaml.Node, v, c reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if c.IsValid() {
+		if ju, tu, pv := indirect(c, false); ju != nil || tu != nil {
+			c = reflect.Value{}
+		} else {
+			c = pv
+		}
+	}
+	if n != nil {
+		switch n.Kind {
+		case yaml.MappingNode:
+			return d.object(n, v, c)
+		case yaml.SequenceNode:
+			return d.array(n, v, c)
+		}
+	}
+	return d.literal(n, v, c)
+}
+
+func (d *directDecoder) object(n *yaml.Node, v, c reflect.Value) error {
+	if d.timeError(n, "object", v) {
+		return nil
+	}
+	u, ut, pv := indirect(v, false)
+	if u != nil {
+		return d.unmarshalerError(n, d.unmarshalJSON(u, n, c))
+	}
+	if ut != nil {
+		d.typeError(n, "object", v.Type())
+		return nil
+	}
+	v = pv
+	t := v.Type()
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(d.arena.object(n)))
+		return nil
+	}
+
+	var plan, cplan *typePlan
+	switch v.Kind() {
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			d.typeError(n, "object", t)
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+	case reflect.Struct:
+		plan = cachedTypePlan(t)
+	default:
+		d.typeError(n, "object", t)
+		return nil
+	}
+	if c.IsValid() && c.Kind() == reflect.Struct {
+		cplan = plan
+		if c.Type() != t {
+			cplan = cachedTypePlan(c.Type())
+		}
+	}
+
+	// The keys are visited in the order they appear in the JSON.
+	order := sortedKeys(n)
+	var mapElem reflect.Value
+	for i := 0; i < len(n.Content)/2; i++ {
+		p := i
+		if order != nil {
+			p = order[i]
+		}
+		k, e := n.Content[2*p], n.Content[2*p+1]
+
+		var subv, subc reflect.Value
+		if v.Kind() == reflect.Map {
+			if !mapElem.IsValid() {
+				mapElem = reflect.New(t.Elem()).Elem()
+			} else {
+				mapElem.Set(reflect.Zero(mapElem.Type()))
+			}
+			subv = mapElem
+		} else if f := plan.field(k.Value); f != nil {
+			subv = v
+			for _, i := range f.index {
+				if subv.Kind() == reflect.Ptr {
+					if subv.IsNil() {
+						subv.Set(reflect.New(subv.Type().Elem()))
+					}
+					subv = subv.Elem()
+				}
+				subv = subv.Field(i)
+			}
+		} else if d.knownFields {
+			d.path = append(d.path, directKey{key: k})
+			tokens := d.tokens()
+			d.path = d.path[:len(d.path)-1]
+			d.saveError(&UnknownFieldError{
+				Path:   formatPath(tokens),
+				Field:  k.Value,
+				Line:   k.Line,
+				Column: k.Column,
+				tokens: tokens,
+			})
+		}
+		if c.IsValid() {
+			switch c.Kind() {
+			case reflect.Struct:
+				if f := cplan.field(k.Value); f != nil {
+					subc = c.Field(f.index[0])
+				}
+			case reflect.Map:
+				subc = reflect.Zero(c.Type().Elem())
+			}
+		}
+
+		d.path = append(d.path, directKey{key: k})
+		err := d.value(e, subv, subc)
+		d.path = d.path[:len(d.path)-1]
+		if err != nil {
+			return err
+		}
+
+		if v.Kind() == reflect.Map {
+			kt := t.Key()
+			var kv reflect.Value
+			switch kt.Kind() {
+			case reflect.String:
+				kv = reflect.New(kt).Elem()
+				kv.SetString(k.Value)
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				x, err := strconv.ParseInt(k.Value, 10, 64)
+				if err != nil || reflect.Zero(kt).OverflowInt(x) {
+					d.keyError(n, k, kt)
+					break
+				}
+				kv = reflect.New(kt).Elem()
+				kv.SetInt(x)
+			default:
+				x, err := strconv.ParseUint(k.Value, 10, 64)
+				if err != nil || reflect.Zero(kt).OverflowUint(x) {
+					d.keyError(n, k, kt)
+					break
+				}
+				kv = reflect.New(kt).Elem()
+				kv.SetUint(x)
+			}
+			if kv.IsValid() {
+				v.SetMapIndex(kv, subv)
+			}
+		}
+	}
+	return nil
+}
+
+// keyError reports a key of the mapping that can't be converted to the
+// key type, at the mapping as encoding/json does.
+func (d *directDecoder) keyError(n, k *yaml.Node, t reflect.Type) {
+	d.typeError(n, "number "+k.Value, t)
+}
+
+// timeError reports a *TypeError for values other than strings decoded
+// into a time.Time when encoding/json/v2 is in use, as it reads times
+// itself rather than calling UnmarshalJSON. It returns true if the error
+// was reported.
+func (d *directDecoder) timeError(n *yaml.Node, value string, v reflect.Value) bool {
+	if !encodingJSONv2 {
+		return false
+	}
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != timeType {
+		return false
+	}
+	d.typeError(n, value, t)
+	return true
+}
+
+func (d *directDecoder) array(n *yaml.Node, v, c reflect.Value) error {
+	if d.timeError(n, "array", v) {
+		return nil
+	}
+	u, ut, pv := indirect(v, false)
+	if u != nil {
+		return d.unmarshalerError(n, d.unmarshalJSON(u, n, c))
+	}
+	if ut != nil {
+		d.typeError(n, "array", v.Type())
+		return nil
+	}
+	v = pv
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(d.arena.array(n)))
+			return nil
+		}
+		fallthrough
+	default:
+		d.typeError(n, "array", v.Type())
+		return nil
+	case reflect.Array, reflect.Slice:
+	}
+
+	// The JSON conversion shares a single element between all the values.
+	var ec reflect.Value
+	if c.IsValid() && c.Kind() == reflect.Slice {
+		ec = reflect.New(c.Type().Elem()).Elem()
+	}
+
+	i := 0
+	for _, e := range n.Content {
+		if v.Kind() == reflect.Slice {
+			if i >= v.Cap() {
+				nv := reflect.MakeSlice(v.Type(), v.Len(), len(n.Content))
+				reflect.Copy(nv, v)
+				v.Set(nv)
+			}
+			if i >= v.Len() {
+				v.SetLen(i + 1)
+			}
+		}
+		var ev reflect.Value
+		if i < v.Len() {
+			ev = v.Index(i)
+		}
+		d.path = append(d.path, directKey{index: i})
+		err := d.value(e, ev, ec)
+		d.path = d.path[:len(d.path)-1]
+		if err != nil {
+			return err
+		}
+		i++
+	}
+
+	if i < v.Len() {
+		if v.Kind() == reflect.Array {
+			for ; i < v.Len(); i++ {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+		} else {
+			v.SetLen(i)
+		}
+	}
+	if i == 0 && v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	return nil
+}
+
+func (d *directDecoder) literal(n *yaml.Node, v, c reflect.Value) error {
+	l := literalOf(n)
+	if c.IsValid() && c.Kind() == reflect.String {
+		l = l.coerce()
+	}
+	val := "number"
+	switch l.kind {
+	case nullLiteral:
+		val = "null"
+	case boolLiteral:
+		val = "bool"
+	case stringLiteral:
+		val = "string"
+	}
+	if l.kind != nullLiteral && l.kind != stringLiteral && d.timeError(n, val, v) {
+		return nil
+	}
+	u, ut, pv := indirect(v, l.kind == nullLiteral)
+	if u != nil {
+		return d.unmarshalerError(n, u.UnmarshalJSON(l.json()))
+	}
+	if ut != nil {
+		if l.kind != stringLiteral {
+			d.typeError(n, val, v.Type())
+			return nil
+		}
+		return d.unmarshalerError(n, ut.UnmarshalText([]byte(l.s)))
+	}
+	v = pv
+
+	switch l.kind {
+	case nullLiteral:
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+	case boolLiteral:
+		switch v.Kind() {
+		default:
+			d.typeError(n, "bool", v.Type())
+		case reflect.Bool:
+			v.SetBool(l.b)
+		case reflect.Interface:
+			if v.NumMethod() == 0 {
+				v.Set(reflect.ValueOf(l.b))
+			} else {
+				d.typeError(n, "bool", v.Type())
+			}
+		}
+	case stringLiteral:
+		switch v.Kind() {
+		default:
+			d.typeError(n, "string", v.Type())
+		case reflect.Slice:
+			if v.Type().Elem().Kind() != reflect.Uint8 {
+				d.typeError(n, "string", v.Type())
+				break
+			}
+			b, err := base64.StdEncoding.DecodeString(l.s)
+			if err != nil && encodingJSONv2 {
+				d.typeError(n, "string", v.Type())
+				break
+			}
+			if err != nil {
+				d.saveError(err)
+				break
+			}
+			v.SetBytes(b)
+		case reflect.String:
+			v.SetString(l.s)
+		case reflect.Interface:
+			if v.NumMethod() == 0 {
+				v.Set(reflect.ValueOf(l.s))
+			} else {
+				d.typeError(n, "string", v.Type())
+			}
+		}
+	default:
+		d.number(n, l, v)
+	}
+	return nil
+}
+
+func (d *directDecoder) number(n *yaml.Node, l directLiteral, v reflect.Value) {
+	switch v.Kind() {
+	default:
+		if v.Kind() == reflect.String && v.Type() == numberType {
+			v.SetString(l.number())
+			break
+		}
+		d.typeError(n, "number", v.Type())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			d.typeError(n, "number", v.Type())
+			break
+		}
+		v.Set(reflect.ValueOf(l.float()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := l.i, error(nil)
+		if l.kind != intLiteral {
+			i, err = strconv.ParseInt(l.number(), 10, 64)
+		}
+		if err != nil || v.OverflowInt(i) {
+			d.typeError(n, "number "+l.number(), v.Type())
+			break
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := l.u, error(nil)
+		if l.kind != uintLiteral {
+			u, err = strconv.ParseUint(l.number(), 10, 64)
+		}
+		if err != nil || v.OverflowUint(u) {
+			d.typeError(n, "number "+l.number(), v.Type())
+			break
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := l.float(), error(nil)
+		if v.Kind() == reflect.Float32 {
+			f, err = strconv.ParseFloat(l.number(), 32)
+		}
+		if err != nil || v.OverflowFloat(f) {
+			d.typeError(n, "number "+l.number(), v.Type())
+			// encoding/json/v2 stores the infinity that the number
+			// rounds to.
+			if encodingJSONv2 && math.IsInf(f, 0) {
+				v.SetFloat(f)
+			}
+			break
+		}
+		v.SetFloat(f)
+	}
+}
+
+// unmarshalJSON passes the JSON for the node to the unmarshaler.
+func (d *directDecoder) unmarshalJSON(u json.Unmarshaler, n *yaml.Node, c reflect.Value) error {
+	obj := nodeObject(n)
+	var target *reflect.Value
+	if c.IsValid() {
+		target = &c
+	}
+	j, err := yamlObjectToJSON(obj, target)
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalJSON(j)
+}
+
+// nodeObject builds the value go-yaml decodes the node into when the
+// target is an empty interface, with the keys already converted into
+// strings. The node must have been accepted by directNode.
+func nodeObject(n *yaml.Node) interface{} {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return nodeObject(n.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			m[n.Content[i].Value] = nodeObject(n.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		a := make([]interface{}, len(n.Content))
+		for i, e := range n.Content {
+			a[i] = nodeObject(e)
+		}
+		return a
+	}
+	switch l := literalOf(n); l.kind {
+	case nullLiteral:
+		return nil
+	case boolLiteral:
+		return l.b
+	case stringLiteral:
+		return l.s
+	case intLiteral:
+		if l.i == int64(int(l.i)) {
+			return int(l.i)
+		}
+		return l.i
+	case uintLiteral:
+		return l.u
+	default:
+		return l.f
+	}
+}
+
+// sortedKeys returns the order of the pairs in the mapping once sorted by
+// key, as json.Marshal writes them, or nil if they are already sorted.
+func sortedKeys(n *yaml.Node) []int {
+	sorted := true
+	for i := 2; i < len(n.Content); i += 2 {
+		if n.Content[i].Value < n.Content[i-2].Value {
+			sorted = false
+			break
+		}
+	}
+	if sorted {
+		return nil
+	}
+	order := make([]int, len(n.Content)/2)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return n.Content[2*order[i]].Value < n.Content[2*order[j]].Value
+	})
+	return order
+}
+
+// Kinds of directLiteral.
+const (
+	nullLiteral = iota
+	boolLiteral
+	stringLiteral
+	intLiteral
+	uintLiteral
+	floatLiteral
+)
+
+// directLiteral is a scalar as resolved by go-yaml.
+type directLiteral struct {
+	kind int
+	b    bool
+	s    string
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// literalOf resolves the scalar node, which may be nil for null. The node
+// must have been accepted by directNode.
+func literalOf(n *yaml.Node) directLiteral {
+	l, _ := resolveLiteral(n)
+	return l
+}
+
+// resolveLiteral resolves the scalar node the same way as go-yaml, returning
+// false for the values that are not supported.
+func resolveLiteral(n *yaml.Node) (directLiteral, bool) {
+	if n == nil {
+		return directLiteral{}, true
+	}
+	switch n.ShortTag() {
+	case "!!null":
+		return directLiteral{}, true
+	case "!!str":
+		return directLiteral{kind: stringLiteral, s: n.Value}, utf8.ValidString(n.Value)
+	case "!!bool":
+		switch n.Value {
+		case "true", "True", "TRUE":
+			return directLiteral{kind: boolLiteral, b: true}, true
+		case "false", "False", "FALSE":
+			return directLiteral{kind: boolLiteral}, true
+		}
+	case "!!int":
+		plain := n.Value
+		if strings.IndexByte(plain, '_') >= 0 {
+			plain = strings.ReplaceAll(plain, "_", "")
+		}
+		if i, err := strconv.ParseInt(plain, 0, 64); err == nil {
+			return directLiteral{kind: intLiteral, i: i}, true
+		}
+		if u, err := strconv.ParseUint(plain, 0, 64); err == nil {
+			return directLiteral{kind: uintLiteral, u: u}, true
+		}
+	case "!!float":
+		plain := n.Value
+		if strings.IndexByte(plain, '_') >= 0 {
+			plain = strings.ReplaceAll(plain, "_", "")
+		}
+		if f, err := strconv.ParseFloat(plain, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+			return directLiteral{kind: floatLiteral, f: f}, true
+		}
+	}
+	return directLiteral{}, false
+}
+
+// coerce converts numbers and booleans into strings, as the JSON conversion
+// does when the target is a string.
+func (l directLiteral) coerce() directLiteral {
+	switch l.kind {
+	case boolLiteral:
+		return directLiteral{kind: stringLiteral, s: strconv.FormatBool(l.b)}
+	case intLiteral:
+		return directLiteral{kind: stringLiteral, s: strconv.FormatInt(l.i, 10)}
+	case uintLiteral:
+		return directLiteral{kind: stringLiteral, s: strconv.FormatUint(l.u, 10)}
+	case floatLiteral:
+		return directLiteral{kind: stringLiteral, s: strconv.FormatFloat(l.f, 'g', -1, 64)}
+	}
+	return l
+}
+
+// appendString appends the string that coerce would give for the scalar.
+func (l directLiteral) appendString(b []byte) []byte {
+	switch l.kind {
+	case boolLiteral:
+		return strconv.AppendBool(b, l.b)
+	case intLiteral:
+		return strconv.AppendInt(b, l.i, 10)
+	case uintLiteral:
+		return strconv.AppendUint(b, l.u, 10)
+	case floatLiteral:
+		return strconv.AppendFloat(b, l.f, 'g', -1, 64)
+	}
+	return append(b, l.s...)
+}
+
+func (l directLiteral) float() float64 {
+	switch l.kind {
+	case intLiteral:
+		return float64(l.i)
+	case uintLiteral:
+		return float64(l.u)
+	}
+	return l.f
+}
+
+// number provides the number as written by json.Marshal.
+func (l directLiteral) number() string {
+	switch l.kind {
+	case intLiteral:
+		return strconv.FormatInt(l.i, 10)
+	case uintLiteral:
+		return strconv.FormatUint(l.u, 10)
+	}
+	abs := math.Abs(l.f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b := strconv.AppendFloat(nil, l.f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return string(b)
+}
+
+// json provides the literal as it appears in the JSON conversion.
+func (l directLiteral) json() []byte {
+	switch l.kind {
+	case nullLiteral:
+		return []byte("null")
+	case boolLiteral:
+		return []byte(strconv.FormatBool(l.b))
+	case stringLiteral:
+		j, _ := json.Marshal(l.s)
+		return j
+	}
+	return []byte(l.number())
+}
+
+// directNode reports whether every value in the node tree can be decoded
+// directly.
+func directNode(n *yaml.Node) bool {
+	if n.Style&yaml.TaggedStyle != 0 {
+		return false
+	}
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			if !directNode(c) {
+				return false
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if !directKeyNode(n.Content[i]) || !directNode(n.Content[i+1]) {
+				return false
+			}
+		}
+	case yaml.ScalarNode:
+		_, ok := resolveLiteral(n)
+		return ok
+	default:
+		return false
+	}
+	return true
+}
+
+// directKeyNode reports whether the mapping key is converted into a JSON
+// key identical to its text. Other keys, such as 0x10 which becomes "16",
+// could collide with another key after being converted.
+func directKeyNode(k *yaml.Node) bool {
+	if k.Kind != yaml.ScalarNode || k.Style&yaml.TaggedStyle != 0 {
+		return false
+	}
+	l, ok := resolveLiteral(k)
+	if !ok {
+		return false
+	}
+	switch l.kind {
+	case stringLiteral:
+		return true
+	case nullLiteral:
+		return false
+	}
+	// Compared without allocating the converted key.
+	var b [32]byte
+	return string(l.appendString(b[:0])) == k.Value
+}
+
+var numberType = reflect.TypeOf(json.Number(""))
+
+var directTypes sync.Map // map[reflect.Type]bool
+
+// directType reports whether values of the type can be decoded directly.
+// Struct fields using the ",string" option, fields embedded from unexported
+// structs, and json.Number need the JSON conversion.
+func directType(t reflect.Type) bool {
+	if ok, found := directTypes.Load(t); found {
+		return ok.(bool)
+	}
+	ok := directTypeVisit(t, make(map[reflect.Type]bool))
+	directTypes.Store(t, ok)
+	return ok
+}
+
+func directTypeVisit(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+	if t == numberType || implementsUnmarshalerFrom(t) || implementsUnmarshalerFrom(reflect.PtrTo(t)) {
+		return false
+	}
+	if t.Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return directTypeVisit(t.Elem(), seen)
+	case reflect.Map:
+		if reflect.PtrTo(t.Key()).Implements(textUnmarshalerType) {
+			return false
+		}
+		return directTypeVisit(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.Anonymous && sf.PkgPath != "" {
+				return false
+			}
+			if sf.PkgPath != "" || sf.Tag.Get("json") == "-" {
+				continue
+			}
+			if _, opts := parseTag(sf.Tag.Get("json")); opts.Contains("string") {
+				return false
+			}
+			if !directTypeVisit(sf.Type, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
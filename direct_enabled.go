@@ -0,0 +1,8 @@
+//go:build !tinygo && !yaml_lite
+// +build !tinygo,!yaml_lite
+
+package yaml
+
+// directEnabled allows documents to be decoded without the intermediate
+// JSON.
+const directEnabled = true
@@ -0,0 +1,9 @@
+//go:build tinygo || yaml_lite
+// +build tinygo yaml_lite
+
+package yaml
+
+// directEnabled is false for TinyGo and builds with the yaml_lite tag, so
+// every document is decoded through encoding/json instead of the reflection
+// heavy direct decoder.
+const directEnabled = false
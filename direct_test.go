@@ -0,0 +1,377 @@
+package yaml
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"gopkg.in/yaml.v3"
+)
+
+type DirectEmbedded struct {
+	Label string `json:"label"`
+	Count int
+}
+
+type directText struct{ s string }
+
+func (t *directText) UnmarshalText(b []byte) error {
+	if string(b) == "bad" {
+		return fmt.Errorf("bad text")
+	}
+	t.s = strings.ToUpper(string(b))
+	return nil
+}
+
+type directJSON struct{ raw string }
+
+func (j *directJSON) UnmarshalJSON(b []byte) error {
+	j.raw = string(b)
+	return nil
+}
+
+type directTarget struct {
+	*DirectEmbeddedPtr
+	DirectEmbedded
+	Name     string                 `json:"name"`
+	Ptr      *string                `json:"ptr"`
+	Int      int8                   `json:"int"`
+	Uint     uint                   `json:"uint"`
+	Float    float32                `json:"float"`
+	Bool     bool                   `json:"bool"`
+	Bytes    []byte                 `json:"bytes"`
+	List     []string               `json:"list"`
+	Array    [2]int                 `json:"array"`
+	Map      map[string]int         `json:"map"`
+	IntMap   map[int]*string        `json:"intMap"`
+	Any      interface{}            `json:"any"`
+	AnyMap   map[string]interface{} `json:"anyMap"`
+	Nested   *directTarget          `json:"nested"`
+	Text     directText             `json:"text"`
+	JSON     directJSON             `json:"json"`
+	Time     time.Time              `json:"time"`
+	Duration time.Duration          `json:"duration"`
+}
+
+type directEmbedded struct {
+	A int `json:"a"`
+}
+
+type DirectEmbeddedPtr struct {
+	Kind string `json:"kind"`
+}
+
+var directTests = []string{
+	"",
+	"name: web\nint: 12\nuint: 3\nfloat: 1.5\nbool: true\n",
+	"name: 12\nptr: 3.0\nlist: [1, 2.50, true, x]\n",
+	"name: 1e30\nptr: 1e-7\nlist: [0x1F, 1_000, 0o17]\n",
+	"label: 5\nCOUNT: 2\nkind: 7\n",
+	"int: 300\n",
+	"int: 1.0\nuint: -1\n",
+	"int: 1.5\n",
+	"uint: 18446744073709551615\nfloat: 1e30\n",
+	"bytes: aGVsbG8=\n",
+	"list: []\narray: [1, 2, 3]\n",
+	"array: [1]\n",
+	"map: {b: 2, a: 1}\nintMap: {1: x, 2: null, -3: z}\n",
+	"intMap: {x: a}\n",
+	"any: {b: [1, {c: null}], a: 1}\nanyMap: {x: 1.5, 'y': yes, z: true}\n",
+	"any: [1, x]\n",
+	"any: ~\nptr: null\nlist: null\nmap: null\nname: null\n",
+	"nested: {name: inner, nested: {int: 2}}\n",
+	"text: abc\njson: {a: '<b>', z: 1}\n",
+	"text: 1\n",
+	"text: bad\n",
+	"json: 12\ntime: 2023-01-02T03:04:05Z\n",
+	"duration: 30\n",
+	"name: {a: 1}\n",
+	"name: [a]\n",
+	"int: x\nuint: y\n",
+	"list: {a: 1}\nmap: [1]\n",
+	"nested: {list: [a, {b: 1}]}\n",
+	"Name: upper\nNAME: case\n",
+	"b: 1\na: 2\nunknown: 3\n",
+	"'1': x\ntrue: y\n2: z\n",
+	"list: ['0x10', \"true\"]\n",
+	"- 1\n- 2\n",
+	"just a string\n",
+	"~\n",
+	"[]\n",
+	"{}\n",
+	"name: &a x\nptr: *a\n",
+	"<<: {name: merged}\n",
+	"name: !!str 12\n",
+	"float: .inf\n",
+	"0x10: a\n",
+	"date: 2001-12-14\n",
+	"time: 1\n",
+	"bytes: '***'\n",
+	"float: 1e40\n",
+	"float: -1e40\n",
+	"intMap: {a: x}\n",
+	"text: [1]\n",
+	"json: {a: 1}\ntext: bad\n",
+}
+
+var directTargets = []func() interface{}{
+	func() interface{} { return &directTarget{} },
+	func() interface{} {
+		return &directTarget{List: []string{"a", "b", "c"}, Array: [2]int{7, 8}, Map: map[string]int{"old": 1}}
+	},
+	func() interface{} { var v interface{}; return &v },
+	func() interface{} { return &map[string]interface{}{"old": 1} },
+	func() interface{} { return &map[string]string{} },
+	func() interface{} { return &[]string{} },
+	func() interface{} { var s string; return &s },
+	func() interface{} { var p *directTarget; return &p },
+	func() interface{} { var m map[string]interface{}; return &m },
+	func() interface{} { return &[]interface{}{} },
+	func() interface{} { a := make([]interface{}, 0, 1); return &a },
+	func() interface{} { return &[]interface{}{"old", 2.0, nil} },
+	func() interface{} { var v interface{} = "old"; return &v },
+	func() interface{} { var v interface{} = &directTarget{}; return &v },
+}
+
+// decodeViaJSON decodes the node by converting it into JSON first.
+func decodeViaJSON(n *yaml.Node, o interface{}, opts ...JSONOpt) error {
+	vo := reflect.ValueOf(o)
+	j, err := yamlNodeToJSON(n, &vo)
+	if err != nil {
+		return err
+	}
+	if err := jsonUnmarshal(j, n, o, opts...); err != nil {
+		return fmt.Errorf("error unmarshaling JSON: %w", err)
+	}
+	return nil
+}
+
+func TestDecodeDirect(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	direct := 0
+	for _, y := range directTests {
+		for _, target := range directTargets {
+			for _, knownFields := range []bool{false, true} {
+				var opts []JSONOpt
+				if knownFields {
+					opts = append(opts, DisallowUnknownFields)
+				}
+				n, err := decodeYAMLNode(yaml.NewDecoder(strings.NewReader(y)))
+				if err != nil {
+					t.Fatalf("decodeYAMLNode(%q) = %v", y, err)
+				}
+				want, got := target(), target()
+				wantErr := decodeViaJSON(n, want, opts...)
+				if !canDecodeDirect(n, got) {
+					continue
+				}
+				direct++
+				gotErr := decodeDirect(n, got, directConfig{knownFields: knownFields})
+				if gotErr != nil {
+					gotErr = fmt.Errorf("error unmarshaling JSON: %w", gotErr)
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("decodeDirect(%q, %T) = %+v; want %+v", y, got, reflect.ValueOf(got).Elem(), reflect.ValueOf(want).Elem())
+				}
+				if errorSummary(gotErr) != errorSummary(wantErr) {
+					t.Errorf("decodeDirect(%q, %T) error = %v; want %v", y, got, gotErr, wantErr)
+				}
+			}
+		}
+	}
+	if direct == 0 {
+		t.Errorf("decodeDirect() was never used")
+	}
+}
+
+// errorSummary describes the error by its type, position and path, without
+// the wrapping, which differs between the two decoders.
+func errorSummary(err error) string {
+	var te *TypeError
+	if errors.As(err, &te) {
+		return fmt.Sprintf("%d:%d %s cannot unmarshal %s into %s", te.Line, te.Column, te.Path, te.Value, te.Type)
+	}
+	var ue *UnknownFieldError
+	if errors.As(err, &ue) {
+		return "unknown field " + ue.Field
+	}
+	return fmt.Sprint(err)
+}
+
+// The JSON conversion gives different results for these depending on
+// whether encoding/json is implemented by encoding/json/v2, and the direct
+// decoder follows the one in use.
+func TestDecodeDirectOverflow(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	var s directTarget
+	err := Unmarshal([]byte("float: 1e40\nint: 3\n"), &s)
+	var te *TypeError
+	if !errors.As(err, &te) || te.Value != "number 1e+40" || te.Path != "float" || te.Line != 1 {
+		t.Errorf("Unmarshal() = %v; want *TypeError for number 1e+40 at float", err)
+	}
+	want := float32(0)
+	if encodingJSONv2 {
+		want = float32(math.Inf(1))
+	}
+	if s.Float != want || s.Int != 3 {
+		t.Errorf("Unmarshal() = %+v; want Float %v and Int 3", s, want)
+	}
+
+	err = Unmarshal([]byte("bytes: '***'\n"), &s)
+	var ce base64.CorruptInputError
+	if encodingJSONv2 {
+		if !errors.As(err, &te) || te.Path != "bytes" || te.Line != 1 || te.Column != 8 {
+			t.Errorf("Unmarshal() = %v; want *TypeError at bytes", err)
+		}
+	} else if !errors.As(err, &ce) {
+		t.Errorf("Unmarshal() = %v; want base64.CorruptInputError", err)
+	}
+
+	err = Unmarshal([]byte("time: 1\n"), &s)
+	if encodingJSONv2 && (!errors.As(err, &te) || te.Path != "time" || te.Value != "number" || te.Column != 7) {
+		t.Errorf("Unmarshal() = %v; want *TypeError for number at time", err)
+	}
+}
+
+func TestDecodeDirectKeyError(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	var s directTarget
+	err := Unmarshal([]byte("intMap: {1: x, a: y}\n"), &s)
+	var te *TypeError
+	if !errors.As(err, &te) || te.Path != "intMap" || te.Line != 1 || te.Column != 9 || te.Value != "number a" {
+		t.Errorf("Unmarshal() = %v; want *TypeError for number a at intMap, column 9", err)
+	}
+}
+
+func TestCanDecodeDirect(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	tests := []struct {
+		y    string
+		o    interface{}
+		want bool
+	}{
+		{"a: 1\n", &map[string]int{}, true},
+		{"a: &x 1\nb: *x\n", &map[string]int{}, false},
+		{"a: !!str 1\n", &map[string]string{}, false},
+		{"1.0: a\n", &map[string]string{}, false},
+		{"[1]: a\n", &map[string]string{}, false},
+		{"a: 1\n", &struct {
+			A int `json:"a,string"`
+		}{}, false},
+		{"a: 1\n", nil, false},
+		{"a: 1\n", &struct{ directEmbedded }{}, false},
+		{"a: 1\n", func() interface{} { var v interface{} = &struct{ directEmbedded }{}; return &v }(), false},
+		{"a: 1\n", func() interface{} { var v interface{} = 1; return &v }(), true},
+	}
+	for _, test := range tests {
+		n, _ := decodeYAMLNode(yaml.NewDecoder(strings.NewReader(test.y)))
+		if got := canDecodeDirect(n, test.o); got != test.want {
+			t.Errorf("canDecodeDirect(%q, %T) = %v; want %v", test.y, test.o, got, test.want)
+		}
+	}
+}
+
+func TestDecodeDirectSharesStrings(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	doc, err := ParseDocument([]byte("name: web\nlabels: {tier: frontend}\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	var s struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	}
+	root := doc.Node().Content[0]
+	if err := NodeToStruct(root, &s); err != nil {
+		t.Fatalf("NodeToStruct() = %v", err)
+	}
+	labels := root.Content[3]
+	for _, c := range []struct{ got, node string }{
+		{s.Name, root.Content[1].Value},
+		{s.Labels["tier"], labels.Content[1].Value},
+	} {
+		if stringData(c.got) != stringData(c.node) {
+			t.Errorf("NodeToStruct() copied %q instead of sharing the node value", c.got)
+		}
+	}
+	for k := range s.Labels {
+		if stringData(k) != stringData(labels.Content[0].Value) {
+			t.Errorf("NodeToStruct() copied key %q instead of sharing the node value", k)
+		}
+	}
+}
+
+func TestNodeObject(t *testing.T) {
+	tests := append([]string{
+		"- 9223372036854775807\n- 18446744073709551615\n- 0x_1F\n- .5\n- -0.0\n",
+		"1: a\ntrue: b\n'c': [d, {e: ~}]\n",
+	}, directTests...)
+	for _, y := range tests {
+		n, err := decodeYAMLNode(yaml.NewDecoder(strings.NewReader(y)))
+		if err != nil || n == nil || !directNode(n) {
+			continue
+		}
+		var obj interface{}
+		if err := n.Decode(&obj); err != nil {
+			t.Fatalf("Decode(%q) = %v", y, err)
+		}
+		want, err := convertToJSONableObject(obj, nil)
+		if err != nil {
+			t.Fatalf("convertToJSONableObject(%q) = %v", y, err)
+		}
+		if got := nodeObject(n); !reflect.DeepEqual(got, want) {
+			t.Errorf("nodeObject(%q) = %#v; want %#v", y, got, want)
+		}
+	}
+}
+
+func TestDirectKeyNode(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"name", true},
+		{"12", true},
+		{"-3", true},
+		{"true", true},
+		{"1.5", true},
+		{"1.0", false},
+		{"0x10", false},
+		{"~", false},
+	}
+	for _, test := range tests {
+		var n yaml.Node
+		if err := yaml.Unmarshal([]byte(test.key+": x\n"), &n); err != nil {
+			t.Fatalf("yaml.Unmarshal(%q) = %v", test.key, err)
+		}
+		k := n.Content[0].Content[0]
+		if got := directKeyNode(k); got != test.want {
+			t.Errorf("directKeyNode(%q) = %v; want %v", test.key, got, test.want)
+		}
+		if allocs := testing.AllocsPerRun(10, func() { directKeyNode(k) }); allocs != 0 {
+			t.Errorf("directKeyNode(%q) allocated %v times", test.key, allocs)
+		}
+	}
+}
+
+// stringData returns the address of the bytes backing s, which is the first
+// word of a string header.
+func stringData(s string) uintptr {
+	return *(*uintptr)(unsafe.Pointer(&s))
+}
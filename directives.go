@@ -0,0 +1,138 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NormalizeTags configures the decoder to accept documents written by
+// other emitters that go-yaml would reject or misread. %YAML directives for
+// versions other than 1.1 and unknown directives are removed, while %TAG
+// directives are kept. Tags that the conversion to JSON does not
+// understand, such as !Ref or !!python/object, are removed so that values
+// are read as if they had not been tagged, rather than as strings. Each
+// removal is sent to the Warner given with ReportWarnings, if any.
+func NormalizeTags() DecodeOpt {
+	return func(d *Decoder) {
+		d.normalizeTags = true
+	}
+}
+
+// normalizeTags removes the unknown tags from the node tree.
+func normalizeTags(n *yaml.Node, w Warner, path []interface{}) {
+	if n.Kind != yaml.DocumentNode && n.Kind != yaml.AliasNode && n.Tag != "" && !knownTags[n.ShortTag()] {
+		if w != nil {
+			w.Warn(Warning{
+				Path:    formatPath(path),
+				Line:    n.Line,
+				Column:  n.Column,
+				Message: fmt.Sprintf("unknown tag %s removed", n.Tag),
+			})
+		}
+		n.Tag = ""
+		n.Style &^= yaml.TaggedStyle
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			normalizeTags(c, w, path)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			p := append(path[:len(path):len(path)], k.Value)
+			normalizeTags(k, w, p)
+			normalizeTags(v, w, p)
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			normalizeTags(c, w, append(path[:len(path):len(path)], i))
+		}
+	}
+}
+
+// directiveReader comments out the directives that go-yaml rejects. Lines
+// starting with "%" are only taken as directives when they are followed by
+// the "---" that starts a document.
+type directiveReader struct {
+	r       *bufio.Reader
+	warner  Warner
+	line    int
+	pending [][]byte // possible directives and the comments between them
+	lines   []int    // line numbers of the pending lines
+	out     []byte
+	err     error
+}
+
+func newDirectiveReader(r io.Reader, w Warner) *directiveReader {
+	return &directiveReader{r: bufio.NewReader(r), warner: w}
+}
+
+func (d *directiveReader) Read(p []byte) (int, error) {
+	for len(d.out) == 0 {
+		if d.err != nil {
+			if len(d.pending) == 0 {
+				return 0, d.err
+			}
+			d.flush(false)
+			continue
+		}
+		l, err := d.r.ReadBytes('\n')
+		d.err = err
+		if len(l) == 0 {
+			continue
+		}
+		d.line++
+		switch {
+		case l[0] == '%':
+			d.pending = append(d.pending, l)
+			d.lines = append(d.lines, d.line)
+			continue
+		case len(d.pending) > 0 && l[0] == '#':
+			d.pending = append(d.pending, l)
+			d.lines = append(d.lines, 0)
+			continue
+		}
+		d.flush(bytes.HasPrefix(l, []byte("---")))
+		d.out = append(d.out, l...)
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+// flush writes the pending lines, commenting out the unsupported directives
+// when they start a document.
+func (d *directiveReader) flush(directives bool) {
+	for i, l := range d.pending {
+		if directives && l[0] == '%' && !supportedDirective(l) {
+			if d.warner != nil {
+				d.warner.Warn(Warning{
+					Line:    d.lines[i],
+					Column:  1,
+					Message: fmt.Sprintf("directive %s removed", bytes.TrimSpace(l)),
+				})
+			}
+			// Commented out, so that lines keep their numbers.
+			d.out = append(d.out, "# "...)
+		}
+		d.out = append(d.out, l...)
+	}
+	d.pending, d.lines = d.pending[:0], d.lines[:0]
+}
+
+// supportedDirective reports whether go-yaml accepts the directive.
+func supportedDirective(l []byte) bool {
+	f := bytes.Fields(l)
+	switch string(f[0]) {
+	case "%TAG":
+		return true
+	case "%YAML":
+		return len(f) > 1 && string(f[1]) == "1.1"
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package yaml
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	y := []byte(`%YAML 1.2
+# written by another emitter
+%FOO bar
+%TAG !e! tag:example.com,2000:app/
+---
+ref: !Ref bucket
+count: !custom 3
+nested: !!python/object:app.Config {debug: !e!flag true}
+quoted: !!str 12
+list: !!set [x]
+...
+%YAML 1.2
+---
+second: 1
+`)
+	var warnings []Warning
+	d := NewDecoder(bytes.NewReader(y), NormalizeTags(), ReportWarnings(WarnerFunc(func(w Warning) {
+		warnings = append(warnings, w)
+	})))
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	want := map[string]interface{}{
+		"ref":    "bucket",
+		"count":  3.0,
+		"nested": map[string]interface{}{"debug": true},
+		"quoted": "12",
+		"list":   []interface{}{"x"},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Decode() = %#v; want %#v", v, want)
+	}
+	if err := d.Decode(&v); err != nil || !reflect.DeepEqual(v, map[string]interface{}{"second": 1.0}) {
+		t.Errorf("Decode() = %#v, %v", v, err)
+	}
+	var msgs []string
+	for _, w := range warnings {
+		msgs = append(msgs, w.String())
+	}
+	wantMsgs := []string{
+		"1:1: directive %YAML 1.2 removed",
+		"12:1: directive %YAML 1.2 removed",
+		"3:1: directive %FOO bar removed",
+		"6:6: ref: unknown tag !Ref removed",
+		"7:8: count: unknown tag !custom removed",
+		"8:9: nested: unknown tag !!python/object:app.Config removed",
+		"8:44: nested.debug: unknown tag tag:example.com,2000:app/flag removed",
+		"10:7: list: unknown tag !!set removed",
+	}
+	// The directives are found as the input is read, before decoding.
+	sort.Strings(msgs)
+	sort.Strings(wantMsgs)
+	if !reflect.DeepEqual(msgs, wantMsgs) {
+		t.Errorf("warnings = %q; want %q", msgs, wantMsgs)
+	}
+
+	if err := Unmarshal(y, &v); err == nil {
+		t.Errorf("Unmarshal() = nil; want directive error")
+	}
+	if err := UnmarshalWithOptions([]byte("%x: 1\n"), &v, NormalizeTags()); err == nil {
+		t.Errorf("UnmarshalWithOptions() = nil; want error for content starting with %%")
+	}
+}
@@ -0,0 +1,72 @@
+package yaml
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldDocs writes the documentation of each struct field as a comment on
+// the lines before its key, to produce annotated configuration files. The
+// documentation is taken from the field's doc tag, or from docs, which
+// maps paths, using the same syntax as Query, including wildcards, to the
+// text for the value found there. Paths in docs take precedence, and can
+// also document map entries. Comments from CommentedValue are kept.
+func FieldDocs(docs map[string]string) EncodeOpt {
+	return func(e *encoder) {
+		e.docs = true
+		for path, doc := range docs {
+			segs, err := parsePath(path)
+			if err != nil {
+				e.err = err
+				return
+			}
+			e.pathDocs = append(e.pathDocs, pathDoc{path: segs, doc: doc})
+		}
+	}
+}
+
+// MarshalWithDocs marshals the object as Marshal does, with the
+// documentation of each field written as a comment, as described by
+// FieldDocs.
+func MarshalWithDocs(o interface{}, docs map[string]string, opts ...EncodeOpt) ([]byte, error) {
+	return MarshalWithOptions(o, append(opts, FieldDocs(docs))...)
+}
+
+// pathDoc holds the documentation given for a path.
+type pathDoc struct {
+	path []pathSegment
+	doc  string
+}
+
+// applyDocs sets the documentation of the fields in the value as comments
+// on the keys of the node tree generated for it.
+func applyDocs(n *yaml.Node, v reflect.Value, docs []pathDoc) {
+	walkValue(v, func(path []string, _ reflect.Value, f *field) bool {
+		if len(path) == 0 {
+			return true
+		}
+		doc := ""
+		if f != nil {
+			doc = f.doc
+		}
+		for _, d := range docs {
+			if matchesPath(d.path, path) {
+				doc = d.doc
+			}
+		}
+		if doc == "" {
+			return true
+		}
+		target, key := n, (*yaml.Node)(nil)
+		for _, p := range path {
+			if target, key = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		if key != nil && key.HeadComment == "" {
+			key.HeadComment = doc
+		}
+		return true
+	})
+}
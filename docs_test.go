@@ -0,0 +1,66 @@
+package yaml
+
+import "testing"
+
+type docsServer struct {
+	Host    string            `json:"host" doc:"Address to listen on."`
+	Port    int               `json:"port" doc:"Port to listen on.\nUse 0 for any free port."`
+	TLS     *docsTLS          `json:"tls,omitempty" doc:"TLS settings."`
+	Labels  map[string]string `json:"labels"`
+	Backend []docsBackend     `json:"backends"`
+	Note    CommentedValue    `json:"note" doc:"Ignored."`
+}
+
+type docsTLS struct {
+	Cert string `json:"cert" doc:"Path to the certificate."`
+}
+
+type docsBackend struct {
+	URL string `json:"url"`
+}
+
+func TestMarshalWithDocs(t *testing.T) {
+	s := docsServer{
+		Host:    "localhost",
+		Port:    8080,
+		TLS:     &docsTLS{Cert: "cert.pem"},
+		Labels:  map[string]string{"env": "dev"},
+		Backend: []docsBackend{{URL: "http://a"}, {URL: "http://b"}},
+		Note:    CommentedValue{Value: "x", Head: "Kept."},
+	}
+	y, err := MarshalWithDocs(s, map[string]string{
+		"host":            "Host name or address.",
+		"labels.env":      "Deployment environment.",
+		"backends[*].url": "Backend address.",
+	})
+	if err != nil {
+		t.Fatalf("MarshalWithDocs() = %v", err)
+	}
+	want := `backends:
+    - # Backend address.
+      url: http://a
+    - # Backend address.
+      url: http://b
+# Host name or address.
+host: localhost
+labels:
+    # Deployment environment.
+    env: dev
+# Kept.
+note: x
+# Port to listen on.
+# Use 0 for any free port.
+port: 8080
+# TLS settings.
+tls:
+    # Path to the certificate.
+    cert: cert.pem
+`
+	if string(y) != want {
+		t.Errorf("MarshalWithDocs() = %s; want %s", y, want)
+	}
+
+	if _, err := MarshalWithDocs(s, map[string]string{"a[": "x"}); err == nil {
+		t.Errorf("MarshalWithDocs() = nil; want path error")
+	}
+}
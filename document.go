@@ -0,0 +1,149 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultIndent is the indentation used by go-yaml when none is set.
+const defaultIndent = 4
+
+// parseDocument parses the first YAML document in data into a node tree,
+// always returning a document node so that the content can be replaced.
+func parseDocument(data []byte) (*yaml.Node, error) {
+	doc := new(yaml.Node)
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+	return doc, nil
+}
+
+// encodeDocument outputs the node tree using the given indentation.
+func encodeDocument(n *yaml.Node, indent int) ([]byte, error) {
+	if n.Kind == yaml.DocumentNode && len(n.Content) == 0 {
+		return []byte{}, nil
+	}
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// detectIndent guesses the indentation used in the source data by looking
+// for the smallest amount of leading whitespace on a content line.
+func detectIndent(data []byte) int {
+	indent := 0
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Bytes()
+		trimmed := bytes.TrimLeft(line, " ")
+		n := len(line) - len(trimmed)
+		if n == 0 || len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if indent == 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent < 2 || indent > 9 {
+		return defaultIndent
+	}
+	return indent
+}
+
+// resolveAlias follows alias nodes to the node they point at.
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// newScalarNode builds a plain string scalar node, as used for keys.
+func newScalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// Document holds a parsed YAML document so that it can be inspected and
+// edited before being written back out. Comments, key order, anchors and
+// quoting styles are maintained, and output uses the source's indentation.
+//
+// A document that has not been modified is written back out exactly as it
+// was read, byte for byte, so tools that rewrite files only change those
+// that they edit.
+type Document struct {
+	root   *yaml.Node
+	indent int
+	src    []byte // original source, when it holds a single document
+	orig   []byte // encoding of the tree as parsed
+}
+
+// ParseDocument parses the first YAML document contained in the data.
+func ParseDocument(y []byte) (*Document, error) {
+	root, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	d := &Document{root: root, indent: detectIndent(y)}
+	if singleDocument(y) {
+		if d.orig, err = encodeDocument(root, d.indent); err == nil {
+			d.src = append([]byte{}, y...)
+		}
+	}
+	return d, nil
+}
+
+// singleDocument returns true if the stream contains no more than one
+// document, so the whole source represents the parsed document.
+func singleDocument(y []byte) bool {
+	dec := yaml.NewDecoder(bytes.NewReader(y))
+	var n yaml.Node
+	if err := dec.Decode(&n); err != nil {
+		return errors.Is(err, io.EOF)
+	}
+	return errors.Is(dec.Decode(&n), io.EOF)
+}
+
+// Node provides the document node at the root of the tree, which may be
+// modified directly.
+func (d *Document) Node() *yaml.Node {
+	return d.root
+}
+
+// Modified returns true if the tree has been changed in a way that affects
+// the output since the document was parsed.
+func (d *Document) Modified() bool {
+	if d.src == nil {
+		return true
+	}
+	out, err := encodeDocument(d.root, d.indent)
+	return err != nil || !bytes.Equal(out, d.orig)
+}
+
+// Bytes re-emits the document as YAML. If the document has not been
+// modified, the original source is returned unchanged, including blank
+// lines and formatting that the node tree does not record.
+func (d *Document) Bytes() ([]byte, error) {
+	out, err := encodeDocument(d.root, d.indent)
+	if err != nil {
+		return nil, err
+	}
+	if d.src != nil && bytes.Equal(out, d.orig) {
+		return append([]byte{}, d.src...), nil
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	sources := []string{
+		"",
+		"# only a comment\n",
+		"a:   1  # spaced\n\n\nb: 'single'\nc: \"double\"\n",
+		"base: &b\n   x: 1\nother:\n   <<: *b\n   list:\n   - 1\n   -   2\n",
+		"---\nkey: |\n  text\n\n...\n",
+		"flow: {a: 1,   b: [x,y]}\r\nnext: 2\r\n",
+	}
+	for _, src := range sources {
+		d, err := ParseDocument([]byte(src))
+		if err != nil {
+			t.Errorf("ParseDocument(%q) = %v", src, err)
+			continue
+		}
+		if d.Modified() {
+			t.Errorf("ParseDocument(%q).Modified() = true; want false", src)
+		}
+		out, err := d.Bytes()
+		if err != nil || string(out) != src {
+			t.Errorf("ParseDocument(%q).Bytes() = %q, %v; want source", src, out, err)
+		}
+	}
+}
+
+func TestDocumentModified(t *testing.T) {
+	src := "a:   1\n\nb: 2\n"
+	d, err := ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	ns, _ := d.Query("b")
+	ns[0].Value = "3"
+	if !d.Modified() {
+		t.Errorf("Modified() = false; want true")
+	}
+	out, _ := d.Bytes()
+	if string(out) != "a: 1\nb: 3\n" {
+		t.Errorf("Bytes() = %q", out)
+	}
+
+	// Reverting the change restores the original source.
+	ns[0].Value = "2"
+	if out, _ := d.Bytes(); string(out) != src {
+		t.Errorf("Bytes() = %q; want %q", out, src)
+	}
+
+	// Only the first document of a stream is kept.
+	d, _ = ParseDocument([]byte("a: 1\n---\nb: 2\n"))
+	if out, _ := d.Bytes(); string(out) != "a: 1\n" {
+		t.Errorf("Bytes() = %q; want first document", out)
+	}
+}
@@ -0,0 +1,181 @@
+package yaml
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written in the ISO 8601 form, such as P3DT4H
+// or PT1.5S, as used by many scheduling formats. Weeks and days are taken
+// to be 7 and 24 hours long, while years and months, which have no fixed
+// length, are rejected.
+type Duration time.Duration
+
+// String provides the duration in ISO 8601 form.
+func (d Duration) String() string {
+	return FormatISODuration(time.Duration(d))
+}
+
+// MarshalText provides the duration in ISO 8601 form.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText reads a duration in ISO 8601 form.
+func (d *Duration) UnmarshalText(b []byte) error {
+	v, err := ParseISODuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// isoUnits holds the designators of the ISO 8601 duration units, in the
+// order they must appear, for the date and time parts.
+var isoUnits = [2]string{"WD", "HMS"}
+
+// isoLengths holds the length of each ISO 8601 duration unit.
+var isoLengths = map[byte]uint64{
+	'W': uint64(7 * 24 * time.Hour),
+	'D': uint64(24 * time.Hour),
+	'H': uint64(time.Hour),
+	'M': uint64(time.Minute),
+	'S': uint64(time.Second),
+}
+
+// ParseISODuration reads a duration in the ISO 8601 form, such as P3DT4H,
+// with an optional leading sign. Each unit may appear once, in order, and
+// the last value may have a fraction.
+func ParseISODuration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("yaml: invalid ISO 8601 duration %q", s)
+	p := s
+	neg := false
+	if p != "" && (p[0] == '-' || p[0] == '+') {
+		neg = p[0] == '-'
+		p = p[1:]
+	}
+	if len(p) < 3 || p[0] != 'P' {
+		return 0, invalid
+	}
+	p = p[1:]
+	// The magnitude is summed exactly, and may reach 1<<63 when negative.
+	limit := uint64(math.MaxInt64)
+	if neg {
+		limit++
+	}
+	var total uint64
+	part, next, frac := 0, 0, false
+	for p != "" {
+		if p[0] == 'T' {
+			if part == 1 || len(p) == 1 {
+				return 0, invalid
+			}
+			part, next = 1, 0
+			p = p[1:]
+			continue
+		}
+		i := 0
+		for i < len(p) && p[i] >= '0' && p[i] <= '9' {
+			i++
+		}
+		whole, fraction := p[:i], ""
+		if i < len(p) && (p[i] == '.' || p[i] == ',') {
+			j := i + 1
+			for j < len(p) && p[j] >= '0' && p[j] <= '9' {
+				j++
+			}
+			fraction, i = p[i+1:j], j
+			if fraction == "" {
+				return 0, invalid
+			}
+		}
+		if whole == "" || i == len(p) || frac {
+			// only the last value may have a fraction
+			return 0, invalid
+		}
+		u := strings.IndexByte(isoUnits[part][next:], p[i])
+		if u < 0 {
+			return 0, invalid
+		}
+		next += u + 1
+		unit := isoLengths[p[i]]
+		v, ok := isoValue(whole, fraction, unit)
+		if !ok || v > limit-total {
+			return 0, invalid
+		}
+		total += v
+		frac = fraction != ""
+		p = p[i+1:]
+	}
+	if neg {
+		return time.Duration(-total), nil
+	}
+	return time.Duration(total), nil
+}
+
+// isoValue provides the length of the value in nanoseconds, rounded to the
+// nearest, reporting false when it overflows.
+func isoValue(whole, fraction string, unit uint64) (uint64, bool) {
+	w, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil || w > math.MaxUint64/unit {
+		return 0, false
+	}
+	v := w * unit
+	if fraction == "" {
+		return v, true
+	}
+	f, _ := new(big.Int).SetString(fraction, 10)
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(fraction))), nil)
+	f.Mul(f, new(big.Int).SetUint64(unit))
+	f.Add(f, new(big.Int).Rsh(den, 1))
+	f.Quo(f, den)
+	if !f.IsUint64() || f.Uint64() > math.MaxUint64-v {
+		return 0, false
+	}
+	return v + f.Uint64(), true
+}
+
+// FormatISODuration writes the duration in the ISO 8601 form, using days,
+// hours, minutes and seconds, such as P1DT2H30M or PT0.5S.
+func FormatISODuration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var b strings.Builder
+	u := uint64(d)
+	if d < 0 {
+		b.WriteByte('-')
+		u = -u
+	}
+	b.WriteByte('P')
+	day := uint64(24 * time.Hour)
+	if days := u / day; days > 0 {
+		b.WriteString(strconv.FormatUint(days, 10) + "D")
+		u %= day
+	}
+	if u == 0 {
+		return b.String()
+	}
+	b.WriteByte('T')
+	if h := u / uint64(time.Hour); h > 0 {
+		b.WriteString(strconv.FormatUint(h, 10) + "H")
+		u %= uint64(time.Hour)
+	}
+	if m := u / uint64(time.Minute); m > 0 {
+		b.WriteString(strconv.FormatUint(m, 10) + "M")
+		u %= uint64(time.Minute)
+	}
+	if u > 0 {
+		s := strconv.FormatUint(u/uint64(time.Second), 10)
+		if frac := u % uint64(time.Second); frac > 0 {
+			s += strings.TrimRight(fmt.Sprintf(".%09d", frac), "0")
+		}
+		b.WriteString(s + "S")
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package yaml
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseISODuration(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"P3DT4H":      3*24*time.Hour + 4*time.Hour,
+		"PT1H30M":     90 * time.Minute,
+		"PT1.5S":      1500 * time.Millisecond,
+		"PT0,25S":     250 * time.Millisecond,
+		"P1W":         7 * 24 * time.Hour,
+		"-PT2M":       -2 * time.Minute,
+		"PT0S":        0,
+		"P1DT0.5H":    24*time.Hour + 30*time.Minute,
+		"+P0DT0H1M1S": 61 * time.Second,
+	} {
+		got, err := ParseISODuration(s)
+		if err != nil || got != want {
+			t.Errorf("ParseISODuration(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "P", "PT", "P1Y", "P2M", "PT1D", "P1H", "P1DT", "PT1.5H30M", "3DT4H", "PT1.2.3S", "PTT1H",
+		"PT1H1H", "PT1M1H", "P1DT1S1M", "P1D1W", "PT.5S", "PT1.S",
+		"PT9223372036.854775808S", "-PT9223372036.854775809S", "P99999999999999999999D"} {
+		if _, err := ParseISODuration(s); err == nil {
+			t.Errorf("ParseISODuration(%q) = nil error; want error", s)
+		}
+	}
+}
+
+func TestISODurationRoundTrip(t *testing.T) {
+	for _, d := range []time.Duration{
+		365*24*time.Hour + time.Nanosecond,
+		200*24*time.Hour + 123456789,
+		math.MaxInt64,
+		math.MinInt64,
+		math.MaxInt64 - 1,
+		math.MinInt64 + 1,
+		-time.Nanosecond,
+	} {
+		s := FormatISODuration(d)
+		got, err := ParseISODuration(s)
+		if err != nil || got != d {
+			t.Errorf("ParseISODuration(%q) = %d, %v; want %d", s, got, err, d)
+		}
+	}
+	for s, want := range map[string]time.Duration{
+		"P200DT0.123456789S":      200*24*time.Hour + 123456789,
+		"PT9223372036.854775807S": math.MaxInt64,
+		"PT0.0000000005S":         time.Nanosecond,
+		"PT0.0000000004S":         0,
+		"P0.1W":                   time.Duration(0.7 * 24 * float64(time.Hour)),
+	} {
+		got, err := ParseISODuration(s)
+		if err != nil || got != want {
+			t.Errorf("ParseISODuration(%q) = %d, %v; want %d", s, got, err, want)
+		}
+	}
+}
+
+func TestFormatISODuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                            "PT0S",
+		3*24*time.Hour + 4*time.Hour: "P3DT4H",
+		48 * time.Hour:               "P2D",
+		90 * time.Minute:             "PT1H30M",
+		1500 * time.Millisecond:      "PT1.5S",
+		-2 * time.Minute:             "-PT2M",
+		time.Nanosecond:              "PT0.000000001S",
+		time.Duration(-1 << 63):      "-P106751DT23H47M16.854775808S",
+	} {
+		if got := FormatISODuration(d); got != want {
+			t.Errorf("FormatISODuration(%v) = %q; want %q", d, got, want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	type job struct {
+		Timeout Duration            `json:"timeout"`
+		Windows map[Duration]string `json:"windows,omitempty"`
+	}
+	var j job
+	if err := Unmarshal([]byte("timeout: P1DT12H\nwindows: {PT5M: short}\n"), &j); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if time.Duration(j.Timeout) != 36*time.Hour || j.Windows[Duration(5*time.Minute)] != "short" {
+		t.Errorf("Unmarshal() = %+v", j)
+	}
+	y, err := Marshal(j)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "timeout: P1DT12H\nwindows:\n    PT5M: short\n"; string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+	if err := Unmarshal([]byte("timeout: 1h\n"), &j); err == nil {
+		t.Errorf("Unmarshal() = nil; want duration error")
+	}
+}
@@ -0,0 +1,396 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetValue replaces the single scalar value found at the path expression
+// (as accepted by Query) and returns the updated YAML. Wherever possible the
+// new value is spliced directly into the original bytes so that everything
+// else in the document, including comments, anchors, quoting, blank lines
+// and indentation, remains untouched. The existing quoting style is reused
+// when the new value allows it. Block and multi-line scalars fall back to
+// re-emitting the document in the same way as SetPath.
+func SetValue(y []byte, path string, value interface{}) ([]byte, error) {
+	doc, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	nodes, err := QueryNodes(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(nodes) == 0:
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	case len(nodes) > 1:
+		return nil, fmt.Errorf("path %s matched %d values, expected one", path, len(nodes))
+	}
+	old := nodes[0]
+	if old.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("value at %s is not a scalar", path)
+	}
+
+	n, err := StructToNode(value)
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("new value for %s is not a scalar", path)
+	}
+
+	if out, ok := spliceScalar(y, doc, old, n); ok {
+		return out, nil
+	}
+	replaceNode(old, n)
+	return encodeDocument(doc, detectIndent(y))
+}
+
+// spliceScalar attempts to replace the source text of the old scalar with
+// the encoded form of the new one.
+func spliceScalar(y []byte, doc, old, n *yaml.Node) ([]byte, bool) {
+	if old.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, false
+	}
+	start, end, ok := scalarSpan(y, old, inFlow(doc, old))
+	if !ok {
+		return nil, false
+	}
+
+	if n.Tag == "!!str" && n.Style == 0 {
+		n.Style = old.Style
+	}
+	text, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: n.Tag, Value: n.Value, Style: n.Style})
+	if err != nil {
+		return nil, false
+	}
+	text = bytes.TrimSuffix(text, []byte("\n"))
+	if bytes.ContainsAny(text, "\n") {
+		return nil, false
+	}
+
+	out := make([]byte, 0, len(y)-(end-start)+len(text))
+	out = append(out, y[:start]...)
+	out = append(out, text...)
+	return append(out, y[end:]...), true
+}
+
+// pointerEntry is a mapping entry, or sequence item, on the way to the
+// location referenced by a JSON Pointer.
+type pointerEntry struct {
+	parent *yaml.Node
+	index  int // index in the parent's content, of the key for mappings
+}
+
+// value provides the value of the entry.
+func (e pointerEntry) value() *yaml.Node {
+	if e.parent.Kind == yaml.MappingNode {
+		return e.parent.Content[e.index+1]
+	}
+	return e.parent.Content[e.index]
+}
+
+// next provides the entry that follows in the parent, if any.
+func (e pointerEntry) next() (pointerEntry, bool) {
+	step := 1
+	if e.parent.Kind == yaml.MappingNode {
+		step = 2
+	}
+	e.index += step
+	return e, e.index < len(e.parent.Content)
+}
+
+// splicePointer attempts to set the node at the location referenced by the
+// tokens by replacing, or inserting, only the source text of the entry
+// that changes. The new node is not modified.
+func splicePointer(y []byte, doc *yaml.Node, tokens []string, n *yaml.Node, indent int) ([]byte, bool) {
+	if len(tokens) == 0 || len(doc.Content) == 0 {
+		return nil, false
+	}
+	entries := make([]pointerEntry, len(tokens))
+	parent := doc.Content[0]
+	for i, t := range tokens {
+		e := pointerEntry{parent: parent, index: -1}
+		switch parent.Kind {
+		case yaml.MappingNode:
+			for j := 0; j+1 < len(parent.Content); j += 2 {
+				if parent.Content[j].Value == t {
+					e.index = j
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if j, ok := arrayIndex(t); ok && j < len(parent.Content) {
+				e.index = j
+			}
+		default:
+			return nil, false // aliases have their text elsewhere
+		}
+		if e.index < 0 && i < len(tokens)-1 {
+			return nil, false
+		}
+		entries[i] = e
+		if e.index >= 0 {
+			parent = e.value()
+		}
+	}
+
+	last := &entries[len(entries)-1]
+	if last.index >= 0 {
+		if old := last.value(); old.Kind == yaml.ScalarNode && n.Kind == yaml.ScalarNode {
+			c := *n
+			return spliceScalar(y, doc, old, &c)
+		}
+	}
+	if last.parent.Style&yaml.FlowStyle != 0 || len(last.parent.Content) == 0 {
+		return nil, false
+	}
+
+	var entry *yaml.Node
+	replace := last.index >= 0
+	if replace {
+		entry = replacedEntry(*last, n)
+	} else {
+		// Added after the parent's last entry, at the same column.
+		if last.parent.Kind == yaml.MappingNode {
+			last.index = len(last.parent.Content) - 2
+			entry = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{newScalarNode(tokens[len(tokens)-1]), n}}
+		} else {
+			last.index = len(last.parent.Content) - 1
+			entry = &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{n}}
+		}
+	}
+	start, col, ok := entryStart(y, *last)
+	if !ok {
+		return nil, false
+	}
+	end, ok := entryEnd(y, entries, start)
+	if !ok {
+		return nil, false
+	}
+	text, err := encodeDocument(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{entry}}, indent)
+	if err != nil {
+		return nil, false
+	}
+
+	pad := bytes.Repeat([]byte(" "), col)
+	out := make([]byte, 0, len(y)+len(text)+len(pad)+1)
+	if replace {
+		out = append(out, y[:start]...)
+	} else {
+		out = append(out, y[:end]...)
+		if end > 0 && y[end-1] != '\n' {
+			out = append(out, '\n')
+		}
+		out = append(out, pad...)
+	}
+	for i, l := range bytes.SplitAfter(bytes.TrimSuffix(text, []byte("\n")), []byte("\n")) {
+		if i > 0 && len(l) > 1 {
+			out = append(out, pad...)
+		}
+		out = append(out, l...)
+	}
+	out = append(out, '\n')
+	return append(out, y[end:]...), true
+}
+
+// replacedEntry provides the mapping, or sequence, holding the entry with
+// its value replaced, written in place of the entry's source text. The
+// comments before and after the entry are left in the source.
+func replacedEntry(e pointerEntry, n *yaml.Node) *yaml.Node {
+	v := *e.value()
+	replaceNode(&v, n)
+	v.HeadComment, v.FootComment = "", ""
+	if e.parent.Kind == yaml.SequenceNode {
+		return &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{&v}}
+	}
+	k := *e.parent.Content[e.index]
+	k.HeadComment, k.FootComment = "", ""
+	return &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{&k, &v}}
+}
+
+// entryStart provides the offset where the entry starts, at its key or
+// the "-" of the item, and its column, reporting false unless only
+// indentation and "-" indicators come before it on its line.
+func entryStart(y []byte, e pointerEntry) (int, int, bool) {
+	n := e.parent.Content[e.index]
+	off, ok := lineColumnOffset(y, n.Line, n.Column)
+	if !ok {
+		return 0, 0, false
+	}
+	if e.parent.Kind == yaml.SequenceNode {
+		// Items may start on the line after their "-".
+		for off > 0 && (y[off-1] == ' ' || y[off-1] == '\n') {
+			off--
+		}
+		if off == 0 || y[off-1] != '-' {
+			return 0, 0, false
+		}
+		off--
+	} else if off < len(y) && y[off] == '?' {
+		return 0, 0, false
+	}
+	line := bytes.LastIndexByte(y[:off], '\n') + 1
+	for i := line; i < off; i++ {
+		if y[i] != ' ' && !(y[i] == '-' && i+1 < off && y[i+1] == ' ') {
+			return 0, 0, false
+		}
+	}
+	return off, off - line, true
+}
+
+// entryEnd provides the offset of the line after the last entry of the
+// path, which starts at start: the line of the entry that follows it, or
+// of the one following its parents, without the blank and comment lines
+// in between. Those are left in place for the entries they belong to.
+func entryEnd(y []byte, entries []pointerEntry, start int) (int, bool) {
+	end := len(y)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if next, ok := entries[i].next(); ok {
+			off, _, ok := entryStart(y, next)
+			if !ok {
+				return 0, false
+			}
+			end = bytes.LastIndexByte(y[:off], '\n') + 1
+			break
+		}
+	}
+	for end > start {
+		line := bytes.LastIndexByte(y[:end-1], '\n') + 1
+		if line <= start {
+			break
+		}
+		if t := bytes.TrimSpace(y[line:end]); len(t) > 0 && t[0] != '#' {
+			break
+		}
+		end = line
+	}
+	return end, end > start
+}
+
+// scalarSpan finds the byte offsets of the scalar's text in the source,
+// returning false if the text could not be determined reliably.
+func scalarSpan(y []byte, n *yaml.Node, flow bool) (int, int, bool) {
+	start, ok := lineColumnOffset(y, n.Line, n.Column)
+	if !ok {
+		return 0, 0, false
+	}
+	// Skip over any anchor or tag preceding the value.
+	for start < len(y) && (y[start] == '&' || y[start] == '!') {
+		for start < len(y) && y[start] != ' ' && y[start] != '\n' {
+			start++
+		}
+		for start < len(y) && y[start] == ' ' {
+			start++
+		}
+	}
+	end := start
+	switch {
+	case n.Style&yaml.DoubleQuotedStyle != 0:
+		end = quotedEnd(y, start, '"')
+	case n.Style&yaml.SingleQuotedStyle != 0:
+		end = quotedEnd(y, start, '\'')
+	default:
+		end = plainEnd(y, start, flow)
+	}
+	if end <= start {
+		return 0, 0, false
+	}
+
+	// Confirm the text we found represents the same value.
+	var v yaml.Node
+	if err := yaml.Unmarshal(y[start:end], &v); err != nil || len(v.Content) != 1 {
+		return 0, 0, false
+	}
+	if v.Content[0].Kind != yaml.ScalarNode || v.Content[0].Value != n.Value {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// lineColumnOffset converts a 1-based line and column, as provided by
+// go-yaml, into a byte offset.
+func lineColumnOffset(y []byte, line, col int) (int, bool) {
+	off := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(y[off:], '\n')
+		if i < 0 {
+			return 0, false
+		}
+		off += i + 1
+	}
+	for c := 1; c < col; c++ {
+		if off >= len(y) || y[off] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(y[off:])
+		off += size
+	}
+	return off, true
+}
+
+// quotedEnd returns the offset after the closing quote of a quoted scalar
+// that starts at offset start.
+func quotedEnd(y []byte, start int, q byte) int {
+	if start >= len(y) || y[start] != q {
+		return -1
+	}
+	for i := start + 1; i < len(y); i++ {
+		switch {
+		case q == '"' && y[i] == '\\':
+			i++
+		case y[i] == q:
+			if q == '\'' && i+1 < len(y) && y[i+1] == '\'' {
+				i++ // escaped single quote
+				continue
+			}
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// plainEnd returns the offset at the end of a single line plain scalar.
+func plainEnd(y []byte, start int, flow bool) int {
+	end := start
+	for i := start; i < len(y); i++ {
+		c := y[i]
+		if c == '\n' || c == '\r' {
+			break
+		}
+		if c == '#' && i > start && (y[i-1] == ' ' || y[i-1] == '\t') {
+			break
+		}
+		if flow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if c != ' ' && c != '\t' {
+			end = i + 1
+		}
+	}
+	return end
+}
+
+// inFlow returns true if the target node is contained within a flow
+// style collection.
+func inFlow(n, target *yaml.Node) bool {
+	var find func(n *yaml.Node, flow bool) (bool, bool)
+	find = func(n *yaml.Node, flow bool) (bool, bool) {
+		if n == target {
+			return true, flow
+		}
+		flow = flow || n.Style&yaml.FlowStyle != 0
+		for _, c := range n.Content {
+			if found, f := find(c, flow); found {
+				return true, f
+			}
+		}
+		return false, false
+	}
+	_, flow := find(n, false)
+	return flow
+}
@@ -0,0 +1,65 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	src := `# Release values
+image:
+  repository: example/app   # upstream
+  tag: "1.2.3"
+  pull: &policy 'IfNotPresent'
+other:  *policy
+
+ports: [80, 443]
+notes: |
+  keep this
+`
+	for _, tc := range []struct {
+		path  string
+		value interface{}
+		want  string
+	}{
+		{
+			"image.tag", "1.3.0",
+			"# Release values\nimage:\n  repository: example/app   # upstream\n  tag: \"1.3.0\"\n  pull: &policy 'IfNotPresent'\nother:  *policy\n\nports: [80, 443]\nnotes: |\n  keep this\n",
+		},
+		{
+			"image.repository", "example/api",
+			"# Release values\nimage:\n  repository: example/api   # upstream\n  tag: \"1.2.3\"\n  pull: &policy 'IfNotPresent'\nother:  *policy\n\nports: [80, 443]\nnotes: |\n  keep this\n",
+		},
+		{
+			"image.repository", "123",
+			"# Release values\nimage:\n  repository: \"123\"   # upstream\n  tag: \"1.2.3\"\n  pull: &policy 'IfNotPresent'\nother:  *policy\n\nports: [80, 443]\nnotes: |\n  keep this\n",
+		},
+		{
+			"image.pull", "Always",
+			"# Release values\nimage:\n  repository: example/app   # upstream\n  tag: \"1.2.3\"\n  pull: &policy 'Always'\nother:  *policy\n\nports: [80, 443]\nnotes: |\n  keep this\n",
+		},
+		{
+			"ports[1]", 8443,
+			"# Release values\nimage:\n  repository: example/app   # upstream\n  tag: \"1.2.3\"\n  pull: &policy 'IfNotPresent'\nother:  *policy\n\nports: [80, 8443]\nnotes: |\n  keep this\n",
+		},
+		{
+			// Block scalars are re-emitted.
+			"notes", "changed\n",
+			"# Release values\nimage:\n  repository: example/app # upstream\n  tag: \"1.2.3\"\n  pull: &policy 'IfNotPresent'\nother: *policy\nports: [80, 443]\nnotes: |\n  changed\n",
+		},
+	} {
+		got, err := SetValue([]byte(src), tc.path, tc.value)
+		if err != nil {
+			t.Errorf("SetValue(%q) = %v", tc.path, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("SetValue(%q) = %q; want %q", tc.path, got, tc.want)
+		}
+	}
+
+	for _, path := range []string{"image", "missing", "ports[*]"} {
+		if _, err := SetValue([]byte(src), path, "x"); err == nil {
+			t.Errorf("SetValue(%q) = nil; want error", path)
+		}
+	}
+}
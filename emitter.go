@@ -0,0 +1,317 @@
+package yaml
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EventKind identifies the kind of an Event.
+type EventKind int
+
+// The kinds of events accepted by an Emitter. Every document starts and
+// ends with a document event around a single root node, and collections
+// are closed by the end event matching their start.
+const (
+	DocumentStartEvent EventKind = iota + 1
+	DocumentEndEvent
+	MappingStartEvent
+	MappingEndEvent
+	SequenceStartEvent
+	SequenceEndEvent
+	ScalarEvent
+	AliasEvent
+)
+
+// Event is one step in writing YAML with an Emitter.
+type Event struct {
+	Kind   EventKind
+	Value  string     // value of a scalar, or the anchor an alias refers to
+	Tag    string     // tag of a scalar or collection, such as "!!str"
+	Anchor string     // anchor defined on a scalar or collection
+	Style  yaml.Style // style of a scalar, or yaml.FlowStyle for a collection
+}
+
+// Emitter writes YAML from a stream of events, for documents that are too
+// large or too dynamic to be built as Go values for Marshal. The output is
+// laid out as Marshal would lay it out, with collections in block style
+// unless they start with yaml.FlowStyle. Mapping keys must be scalars or
+// aliases written on a single line.
+//
+// Output is buffered until the Emitter is flushed. Errors are sticky: once
+// an event fails, every following call returns the same error.
+type Emitter struct {
+	w      *bufio.Writer
+	stack  []emitFrame
+	flow   []*yaml.Node // flow collections being built
+	inDoc  bool
+	root   bool // true once the document's root node has started
+	docs   int
+	inline bool // true when the next entry continues the current line
+	err    error
+}
+
+// emitFrame tracks a block collection being written.
+type emitFrame struct {
+	mapping bool
+	indent  int    // column of the keys or sequence indicators
+	count   int    // number of keys and values, or items, started
+	start   string // written before the first entry
+	empty   string // written before the flow form of an empty collection
+}
+
+// NewEmitter returns an Emitter that writes to w.
+func NewEmitter(w io.Writer) *Emitter {
+	return &Emitter{w: bufio.NewWriter(w)}
+}
+
+var (
+	errEmitNoDocument  = errors.New("yaml: event outside of a document")
+	errEmitUnbalanced  = errors.New("yaml: end event does not match the open collection")
+	errEmitComplexKey  = errors.New("yaml: mapping keys must be single-line scalars or aliases")
+	errEmitMissing     = errors.New("yaml: mapping ended without a value for its last key")
+	errEmitIncomplete  = errors.New("yaml: document ended before its root node was complete")
+	errEmitSecondRoot  = errors.New("yaml: document already has a root node")
+	errEmitUnknownKind = errors.New("yaml: unknown event kind")
+)
+
+// Emit writes the event.
+func (e *Emitter) Emit(ev Event) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.emit(ev)
+	return e.err
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (e *Emitter) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.w.Flush()
+	return e.err
+}
+
+func (e *Emitter) emit(ev Event) error {
+	if len(e.flow) > 0 {
+		return e.emitFlow(ev)
+	}
+	switch ev.Kind {
+	case DocumentStartEvent:
+		if e.inDoc {
+			return errors.New("yaml: document started inside another document")
+		}
+		if e.docs > 0 {
+			e.w.WriteString("---\n")
+		}
+		e.inDoc, e.root = true, false
+	case DocumentEndEvent:
+		if !e.inDoc {
+			return errEmitNoDocument
+		}
+		if !e.root || len(e.stack) > 0 {
+			return errEmitIncomplete
+		}
+		e.inDoc = false
+		e.docs++
+	case ScalarEvent:
+		text, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Value: ev.Value, Tag: ev.Tag, Anchor: ev.Anchor, Style: ev.Style})
+		if err != nil {
+			return err
+		}
+		return e.node(string(text), false)
+	case AliasEvent:
+		return e.node("*"+ev.Value+"\n", true)
+	case MappingStartEvent, SequenceStartEvent:
+		if ev.Style&yaml.FlowStyle != 0 {
+			e.flow = append(e.flow, collectionNode(ev))
+			return nil
+		}
+		return e.startCollection(ev)
+	case MappingEndEvent, SequenceEndEvent:
+		return e.endCollection(ev.Kind == MappingEndEvent)
+	default:
+		return errEmitUnknownKind
+	}
+	return nil
+}
+
+// begin writes what comes before a node in the current context. It returns
+// the separator to write before the node, the indentation of the lines
+// that follow it, and whether the node is a mapping key. As in go-yaml,
+// nodes in sequences are indented to skip the "- ", and other nodes to the
+// next multiple of the indentation.
+func (e *Emitter) begin() (string, int, bool, error) {
+	if len(e.stack) == 0 {
+		if !e.inDoc {
+			return "", 0, false, errEmitNoDocument
+		}
+		if e.root {
+			return "", 0, false, errEmitSecondRoot
+		}
+		e.root = true
+		return "", defaultIndent, false, nil
+	}
+	f := &e.stack[len(e.stack)-1]
+	if f.count == 0 {
+		e.w.WriteString(f.start)
+		e.inline = f.start == " "
+	}
+	f.count++
+	if f.mapping && f.count%2 == 0 {
+		return " ", defaultIndent * ((f.indent + defaultIndent) / defaultIndent), false, nil
+	}
+	if !e.inline {
+		e.w.WriteString(strings.Repeat(" ", f.indent))
+	}
+	e.inline = false
+	if f.mapping {
+		return "", 0, true, nil
+	}
+	e.w.WriteByte('-')
+	return " ", f.indent + 2, false, nil
+}
+
+// node writes a scalar, alias or flow collection, given the text Marshal
+// produces for it.
+func (e *Emitter) node(text string, alias bool) error {
+	sep, indent, key, err := e.begin()
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if key {
+		if len(lines) > 1 {
+			return errEmitComplexKey
+		}
+		if alias {
+			// A colon straight after an alias would be read as part of
+			// the anchor's name.
+			lines[0] += " "
+		}
+		e.w.WriteString(lines[0] + ":")
+		return nil
+	}
+	e.w.WriteString(sep + lines[0] + "\n")
+	for _, l := range lines[1:] {
+		// Marshal indents block scalars by the default indentation.
+		if l = strings.TrimPrefix(l, strings.Repeat(" ", defaultIndent)); l != "" {
+			e.w.WriteString(strings.Repeat(" ", indent))
+		}
+		e.w.WriteString(l + "\n")
+	}
+	return nil
+}
+
+func (e *Emitter) startCollection(ev Event) error {
+	root := len(e.stack) == 0
+	sep, indent, key, err := e.begin()
+	if err != nil {
+		return err
+	}
+	if key {
+		return errEmitComplexKey
+	}
+	if root {
+		indent = 0
+	}
+	f := emitFrame{mapping: ev.Kind == MappingStartEvent, indent: indent, start: "\n", empty: sep}
+	if props := nodeProperties(ev); props != "" {
+		e.w.WriteString(sep + props)
+		f.empty = " "
+	} else if root {
+		f.start = ""
+	} else if sep == " " && !e.inMappingValue() {
+		// Collections in sequences start on the same line as the "-".
+		f.start = " "
+	}
+	e.stack = append(e.stack, f)
+	return nil
+}
+
+// inMappingValue reports whether the node being started is a mapping value.
+func (e *Emitter) inMappingValue() bool {
+	f := e.stack[len(e.stack)-1]
+	return f.mapping && f.count%2 == 0
+}
+
+func (e *Emitter) endCollection(mapping bool) error {
+	if len(e.stack) == 0 || e.stack[len(e.stack)-1].mapping != mapping {
+		return errEmitUnbalanced
+	}
+	f := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	if f.mapping && f.count%2 == 1 {
+		return errEmitMissing
+	}
+	if f.count == 0 {
+		if f.mapping {
+			e.w.WriteString(f.empty + "{}\n")
+		} else {
+			e.w.WriteString(f.empty + "[]\n")
+		}
+	}
+	return nil
+}
+
+// emitFlow adds the event to the flow collection being built, writing the
+// collection once it ends.
+func (e *Emitter) emitFlow(ev Event) error {
+	top := e.flow[len(e.flow)-1]
+	switch ev.Kind {
+	case ScalarEvent:
+		top.Content = append(top.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: ev.Value, Tag: ev.Tag, Anchor: ev.Anchor, Style: ev.Style})
+	case AliasEvent:
+		top.Content = append(top.Content, &yaml.Node{Kind: yaml.AliasNode, Value: ev.Value})
+	case MappingStartEvent, SequenceStartEvent:
+		n := collectionNode(ev)
+		top.Content = append(top.Content, n)
+		e.flow = append(e.flow, n)
+	case MappingEndEvent, SequenceEndEvent:
+		if (ev.Kind == MappingEndEvent) != (top.Kind == yaml.MappingNode) {
+			return errEmitUnbalanced
+		}
+		if top.Kind == yaml.MappingNode && len(top.Content)%2 == 1 {
+			return errEmitMissing
+		}
+		e.flow = e.flow[:len(e.flow)-1]
+		if len(e.flow) > 0 {
+			return nil
+		}
+		text, err := yaml.Marshal(top)
+		if err != nil {
+			return err
+		}
+		return e.node(string(text), false)
+	case DocumentStartEvent, DocumentEndEvent:
+		return errEmitIncomplete
+	default:
+		return errEmitUnknownKind
+	}
+	return nil
+}
+
+// collectionNode returns the node for a flow collection.
+func collectionNode(ev Event) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.SequenceNode, Tag: ev.Tag, Anchor: ev.Anchor, Style: yaml.FlowStyle}
+	if ev.Kind == MappingStartEvent {
+		n.Kind = yaml.MappingNode
+	}
+	return n
+}
+
+// nodeProperties returns the anchor and tag written before a collection.
+func nodeProperties(ev Event) string {
+	var props []string
+	if ev.Anchor != "" {
+		props = append(props, "&"+ev.Anchor)
+	}
+	if ev.Tag != "" {
+		props = append(props, ev.Tag)
+	}
+	return strings.Join(props, " ")
+}
@@ -0,0 +1,156 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nodeEvents returns the events that describe the node tree.
+func nodeEvents(n *yaml.Node) []Event {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		events := []Event{{Kind: DocumentStartEvent}}
+		for _, c := range n.Content {
+			events = append(events, nodeEvents(c)...)
+		}
+		return append(events, Event{Kind: DocumentEndEvent})
+	case yaml.AliasNode:
+		return []Event{{Kind: AliasEvent, Value: n.Value}}
+	case yaml.ScalarNode:
+		return []Event{{Kind: ScalarEvent, Value: n.Value, Tag: explicitTag(n), Anchor: n.Anchor, Style: n.Style}}
+	}
+	start, end := Event{Kind: SequenceStartEvent}, Event{Kind: SequenceEndEvent}
+	if n.Kind == yaml.MappingNode {
+		start, end = Event{Kind: MappingStartEvent}, Event{Kind: MappingEndEvent}
+	}
+	start.Tag, start.Anchor, start.Style = explicitTag(n), n.Anchor, n.Style
+	events := []Event{start}
+	for _, c := range n.Content {
+		events = append(events, nodeEvents(c)...)
+	}
+	return append(events, end)
+}
+
+func explicitTag(n *yaml.Node) string {
+	if n.Style&yaml.TaggedStyle != 0 {
+		return n.Tag
+	}
+	return ""
+}
+
+func TestEmitterMatchesMarshal(t *testing.T) {
+	docs := []string{
+		"a: 1\nb: [x, 'y', \"z\"]\nc: {}\nd: []\n",
+		"- [1, 2]\n- {a: 1, b: []}\n- |\n  text\n- - - deep\n    - {}\n",
+		"k: &a\n  x: 1\nj: *a\nl: !t [1]\nm: &m []\nn: !!str 12\n",
+		"- &a\n  x: 1\n- !t\n  - 1\n- &b []\n- &c [1]\n- *a\n",
+		"a: |\n  x\nb:\n- |\n  y\n- - |\n    z\n- c: |-\n    x\n  d: >\n    folded text\n",
+		"k: |2-\n   a\n  b\nl:\n  - m: |2\n       c\n",
+		"&r\na: {b: {c: [1, {d: e}]}}\n",
+		"!t\n- 1\n",
+		"- &a\n  x:\n    y: |\n      z\n- !t\n  - a: 1\n- - a:\n      b: 1\n",
+		"- a:\n    b: 1\n    c: [1, [2]]\n  d: [{e: f}]\n",
+		"[]\n",
+		"plain\n",
+		"|\n  root\n",
+		"'yes': null\n\"\": ~\n? long key\n: 1\n",
+	}
+	for _, doc := range docs {
+		var n yaml.Node
+		if err := yaml.Unmarshal([]byte(doc), &n); err != nil {
+			t.Fatalf("yaml.Unmarshal(%q) = %v", doc, err)
+		}
+		blockStyle(&n)
+		want, err := yaml.Marshal(&n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		e := NewEmitter(&b)
+		for _, ev := range nodeEvents(&n) {
+			if err := e.Emit(ev); err != nil {
+				t.Fatalf("Emit(%+v) for %q = %v", ev, doc, err)
+			}
+		}
+		if err := e.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != string(want) {
+			t.Errorf("Emitter for %q wrote\n%s\nwant\n%s", doc, b.String(), want)
+		}
+	}
+}
+
+func TestEmitterFlowAndDocuments(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEmitter(&b)
+	events := []Event{
+		{Kind: DocumentStartEvent},
+		{Kind: MappingStartEvent},
+		{Kind: ScalarEvent, Value: "ports", Anchor: "k"},
+		{Kind: SequenceStartEvent, Style: yaml.FlowStyle, Anchor: "p"},
+		{Kind: ScalarEvent, Value: "80"},
+		{Kind: MappingStartEvent, Style: yaml.FlowStyle},
+		{Kind: ScalarEvent, Value: "tls"},
+		{Kind: ScalarEvent, Value: "true", Style: yaml.DoubleQuotedStyle},
+		{Kind: MappingEndEvent},
+		{Kind: SequenceEndEvent},
+		{Kind: AliasEvent, Value: "k"},
+		{Kind: AliasEvent, Value: "p"},
+		{Kind: MappingEndEvent},
+		{Kind: DocumentEndEvent},
+		{Kind: DocumentStartEvent},
+		{Kind: ScalarEvent, Value: "second"},
+		{Kind: DocumentEndEvent},
+	}
+	for _, ev := range events {
+		if err := e.Emit(ev); err != nil {
+			t.Fatalf("Emit(%+v) = %v", ev, err)
+		}
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "&k ports: &p [80, {tls: \"true\"}]\n*k : *p\n---\nsecond\n"
+	if b.String() != want {
+		t.Errorf("Emitter wrote %q; want %q", b.String(), want)
+	}
+	var v map[string]interface{}
+	if err := yaml.NewDecoder(&b).Decode(&v); err != nil || v["ports"] == nil {
+		t.Errorf("yaml.Unmarshal() = %v, %v", v, err)
+	}
+}
+
+func TestEmitterErrors(t *testing.T) {
+	tests := []struct {
+		events []Event
+		want   error
+	}{
+		{[]Event{{Kind: ScalarEvent, Value: "x"}}, errEmitNoDocument},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: DocumentEndEvent}}, errEmitIncomplete},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: ScalarEvent}, {Kind: ScalarEvent}}, errEmitSecondRoot},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: MappingStartEvent}, {Kind: SequenceEndEvent}}, errEmitUnbalanced},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: MappingStartEvent}, {Kind: ScalarEvent, Value: "k"}, {Kind: MappingEndEvent}}, errEmitMissing},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: MappingStartEvent}, {Kind: SequenceStartEvent}}, errEmitComplexKey},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: MappingStartEvent}, {Kind: ScalarEvent, Value: "a\nb"}}, errEmitComplexKey},
+		{[]Event{{Kind: DocumentStartEvent}, {Kind: EventKind(99)}}, errEmitUnknownKind},
+	}
+	for i, test := range tests {
+		e := NewEmitter(new(bytes.Buffer))
+		var err error
+		for _, ev := range test.events {
+			if err = e.Emit(ev); err != nil {
+				break
+			}
+		}
+		if !errors.Is(err, test.want) {
+			t.Errorf("%d: Emit() = %v; want %v", i, err, test.want)
+		}
+		if err := e.Flush(); !errors.Is(err, test.want) {
+			t.Errorf("%d: Flush() = %v; want %v", i, err, test.want)
+		}
+	}
+}
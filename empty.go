@@ -0,0 +1,82 @@
+package yaml
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeepEmpty writes empty slices and maps held in fields tagged with
+// omitempty as [] and {}, instead of leaving them out like nil ones.
+// Decoding already tells the two apart, setting an empty value for [] and
+// leaving the field nil when the key is missing or null, so with this
+// option each form survives a round trip.
+func KeepEmpty() EncodeOpt {
+	return func(e *encoder) {
+		e.keepEmpty = true
+	}
+}
+
+// keepEmpty adds the empty slices and maps that omitempty left out to the
+// node tree generated for the value.
+func keepEmpty(n *yaml.Node, v reflect.Value) {
+	walkValue(v, func(path []string, v reflect.Value, f *field) bool {
+		if f == nil || !f.omitEmpty || len(path) == 0 {
+			return true
+		}
+		var kind yaml.Kind
+		switch {
+		case v.Kind() == reflect.Map:
+			kind = yaml.MappingNode
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+			kind = yaml.SequenceNode
+		default:
+			return true
+		}
+		if v.IsNil() || v.Len() > 0 {
+			return true
+		}
+		parent := n
+		for _, p := range path[:len(path)-1] {
+			if parent, _ = childNode(parent, p); parent == nil {
+				return false
+			}
+		}
+		if parent.Kind == yaml.DocumentNode && len(parent.Content) > 0 {
+			parent = parent.Content[0]
+		}
+		if parent.Kind != yaml.MappingNode {
+			return false
+		}
+		key := path[len(path)-1]
+		if c, _ := childNode(parent, key); c != nil {
+			return false
+		}
+		i := sortedKeyIndex(parent, key)
+		empty := &yaml.Node{Kind: kind, Style: yaml.FlowStyle}
+		parent.Content = append(parent.Content[:i], append([]*yaml.Node{newScalarNode(key), empty}, parent.Content[i:]...)...)
+		return false
+	})
+}
+
+// sortedKeyIndex returns the offset in the mapping at which the key belongs.
+// Struct fields reach the node tree through a map, so the keys of every
+// mapping, struct or not, are in the order yaml.v3 sorts map keys, which
+// compares numbers in keys by value. Encoding the keys lets yaml.v3 place
+// the new one.
+func sortedKeyIndex(m *yaml.Node, key string) int {
+	keys := make(map[string]bool, len(m.Content)/2+1)
+	for i := 0; i < len(m.Content); i += 2 {
+		keys[m.Content[i].Value] = true
+	}
+	keys[key] = true
+	var n yaml.Node
+	if err := n.Encode(keys); err == nil {
+		for i := 0; i < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				return i
+			}
+		}
+	}
+	return len(m.Content)
+}
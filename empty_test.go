@@ -0,0 +1,77 @@
+package yaml
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type emptyTarget struct {
+	Args   []string          `json:"args,omitempty"`
+	Env    map[string]string `json:"env,omitempty"`
+	Name   string            `json:"name"`
+	Ports  []int             `json:"ports"`
+	Volume []byte            `json:"volume,omitempty"`
+}
+
+func TestKeepEmpty(t *testing.T) {
+	tests := []struct {
+		v    emptyTarget
+		want string
+	}{
+		{emptyTarget{Name: "web"}, "name: web\nports: null\n"},
+		{emptyTarget{Args: []string{}, Env: map[string]string{}, Name: "web", Ports: []int{}, Volume: []byte{}}, "args: []\nenv: {}\nname: web\nports: []\n"},
+		{emptyTarget{Args: []string{"a"}}, "args:\n    - a\nname: \"\"\nports: null\n"},
+	}
+	for _, test := range tests {
+		y, err := MarshalWithOptions(test.v, KeepEmpty())
+		if err != nil {
+			t.Fatalf("MarshalWithOptions() = %v", err)
+		}
+		if string(y) != test.want {
+			t.Errorf("MarshalWithOptions(%+v) = %q; want %q", test.v, y, test.want)
+		}
+		var back emptyTarget
+		if err := Unmarshal(y, &back); err != nil {
+			t.Fatalf("Unmarshal(%q) = %v", y, err)
+		}
+		back.Volume = test.v.Volume
+		if !reflect.DeepEqual(back, test.v) {
+			t.Errorf("Unmarshal(%q) = %#v; want %#v", y, back, test.v)
+		}
+	}
+
+	y, _ := Marshal(emptyTarget{Args: []string{}})
+	if string(y) != "name: \"\"\nports: null\n" {
+		t.Errorf("Marshal() = %q; want empty slices left out by default", y)
+	}
+}
+
+func TestKeepEmptyKeyOrder(t *testing.T) {
+	type item struct {
+		Name string   `json:"name"`
+		Zeta string   `json:"zeta"`
+		Args []string `json:"args,omitempty"`
+		A10  string   `json:"a10"`
+		A9   []string `json:"a9,omitempty"`
+	}
+	// The empty values must land where the encoder puts the same keys when
+	// they hold data.
+	for _, v := range []item{{Args: []string{}}, {A9: []string{}}} {
+		y, err := MarshalWithOptions(v, KeepEmpty())
+		if err != nil {
+			t.Fatalf("MarshalWithOptions() = %v", err)
+		}
+		full := v
+		if v.Args != nil {
+			full.Args = []string{"a"}
+		} else {
+			full.A9 = []string{"a"}
+		}
+		want, _ := Marshal(full)
+		want = bytes.Replace(want, []byte(":\n    - a\n"), []byte(": []\n"), 1)
+		if string(y) != string(want) {
+			t.Errorf("MarshalWithOptions(%+v) = %q; want %q", v, y, want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package yaml
+
+import (
+	"bufio"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EncodeOpt is an option for encoding into YAML format.
+type EncodeOpt func(*encoder)
+
+// encoder holds the options used to convert values into YAML.
+type encoder struct {
+	redact          string
+	hooks           []encodeHook
+	bufferSize      int
+	flushOnClose    bool
+	keepEmpty       bool
+	floats          floatFormat
+	intBase         int
+	quoteTimestamps bool
+	quoteBoolKeys   bool
+	timeLayout      string
+	docs            bool
+	diffFriendly    bool
+	pathDocs        []pathDoc
+	stringSchema    *Schema
+	stringPaths     [][]pathSegment
+	nodeHooks       []nodeHook
+	jsonMarshal     func(v interface{}) ([]byte, error)
+	err             error
+}
+
+func newEncoder(opts []EncodeOpt) *encoder {
+	e := new(encoder)
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// needsNode returns true if the output must be prepared from a node tree
+// in order to apply comments or the encoding options.
+func (e *encoder) needsNode(o interface{}) bool {
+	if e.redact != "" || len(e.hooks) > 0 || e.keepEmpty || e.floats.set() || e.intBase != 0 || e.quoteTimestamps || e.quoteBoolKeys || e.timeLayout != "" || e.docs || e.diffFriendly || e.stringSchema != nil || len(e.stringPaths) > 0 || len(e.nodeHooks) > 0 {
+		return true
+	}
+	if o == nil {
+		return false
+	}
+	plan := cachedTypePlan(reflect.TypeOf(o))
+	if plan.formats || plan.absent || plan.numbers {
+		return true
+	}
+	if !plan.comments {
+		return false
+	}
+	needs := false
+	walkValue(reflect.ValueOf(o), func(_ []string, v reflect.Value, _ *field) bool {
+		needs = needs || v.Type() == commentedValueType
+		return !needs
+	})
+	return needs
+}
+
+// apply updates the node tree generated from the object according to the
+// options and any CommentedValue wrappers.
+func (e *encoder) apply(n *yaml.Node, o interface{}) error {
+	if e.err != nil {
+		return e.err
+	}
+	v := reflect.ValueOf(o)
+	if len(e.hooks) > 0 {
+		if err := applyEncodeHooks(n, v, e.hooks); err != nil {
+			return err
+		}
+	}
+	applyComments(n, collectComments(v))
+	if e.docs {
+		applyDocs(n, v, e.pathDocs)
+	}
+	if v.IsValid() {
+		plan := cachedTypePlan(v.Type())
+		if plan.formats {
+			applyFormats(n, v)
+		}
+		if plan.absent {
+			removeAbsent(n, v)
+		}
+		if plan.numbers {
+			applyNumbers(n, v)
+		}
+	}
+	if e.keepEmpty {
+		keepEmpty(n, v)
+	}
+	if e.floats.set() {
+		applyFloats(n, v, e.floats)
+	}
+	if e.intBase != 0 {
+		applyIntegerBase(n, v, e.intBase)
+	}
+	if e.timeLayout != "" {
+		applyTimeLayout(n, v, e.timeLayout)
+	}
+	if e.quoteTimestamps {
+		quoteTimestamps(n)
+	}
+	if e.quoteBoolKeys {
+		quoteBoolKeys(n)
+	}
+	if e.redact != "" {
+		redactSecrets(n, v, e.redact)
+	}
+	if e.stringSchema != nil || len(e.stringPaths) > 0 {
+		applyStringTypes(n, e.stringSchema, e.stringPaths)
+	}
+	if e.diffFriendly {
+		applyDiffFriendly(n)
+	}
+	if len(e.nodeHooks) > 0 {
+		return applyNodeHooks(n, e.nodeHooks)
+	}
+	return nil
+}
+
+// Encoder writes YAML documents to an output stream using the same rules as
+// Marshal, separating them with "---". Output is buffered, and by default
+// flushed at the end of every document.
+type Encoder struct {
+	w     *bufio.Writer
+	opts  []EncodeOpt
+	flush bool
+	docs  int
+	buf   []byte
+}
+
+// NewEncoder returns a new encoder that writes to w, configured with the
+// options provided.
+func NewEncoder(w io.Writer, opts ...EncodeOpt) *Encoder {
+	e := newEncoder(opts)
+	return &Encoder{
+		w:     bufio.NewWriterSize(w, e.bufferSize),
+		opts:  opts,
+		flush: !e.flushOnClose,
+	}
+}
+
+// BufferSize sets the size of the Encoder's output buffer. Documents that
+// don't fit are written straight through. It has no effect on Marshal.
+func BufferSize(size int) EncodeOpt {
+	return func(e *encoder) {
+		e.bufferSize = size
+	}
+}
+
+// FlushOnClose makes the Encoder hold documents in its buffer until it is
+// full or Flush or Close are called, instead of writing every document
+// out as soon as it is encoded, which saves a write per document when
+// sending many small documents. It has no effect on Marshal.
+func FlushOnClose() EncodeOpt {
+	return func(e *encoder) {
+		e.flushOnClose = true
+	}
+}
+
+// Encode writes the YAML for the object to the stream as a new document.
+func (e *Encoder) Encode(o interface{}) error {
+	y, err := MarshalAppend(e.buf[:0], o, e.opts...)
+	if err != nil {
+		return err
+	}
+	if cap(y) <= maxPooledBuffer {
+		e.buf = y
+	}
+	if e.docs > 0 {
+		if _, err := e.w.WriteString("---\n"); err != nil {
+			return err
+		}
+	}
+	e.docs++
+	if _, err := e.w.Write(y); err != nil {
+		return err
+	}
+	if e.flush {
+		return e.w.Flush()
+	}
+	return nil
+}
+
+// Flush writes any buffered documents to the underlying writer.
+func (e *Encoder) Flush() error {
+	return e.w.Flush()
+}
+
+// Close flushes the buffered documents. The underlying writer is not
+// closed.
+func (e *Encoder) Close() error {
+	return e.w.Flush()
+}
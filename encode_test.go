@@ -0,0 +1,67 @@
+package yaml
+
+import (
+	"bytes"
+	"testing"
+)
+
+// writeCounter counts the writes made to the buffer.
+type writeCounter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *writeCounter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestEncoder(t *testing.T) {
+	docs := []interface{}{
+		map[string]int{"a": 1},
+		[]string{"x", "y"},
+		nil,
+	}
+	want := "a: 1\n---\n- x\n- \"y\"\n---\nnull\n"
+	tests := []struct {
+		name   string
+		opts   []EncodeOpt
+		writes int // before Close
+	}{
+		{"default", nil, 3},
+		{"flush on close", []EncodeOpt{FlushOnClose()}, 0},
+		{"small buffer", []EncodeOpt{FlushOnClose(), BufferSize(16)}, 1},
+	}
+	for _, test := range tests {
+		var w writeCounter
+		enc := NewEncoder(&w, test.opts...)
+		for _, d := range docs {
+			if err := enc.Encode(d); err != nil {
+				t.Fatalf("%s: Encode(%v) = %v", test.name, d, err)
+			}
+		}
+		if w.writes != test.writes {
+			t.Errorf("%s: Encode() made %d writes; want %d", test.name, w.writes, test.writes)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("%s: Close() = %v", test.name, err)
+		}
+		if w.String() != want {
+			t.Errorf("%s: Encode() wrote %q; want %q", test.name, w.String(), want)
+		}
+	}
+}
+
+func TestEncoderError(t *testing.T) {
+	var w bytes.Buffer
+	enc := NewEncoder(&w)
+	if err := enc.Encode(map[string]interface{}{"f": func() {}}); err == nil {
+		t.Errorf("Encode() = nil; want error")
+	}
+	if err := enc.Encode("ok"); err != nil {
+		t.Fatalf("Encode() = %v", err)
+	}
+	if w.String() != "ok\n" {
+		t.Errorf("Encode() wrote %q; want %q", w.String(), "ok\n")
+	}
+}
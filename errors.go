@@ -0,0 +1,432 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TypeError is returned when a YAML value cannot be stored in the Go value
+// at the path. Line and Column are zero when the position is unknown.
+type TypeError struct {
+	Path   string       // path to the value, as used by Query
+	Line   int          // line of the value in the YAML source
+	Column int          // column of the value in the YAML source
+	Value  string       // description of the value, for example "string"
+	Type   reflect.Type // type of the Go value it could not be assigned to
+
+	offset int64         // offset in the intermediate JSON
+	tokens []interface{} // path to the value
+}
+
+func (e *TypeError) Error() string {
+	msg := "cannot unmarshal " + e.Value + " into "
+	if e.Path != "" {
+		msg += "field " + e.Path + " of type " + e.Type.String()
+	} else {
+		msg += "Go value of type " + e.Type.String()
+	}
+	return positionPrefix(e.Line, e.Column) + msg
+}
+
+// UnknownFieldError is returned when unknown fields are disallowed and a key
+// in the YAML does not match any field of the target struct.
+type UnknownFieldError struct {
+	Path   string // path to the key, as used by Query
+	Field  string // the unknown key
+	Line   int
+	Column int
+
+	tokens []interface{}
+}
+
+func (e *UnknownFieldError) Error() string {
+	msg := fmt.Sprintf("unknown field %q", e.Field)
+	if e.Path != "" && e.Path != e.Field {
+		msg += " at " + e.Path
+	}
+	return positionPrefix(e.Line, e.Column) + msg
+}
+
+// DuplicateKeyError is returned when a mapping defines the same key more
+// than once.
+type DuplicateKeyError struct {
+	Path     string // path to the duplicated key, as used by Query
+	Key      string
+	Line     int
+	Column   int
+	PrevLine int // line of the first definition
+
+	tokens []interface{}
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("line %d: mapping key %q already defined at line %d", e.Line, e.Key, e.PrevLine)
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// unmarshalsJSON reports whether values of the type decode themselves from
+// JSON, so that their fields and content are not inspected.
+func unmarshalsJSON(t reflect.Type) bool {
+	pt := reflect.PtrTo(t)
+	return pt.Implements(jsonUnmarshalerType) || implementsUnmarshalerFrom(pt)
+}
+
+// positionPrefix formats the line and column for an error message when
+// they are known.
+func positionPrefix(line, column int) string {
+	if line == 0 {
+		return ""
+	}
+	return "line " + strconv.Itoa(line) + ", column " + strconv.Itoa(column) + ": "
+}
+
+// checkDuplicateKeys ensures that no mapping in the node tree defines the
+// same key twice, which go-yaml only checks when decoding into a Go value.
+func checkDuplicateKeys(n *yaml.Node) error {
+	return duplicateKeysAt(n, nil)
+}
+
+// smallMapping is the number of keys up to which duplicates are found by
+// comparing with the previous keys rather than through a map.
+const smallMapping = 16
+
+func duplicateKeysAt(n *yaml.Node, path []directKey) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := duplicateKeysAt(c, path); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		var seen map[string]*yaml.Node
+		if len(n.Content) > 2*smallMapping {
+			seen = make(map[string]*yaml.Node, len(n.Content)/2)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Kind == yaml.ScalarNode && k.Tag != "!!merge" {
+				var prev *yaml.Node
+				if seen != nil {
+					prev = seen[k.Value]
+					seen[k.Value] = k
+				} else {
+					for j := 0; j < i; j += 2 {
+						if c := n.Content[j]; c.Kind == yaml.ScalarNode && c.Tag != "!!merge" && c.Value == k.Value {
+							prev = c
+							break
+						}
+					}
+				}
+				if prev != nil {
+					tokens := keyTokens(append(path, directKey{key: k}))
+					return &DuplicateKeyError{
+						Path:     formatPath(tokens),
+						Key:      k.Value,
+						Line:     k.Line,
+						Column:   k.Column,
+						PrevLine: prev.Line,
+						tokens:   tokens,
+					}
+				}
+			}
+			if err := duplicateKeysAt(v, append(path, directKey{key: k})); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			if err := duplicateKeysAt(c, append(path, directKey{index: i})); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// convertJSONError replaces the errors returned by encoding/json with the
+// equivalent typed errors of this package.
+func convertJSONError(err error) error {
+	switch e := err.(type) {
+	case *json.UnmarshalTypeError:
+		return &TypeError{Path: e.Field, Value: e.Value, Type: e.Type, offset: e.Offset}
+	default:
+		msg := err.Error()
+		if strings.HasPrefix(msg, "json: unknown field ") {
+			if name, uerr := strconv.Unquote(strings.TrimPrefix(msg, "json: unknown field ")); uerr == nil {
+				return &UnknownFieldError{Field: name}
+			}
+		}
+	}
+	return err
+}
+
+// locateError fills in the YAML path and position of typed errors returned
+// while decoding the JSON generated from the node, so they refer to the
+// original source rather than the intermediate JSON.
+func locateError(err error, n *yaml.Node, j []byte, t reflect.Type) error {
+	if n == nil {
+		return err
+	}
+	if n.Kind != yaml.DocumentNode {
+		n = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{n}}
+	}
+	var te *TypeError
+	var ue *UnknownFieldError
+	switch {
+	case errors.As(err, &te):
+		path := jsonPathAt(j, te.offset)
+		if path == nil {
+			break
+		}
+		te.Path, te.tokens = formatPath(path), path
+		if v, _, lerr := lookupPointer(n, pointerTokens(path)); lerr == nil {
+			te.Line, te.Column = v.Line, v.Column
+		}
+	case errors.As(err, &ue):
+		if len(n.Content) == 0 {
+			break
+		}
+		if k, path := findUnknownField(n.Content[0], t, ue.Field, nil); k != nil {
+			ue.Path, ue.tokens = formatPath(path), path
+			ue.Line, ue.Column = k.Line, k.Column
+		}
+	}
+	return err
+}
+
+// jsonPathAt returns the path of the value that the decoder was reading
+// when it reached the offset in the JSON document, or nil if unknown.
+func jsonPathAt(j []byte, offset int64) []interface{} {
+	type frame struct {
+		object    bool
+		expectKey bool
+		key       string
+		index     int
+	}
+	var stack []*frame
+	current := func() []interface{} {
+		path := make([]interface{}, 0, len(stack))
+		for _, f := range stack {
+			if f.object {
+				path = append(path, f.key)
+			} else {
+				path = append(path, f.index)
+			}
+		}
+		return path
+	}
+	done := func() {
+		if len(stack) == 0 {
+			return
+		}
+		if f := stack[len(stack)-1]; f.object {
+			f.expectKey = true
+		} else {
+			f.index++
+		}
+	}
+
+	var last []interface{}
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return last
+		}
+		if len(stack) > 0 {
+			if f := stack[len(stack)-1]; f.object && f.expectKey {
+				if s, ok := tok.(string); ok {
+					f.key, f.expectKey = s, false
+					continue
+				}
+			}
+		}
+		switch tok {
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			done()
+			continue
+		}
+		if dec.InputOffset() > offset {
+			return last
+		}
+		last = current()
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, &frame{object: true, expectKey: true})
+		case json.Delim('['):
+			stack = append(stack, &frame{})
+		default:
+			done()
+		}
+	}
+}
+
+// pointerTokens converts path tokens into JSON Pointer tokens.
+func pointerTokens(path []interface{}) []string {
+	tokens := make([]string, len(path))
+	for i, p := range path {
+		switch v := p.(type) {
+		case int:
+			tokens[i] = strconv.Itoa(v)
+		case string:
+			tokens[i] = v
+		}
+	}
+	return tokens
+}
+
+// findUnknownField searches the node for the first mapping key, in document
+// order, with the name that does not correspond to a field of the Go type
+// it would be decoded into.
+func findUnknownField(n *yaml.Node, t reflect.Type, name string, path []interface{}) (*yaml.Node, []interface{}) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || unmarshalsJSON(t) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return nil, nil
+	}
+	n = resolveAlias(n)
+	switch {
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Struct:
+		plan := cachedTypePlan(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			p := append(path[:len(path):len(path)], k.Value)
+			f := plan.field(k.Value)
+			if f == nil {
+				if k.Value == name {
+					return k, p
+				}
+				continue
+			}
+			if k, p := findUnknownField(v, f.typ, name, p); k != nil {
+				return k, p
+			}
+		}
+	case n.Kind == yaml.MappingNode && t.Kind() == reflect.Map:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			p := append(path[:len(path):len(path)], n.Content[i].Value)
+			if k, p := findUnknownField(n.Content[i+1], t.Elem(), name, p); k != nil {
+				return k, p
+			}
+		}
+	case n.Kind == yaml.SequenceNode && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+		for i, c := range n.Content {
+			if k, p := findUnknownField(c, t.Elem(), name, append(path[:len(path):len(path)], i)); k != nil {
+				return k, p
+			}
+		}
+	}
+	return nil, nil
+}
+
+// removeErrorNode deletes the value responsible for a recoverable error
+// from the document so that decoding can be retried, returning false when
+// the error cannot be recovered from.
+func removeErrorNode(doc *yaml.Node, err error) bool {
+	var tokens []interface{}
+	var te *TypeError
+	var ue *UnknownFieldError
+	var de *DuplicateKeyError
+	switch {
+	case errors.As(err, &te):
+		tokens = te.tokens
+	case errors.As(err, &ue):
+		tokens = ue.tokens
+	case errors.As(err, &de):
+		tokens = de.tokens
+	}
+	if len(tokens) == 0 {
+		return false
+	}
+	parent, _, lerr := lookupPointer(doc, pointerTokens(tokens[:len(tokens)-1]))
+	if lerr != nil {
+		return false
+	}
+	parent = resolveAlias(parent)
+	switch last := tokens[len(tokens)-1].(type) {
+	case string:
+		// Remove the last definition, which is the duplicate when there
+		// is more than one.
+		for i := len(parent.Content) - 2; i >= 0 && parent.Kind == yaml.MappingNode; i -= 2 {
+			if parent.Content[i].Value == last {
+				parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+				return true
+			}
+		}
+	case int:
+		if parent.Kind == yaml.SequenceNode && last < len(parent.Content) {
+			parent.Content[last] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+			return true
+		}
+	}
+	return false
+}
+
+// multiError holds several errors reported together, printed one per line.
+// It provides Is and As so the errors can be inspected on Go versions before
+// 1.20, where errors.Is and errors.As do not follow Unwrap() []error.
+type multiError []error
+
+// joinErrors returns the non-nil errors as a multiError, or nil when there
+// are none.
+func joinErrors(errs ...error) error {
+	var m multiError
+	for _, err := range errs {
+		if err != nil {
+			m = append(m, err)
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+func (m multiError) Error() string {
+	s := make([]string, len(m))
+	for i, err := range m {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Unwrap returns the errors held.
+func (m multiError) Unwrap() []error {
+	return m
+}
+
+// Is reports whether any of the errors matches target.
+func (m multiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (m multiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
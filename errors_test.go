@@ -0,0 +1,278 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type errorsTarget struct {
+	Name string `json:"name"`
+	Spec struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+}
+
+func TestTypeError(t *testing.T) {
+	var s errorsTarget
+	err := Unmarshal([]byte("name: web\nspec:\n  replicas: three\n"), &s)
+	var te *TypeError
+	if !errors.As(err, &te) {
+		t.Fatalf("Unmarshal() = %v; want *TypeError", err)
+	}
+	if te.Path != "spec.replicas" || te.Value != "string" || te.Type != reflect.TypeOf(0) {
+		t.Errorf("TypeError = %+v; want spec.replicas string int", te)
+	}
+	if want := "line 3, column 13: cannot unmarshal string into field spec.replicas of type int"; te.Error() != want {
+		t.Errorf("TypeError.Error() = %q; want %q", te.Error(), want)
+	}
+}
+
+func TestUnknownFieldError(t *testing.T) {
+	var s errorsTarget
+	err := Unmarshal([]byte("name: web\nimage: nginx\n"), &s, DisallowUnknownFields)
+	var ue *UnknownFieldError
+	if !errors.As(err, &ue) {
+		t.Fatalf("Unmarshal() = %v; want *UnknownFieldError", err)
+	}
+	if ue.Field != "image" || ue.Line != 2 || ue.Column != 1 {
+		t.Errorf("UnknownFieldError = %+v; want image at 2:1", ue)
+	}
+}
+
+type errorsList struct {
+	Items []struct {
+		Name string `json:"name"`
+		Size int    `json:"size"`
+	} `json:"items"`
+}
+
+func TestErrorPositions(t *testing.T) {
+	y := []byte(`# list
+items:
+  - name: a
+    size: 1
+  - name: b
+    size: [2]
+  - name: c
+    colour: red
+`)
+	var l errorsList
+	err := Unmarshal(y, &l)
+	var te *TypeError
+	if !errors.As(err, &te) {
+		t.Fatalf("Unmarshal() = %v; want *TypeError", err)
+	}
+	if te.Path != "items[1].size" || te.Line != 6 || te.Column != 11 {
+		t.Errorf("TypeError = %+v; want items[1].size at 6:11", te)
+	}
+
+	d := NewDecoder(bytes.NewReader(y))
+	if err := d.Decode(&errorsList{}); !errors.As(err, &te) || te.Path != "items[1].size" {
+		t.Errorf("Decode() = %v; want *TypeError at items[1].size", err)
+	}
+
+	doc, _ := ParseDocument(y)
+	err = NodeToStruct(doc.Node().Content[0].Content[1].Content[1], &struct {
+		Size int `json:"size"`
+	}{})
+	if !errors.As(err, &te) || te.Line != 6 || te.Path != "size" {
+		t.Errorf("NodeToStruct() = %v; want *TypeError at line 6", err)
+	}
+
+	y = bytes.Replace(y, []byte("[2]"), []byte("2"), 1)
+	d = NewDecoder(bytes.NewReader(y))
+	d.KnownFields(true)
+	err = d.Decode(&errorsList{})
+	var ue *UnknownFieldError
+	if !errors.As(err, &ue) {
+		t.Fatalf("Decode() = %v; want *UnknownFieldError", err)
+	}
+	if want := `line 8, column 5: unknown field "colour" at items[2].colour`; ue.Error() != want {
+		t.Errorf("UnknownFieldError.Error() = %q; want %q", ue.Error(), want)
+	}
+}
+
+func TestJSONErrorMessages(t *testing.T) {
+	// Any option other than DisallowUnknownFields decodes through JSON.
+	useJSON := func(d *json.Decoder) *json.Decoder { return d }
+	y := []byte("name: web\nspec:\n  replicas: three\n")
+	want := "error unmarshaling JSON: while decoding JSON: line 3, column 13: cannot unmarshal string into field spec.replicas of type int"
+	if err := Unmarshal(y, &errorsTarget{}, useJSON); err == nil || err.Error() != want {
+		t.Errorf("Unmarshal() = %v; want %q", err, want)
+	}
+	doc, _ := ParseDocument(y)
+	if err := NodeToStruct(doc.Node(), &errorsTarget{}, useJSON); err == nil || err.Error() != want {
+		t.Errorf("NodeToStruct() = %v; want %q", err, want)
+	}
+	d := NewDecoder(bytes.NewReader(y), JSONOptions(useJSON))
+	if err := d.Decode(&errorsTarget{}); err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("Decode() = %v; want %q", err, want)
+	}
+
+	y = []byte("name: web\nimage: nginx\n")
+	want = `error unmarshaling JSON: while decoding JSON: line 2, column 1: unknown field "image"`
+	if err := Unmarshal(y, &errorsTarget{}, useJSON, DisallowUnknownFields); err == nil || err.Error() != want {
+		t.Errorf("Unmarshal() = %v; want %q", err, want)
+	}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	y := []byte("spec:\n  replicas: 1\n  replicas: 2\n")
+	want := &DuplicateKeyError{Path: "spec.replicas", Key: "replicas", Line: 3, Column: 3, PrevLine: 2, tokens: []interface{}{"spec", "replicas"}}
+
+	var s errorsTarget
+	for name, err := range map[string]error{
+		"Unmarshal":             Unmarshal(y, &s),
+		"UnmarshalWithOptions":  UnmarshalWithOptions(y, &s),
+		"YAMLToJSON":            func() error { _, err := YAMLToJSON(y); return err }(),
+		"YAMLToJSONWithOptions": func() error { _, err := YAMLToJSONWithOptions(y); return err }(),
+	} {
+		var de *DuplicateKeyError
+		if !errors.As(err, &de) {
+			t.Errorf("%s() = %v; want *DuplicateKeyError", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(de, want) {
+			t.Errorf("%s() = %+v; want %+v", name, de, want)
+		}
+	}
+
+}
+
+func TestCheckDuplicateKeys(t *testing.T) {
+	var large strings.Builder
+	for i := 0; i < 2*smallMapping; i++ {
+		fmt.Fprintf(&large, "  k%d: %d\n", i, i)
+	}
+	tests := []struct {
+		y    string
+		want *DuplicateKeyError
+	}{
+		{"a: 1\nb: 2\n", nil},
+		{"<<: {a: 1}\n<<: {b: 2}\n", nil},
+		{"list:\n- a: 1\n- a: 1\n  a: 2\n", &DuplicateKeyError{Path: "list[1].a", Key: "a", Line: 4, Column: 3, PrevLine: 3, tokens: []interface{}{"list", 1, "a"}}},
+		{"big:\n" + large.String() + "  k3: x\n", &DuplicateKeyError{Path: "big.k3", Key: "k3", Line: 2*smallMapping + 2, Column: 3, PrevLine: 5, tokens: []interface{}{"big", "k3"}}},
+	}
+	for _, test := range tests {
+		var n yaml.Node
+		if err := yaml.Unmarshal([]byte(test.y), &n); err != nil {
+			t.Fatalf("yaml.Unmarshal(%q) = %v", test.y, err)
+		}
+		err := checkDuplicateKeys(&n)
+		if test.want == nil {
+			if err != nil {
+				t.Errorf("checkDuplicateKeys(%q) = %v; want nil", test.y, err)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(err, test.want) {
+			t.Errorf("checkDuplicateKeys(%q) = %+v; want %+v", test.y, err, test.want)
+		}
+	}
+}
+
+func TestAllErrors(t *testing.T) {
+	y := []byte(`name: web
+name: api
+image: nginx
+spec:
+  replicas: three
+`)
+	var s errorsTarget
+	d := NewDecoder(bytes.NewReader(y), AllErrors())
+	d.KnownFields(true)
+	err := d.Decode(&s)
+	if err == nil {
+		t.Fatalf("Decode() = nil; want errors")
+	}
+
+	var de *DuplicateKeyError
+	var ue *UnknownFieldError
+	var te *TypeError
+	if !errors.As(err, &de) || de.Line != 2 {
+		t.Errorf("Decode() = %v; want *DuplicateKeyError at line 2", err)
+	}
+	if !errors.As(err, &ue) || ue.Line != 3 {
+		t.Errorf("Decode() = %v; want *UnknownFieldError at line 3", err)
+	}
+	if !errors.As(err, &te) || te.Line != 5 {
+		t.Errorf("Decode() = %v; want *TypeError at line 5", err)
+	}
+	if s.Name != "web" {
+		t.Errorf("Decode() Name = %q; want %q", s.Name, "web")
+	}
+
+	// Without the option only the first error is returned.
+	err = UnmarshalWithOptions(y, &s)
+	if errors.As(err, &te) {
+		t.Errorf("UnmarshalWithOptions() = %v; want only the first error", err)
+	}
+}
+
+// passCounter counts the passes made over the document.
+type passCounter struct {
+	Name string `json:"name"`
+	Spec struct {
+		Replicas int `json:"replicas"`
+	} `json:"spec"`
+	passes int
+}
+
+func (c *passCounter) SetYAMLPosition(line, column int) {
+	c.passes++
+}
+
+func TestAllErrorsSinglePass(t *testing.T) {
+	if !directEnabled {
+		t.Skip("direct decoding is disabled")
+	}
+	y := []byte(`name: web
+image: nginx
+port: 80
+spec:
+  replicas: three
+  paused: true
+`)
+	var s passCounter
+	d := NewDecoder(bytes.NewReader(y), AllErrors())
+	d.KnownFields(true)
+	err := d.Decode(&s)
+	if err == nil {
+		t.Fatalf("Decode() = nil; want errors")
+	}
+	want := []string{
+		`error unmarshaling JSON: while decoding JSON: line 2, column 1: unknown field "image"`,
+		`error unmarshaling JSON: while decoding JSON: line 3, column 1: unknown field "port"`,
+		`error unmarshaling JSON: while decoding JSON: line 6, column 3: unknown field "paused" at spec.paused`,
+		`error unmarshaling JSON: while decoding JSON: line 5, column 13: cannot unmarshal string into field spec.replicas of type int`,
+	}
+	if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %q; want %q", got, want)
+	}
+	if s.Name != "web" || s.passes != 1 {
+		t.Errorf("Decode() = %q after %d passes; want %q after 1", s.Name, s.passes, "web")
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	if err := joinErrors(nil, nil); err != nil {
+		t.Errorf("joinErrors(nil, nil) = %v; want nil", err)
+	}
+	te := &TypeError{Path: "a", Value: "string", Type: reflect.TypeOf(0)}
+	err := joinErrors(io.EOF, nil, te)
+	if got, want := err.Error(), io.EOF.Error()+"\n"+te.Error(); got != want {
+		t.Errorf("Error() = %q; want %q", got, want)
+	}
+	var target *TypeError
+	if !errors.Is(err, io.EOF) || !errors.As(err, &target) || target != te {
+		t.Errorf("joinErrors() = %v; want errors.Is and errors.As to find both errors", err)
+	}
+}
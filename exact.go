@@ -0,0 +1,145 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExactNumbers passes numbers decoded into string and json.Number values
+// through with the text used in the source, rather than converting them
+// into a float64 first, so monetary values such as 19.90 are never
+// rounded or reformatted. Numbers that are not valid JSON numbers, such as
+// 0x1F, are only passed through into strings.
+func ExactNumbers() DecodeOpt {
+	return func(d *Decoder) {
+		d.retag.exact = true
+	}
+}
+
+// jsonNumberText matches the numbers allowed in JSON.
+var jsonNumberText = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// exactTag provides the tag for numbers decoded into strings and
+// json.Number values, so that they keep their text.
+func exactTag(n *yaml.Node, t reflect.Type, f *field) string {
+	if !stringTarget(n, t, f) {
+		return ""
+	}
+	if t == numberType && !jsonNumberText.MatchString(n.Value) {
+		return ""
+	}
+	return "!!str"
+}
+
+// VerbatimNumbers configures YAMLToJSONWithOptions to write numbers with
+// the text used in the source, rather than converting them into a float64
+// and formatting that, so that 12345678901234567890 or 0.10 are not
+// rounded or reformatted in the JSON. Numbers that are not valid JSON
+// numbers, such as 0x1F or 1_000, are still converted.
+func VerbatimNumbers() DecodeOpt {
+	return func(d *Decoder) {
+		d.verbatim = true
+	}
+}
+
+// verbatimNumbers replaces the numbers in the value converted from the
+// node with their source text, returning the updated value.
+func verbatimNumbers(n *yaml.Node, v interface{}) interface{} {
+	n = resolveAlias(n)
+	if n == nil {
+		return v
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) > 0 {
+			return verbatimNumbers(n.Content[0], v)
+		}
+	case yaml.MappingNode:
+		if m, ok := v.(map[string]interface{}); ok {
+			verbatimMapping(n, m, make(map[string]bool, len(n.Content)/2))
+		}
+	case yaml.SequenceNode:
+		if a, ok := v.([]interface{}); ok && len(a) == len(n.Content) {
+			for i, c := range n.Content {
+				a[i] = verbatimNumbers(c, a[i])
+			}
+		}
+	case yaml.ScalarNode:
+		switch v.(type) {
+		case int, int64, uint64, float64:
+		default:
+			return v // coerced into a string
+		}
+		if tag := n.ShortTag(); (tag == "!!int" || tag == "!!float") && jsonNumberText.MatchString(n.Value) {
+			return json.Number(n.Value)
+		}
+	}
+	return v
+}
+
+// verbatimMapping replaces the numbers in the mapping's values, skipping
+// the keys already seen, as those take precedence over merged ones.
+func verbatimMapping(n *yaml.Node, m map[string]interface{}, seen map[string]bool) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := n.Content[i]
+		// Keys converted into other text, such as 0x10, are left alone.
+		if isMergeKey(k) || seen[k.Value] || !directKeyNode(k) {
+			continue
+		}
+		seen[k.Value] = true
+		if v, ok := m[k.Value]; ok {
+			m[k.Value] = verbatimNumbers(n.Content[i+1], v)
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if !isMergeKey(n.Content[i]) {
+			continue
+		}
+		sources, err := mergeSources(n.Content[i+1])
+		if err != nil {
+			continue
+		}
+		for _, src := range sources {
+			verbatimMapping(src, m, seen)
+		}
+	}
+}
+
+// KeepLeadingZeros decodes numbers written with leading zeros, such as
+// postal codes like 0123, into strings as they were written, rather than
+// reading them as octal or dropping the zeros. Marshal always quotes such
+// strings, so they are read back as strings by other parsers too.
+func KeepLeadingZeros() DecodeOpt {
+	return func(d *Decoder) {
+		d.retag.leadingZeros = true
+	}
+}
+
+// leadingZerosText matches the numbers written with leading zeros.
+var leadingZerosText = regexp.MustCompile(`^[-+]?0[0-9_]+(\.[0-9_]*)?$`)
+
+// leadingZerosTag provides the tag for numbers with leading zeros decoded
+// into strings, so that they keep their text.
+func leadingZerosTag(n *yaml.Node, t reflect.Type, f *field) string {
+	if t == numberType || !stringTarget(n, t, f) || !leadingZerosText.MatchString(n.Value) {
+		return ""
+	}
+	return "!!str"
+}
+
+// stringTarget reports whether the number is decoded into a string, or
+// json.Number, with the value coerced by the JSON conversion.
+func stringTarget(n *yaml.Node, t reflect.Type, f *field) bool {
+	switch n.ShortTag() {
+	case "!!int", "!!float":
+	default:
+		return false
+	}
+	if t.Kind() != reflect.String || f != nil && f.quoted {
+		return false
+	}
+	return t == numberType || !unmarshalsJSON(t) && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
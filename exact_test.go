@@ -0,0 +1,121 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type exactTarget struct {
+	Price  string              `json:"price"`
+	Amount json.Number         `json:"amount"`
+	Hex    json.Number         `json:"hex"`
+	Code   string              `json:"code"`
+	Total  float64             `json:"total"`
+	Quoted int                 `json:"quoted,string"`
+	Rates  map[string]string   `json:"rates"`
+	Items  []json.Number       `json:"items"`
+	Any    interface{}         `json:"any"`
+	Nested *struct{ V string } `json:"nested"`
+}
+
+func TestExactNumbers(t *testing.T) {
+	y := []byte(`price: 19.90
+amount: 1.50e3
+code: 0x1F
+total: 19.90
+quoted: "7"
+rates: {eur: 0.90}
+items: [1.10, 2]
+any: 3.10
+nested: {v: 100.0}
+`)
+	want := exactTarget{
+		Price:  "19.90",
+		Amount: "1.50e3",
+		Code:   "0x1F",
+		Total:  19.9,
+		Quoted: 7,
+		Rates:  map[string]string{"eur": "0.90"},
+		Items:  []json.Number{"1.10", "2"},
+		Any:    3.1,
+		Nested: &struct{ V string }{V: "100.0"},
+	}
+	var s exactTarget
+	if err := UnmarshalWithOptions(y, &s, ExactNumbers()); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("UnmarshalWithOptions() = %+v; want %+v", s, want)
+	}
+
+	var plain exactTarget
+	if err := UnmarshalWithOptions(y, &plain); err != nil || plain.Price != "19.9" {
+		t.Errorf("UnmarshalWithOptions() = %q, %v; want 19.9 without the option", plain.Price, err)
+	}
+
+	var hex exactTarget
+	if err := UnmarshalWithOptions([]byte("hex: 0x1F\n"), &hex, ExactNumbers()); err != nil || hex.Hex != "31" {
+		t.Errorf("UnmarshalWithOptions() = %q, %v; want 31", hex.Hex, err)
+	}
+}
+
+func TestKeepLeadingZeros(t *testing.T) {
+	var s struct {
+		Zip    string      `json:"zip"`
+		Prefix string      `json:"prefix"`
+		Ratio  string      `json:"ratio"`
+		Count  int         `json:"count"`
+		Any    interface{} `json:"any"`
+		Codes  []string    `json:"codes"`
+	}
+	y := []byte("zip: 0123\nprefix: 0044\nratio: 0.50\ncount: 010\nany: 007\ncodes: [089, 12]\n")
+	if err := UnmarshalWithOptions(y, &s, KeepLeadingZeros()); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if s.Zip != "0123" || s.Prefix != "0044" || s.Ratio != "0.5" || s.Count != 8 || s.Any != 7.0 {
+		t.Errorf("UnmarshalWithOptions() = %+v", s)
+	}
+	if !reflect.DeepEqual(s.Codes, []string{"089", "12"}) {
+		t.Errorf("UnmarshalWithOptions() codes = %q", s.Codes)
+	}
+
+	for _, v := range []string{"0123", "0044", "089", "-012", "00"} {
+		out, err := Marshal(map[string]string{"zip": v})
+		if err != nil || string(out) != "zip: \""+v+"\"\n" {
+			t.Errorf("Marshal(%q) = %q, %v; want it quoted", v, out, err)
+		}
+	}
+}
+
+func TestVerbatimNumbers(t *testing.T) {
+	y := []byte(`big: 12345678901234567890123
+price: 0.10
+exp: 1.50e3
+zero: -0
+hex: 0x1F
+under: 1_000
+str: "0.10"
+list: [1.0, 2]
+base: &base {rate: 0.90, n: 1.0}
+derived:
+  <<: *base
+  n: 2.50
+`)
+	j, err := YAMLToJSONWithOptions(y, VerbatimNumbers())
+	if err != nil {
+		t.Fatalf("YAMLToJSONWithOptions() = %v", err)
+	}
+	want := `{"base":{"n":1.0,"rate":0.90},"big":12345678901234567890123,"derived":{"n":2.50,"rate":0.90},"exp":1.50e3,"hex":31,"list":[1.0,2],"price":0.10,"str":"0.10","under":1000,"zero":-0}`
+	if string(j) != want {
+		t.Errorf("YAMLToJSONWithOptions() = %s; want %s", j, want)
+	}
+
+	j, err = YAMLToJSONWithOptions([]byte("price: 0.10\n"))
+	if err != nil {
+		t.Fatalf("YAMLToJSONWithOptions() = %v", err)
+	}
+	if want := `{"price":0.1}`; string(j) != want {
+		t.Errorf("YAMLToJSONWithOptions() = %s; want %s", j, want)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"reflect"
 	"sort"
 	"strings"
-	"sync"
 	"unicode"
 	"unicode/utf8"
 )
@@ -76,6 +75,10 @@ type field struct {
 	typ       reflect.Type
 	omitEmpty bool
 	quoted    bool
+	secret    bool
+	format    string // from the format tag, such as "date" or "date-time"
+	doc       string // from the doc tag
+	example   string // from the example tag
 }
 
 func fillField(f field) field {
@@ -163,6 +166,7 @@ func typeFields(t reflect.Type) []field {
 					continue
 				}
 				name, opts := parseTag(tag)
+				_, yamlOpts := parseTag(sf.Tag.Get("yaml"))
 				if !isValidTag(name) {
 					name = ""
 				}
@@ -189,6 +193,10 @@ func typeFields(t reflect.Type) []field {
 						typ:       ft,
 						omitEmpty: opts.Contains("omitempty"),
 						quoted:    opts.Contains("string"),
+						secret:    opts.Contains("secret") || yamlOpts.Contains("secret"),
+						format:    sf.Tag.Get("format"),
+						doc:       sf.Tag.Get("doc"),
+						example:   sf.Tag.Get("example"),
 					}))
 					if count[f.typ] > 1 {
 						// If there were multiple instances, add a second,
@@ -283,34 +291,9 @@ func dominantField(fields []field) (field, bool) {
 	return fields[0], true
 }
 
-var fieldCache struct {
-	sync.RWMutex
-	m map[reflect.Type][]field
-}
-
 // cachedTypeFields is like typeFields but uses a cache to avoid repeated work.
 func cachedTypeFields(t reflect.Type) []field {
-	fieldCache.RLock()
-	f := fieldCache.m[t]
-	fieldCache.RUnlock()
-	if f != nil {
-		return f
-	}
-
-	// Compute fields without lock.
-	// Might duplicate effort but won't hold other computations back.
-	f = typeFields(t)
-	if f == nil {
-		f = []field{}
-	}
-
-	fieldCache.Lock()
-	if fieldCache.m == nil {
-		fieldCache.m = map[reflect.Type][]field{}
-	}
-	fieldCache.m[t] = f
-	fieldCache.Unlock()
-	return f
+	return cachedTypePlan(t).fields
 }
 
 func isValidTag(s string) bool {
@@ -0,0 +1,109 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"reflect"
+)
+
+// UnmarshalFS reads the named file from the file system and decodes it
+// into the object, using the same options as the Decoder. Errors include
+// the name of the file.
+func UnmarshalFS(fsys fs.FS, name string, o interface{}, opts ...DecodeOpt) error {
+	y, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	return fileError(name, UnmarshalWithOptions(y, o, opts...))
+}
+
+// UnmarshalFile reads the file at the path and decodes it into the object,
+// using the same options as the Decoder. Errors include the path.
+func UnmarshalFile(path string, o interface{}, opts ...DecodeOpt) error {
+	y, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return fileError(path, UnmarshalWithOptions(y, o, opts...))
+}
+
+// UnmarshalAllFS reads every document in the named file from the file
+// system, appending each one to the slice that s points to.
+func UnmarshalAllFS(fsys fs.FS, name string, s interface{}, opts ...DecodeOpt) error {
+	y, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	return fileError(name, unmarshalAll(y, s, opts))
+}
+
+// UnmarshalAllFile reads every document in the file at the path, appending
+// each one to the slice that s points to.
+func UnmarshalAllFile(path string, s interface{}, opts ...DecodeOpt) error {
+	y, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return fileError(path, unmarshalAll(y, s, opts))
+}
+
+func unmarshalAll(y []byte, s interface{}, opts []DecodeOpt) error {
+	sv := reflect.ValueOf(s)
+	if sv.Kind() != reflect.Ptr || sv.IsNil() || sv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a pointer to a slice, got %T", s)
+	}
+	sv = sv.Elem()
+	d := NewDecoder(bytes.NewReader(y), opts...)
+	for {
+		v := reflect.New(sv.Type().Elem())
+		if err := d.Decode(v.Interface()); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		sv.Set(reflect.Append(sv, v.Elem()))
+	}
+}
+
+// MarshalFile encodes the object and writes it to the file at the path,
+// creating or truncating it.
+func MarshalFile(path string, o interface{}, opts ...EncodeOpt) error {
+	y, err := MarshalWithOptions(o, opts...)
+	if err != nil {
+		return fileError(path, err)
+	}
+	return os.WriteFile(path, y, 0o644)
+}
+
+// MarshalAllFile writes each element of the slice to the file at the path
+// as a separate document, creating or truncating it.
+func MarshalAllFile(path string, s interface{}, opts ...EncodeOpt) error {
+	sv := reflect.ValueOf(s)
+	if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+		return fmt.Errorf("expected a slice, got %T", s)
+	}
+	var b bytes.Buffer
+	e := NewEncoder(&b, append(opts, FlushOnClose())...)
+	for i := 0; i < sv.Len(); i++ {
+		if err := e.Encode(sv.Index(i).Interface()); err != nil {
+			return fileError(path, err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0o644)
+}
+
+// fileError adds the name of the file to the error.
+func fileError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
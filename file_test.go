@@ -0,0 +1,86 @@
+package yaml
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type fileConfig struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+func TestUnmarshalFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.yaml":  {Data: []byte("name: web\nport: 80\n")},
+		"bad.yaml":  {Data: []byte("name: web\nport: http\n")},
+		"all.yaml":  {Data: []byte("name: a\n---\nname: b\nport: 2\n")},
+		"empty.yml": {Data: nil},
+	}
+	var c fileConfig
+	if err := UnmarshalFS(fsys, "app.yaml", &c); err != nil {
+		t.Fatalf("UnmarshalFS() = %v", err)
+	}
+	if c != (fileConfig{Name: "web", Port: 80}) {
+		t.Errorf("UnmarshalFS() = %+v", c)
+	}
+
+	err := UnmarshalFS(fsys, "bad.yaml", &c)
+	var te *TypeError
+	if !errors.As(err, &te) || !strings.HasPrefix(err.Error(), "bad.yaml: ") {
+		t.Errorf("UnmarshalFS() = %v; want *TypeError prefixed with the name", err)
+	}
+	if err := UnmarshalFS(fsys, "missing.yaml", &c); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("UnmarshalFS() = %v; want fs.ErrNotExist", err)
+	}
+
+	var all []fileConfig
+	if err := UnmarshalAllFS(fsys, "all.yaml", &all); err != nil {
+		t.Fatalf("UnmarshalAllFS() = %v", err)
+	}
+	if want := []fileConfig{{Name: "a"}, {Name: "b", Port: 2}}; !reflect.DeepEqual(all, want) {
+		t.Errorf("UnmarshalAllFS() = %+v; want %+v", all, want)
+	}
+	all = nil
+	if err := UnmarshalAllFS(fsys, "empty.yml", &all); err != nil || len(all) != 0 {
+		t.Errorf("UnmarshalAllFS() = %+v, %v; want no documents", all, err)
+	}
+	if err := UnmarshalAllFS(fsys, "all.yaml", all); err == nil {
+		t.Errorf("UnmarshalAllFS() = nil; want error for a non-pointer")
+	}
+}
+
+func TestMarshalFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	if err := MarshalFile(path, fileConfig{Name: "web", Port: 80}); err != nil {
+		t.Fatalf("MarshalFile() = %v", err)
+	}
+	var c fileConfig
+	if err := UnmarshalFile(path, &c); err != nil || c != (fileConfig{Name: "web", Port: 80}) {
+		t.Errorf("UnmarshalFile() = %+v, %v", c, err)
+	}
+
+	docs := []fileConfig{{Name: "a"}, {Name: "b", Port: 2}}
+	if err := MarshalAllFile(path, docs); err != nil {
+		t.Fatalf("MarshalAllFile() = %v", err)
+	}
+	if y, _ := os.ReadFile(path); string(y) != "name: a\nport: 0\n---\nname: b\nport: 2\n" {
+		t.Errorf("MarshalAllFile() wrote %q", y)
+	}
+	var all []fileConfig
+	if err := UnmarshalAllFile(path, &all); err != nil || !reflect.DeepEqual(all, docs) {
+		t.Errorf("UnmarshalAllFile() = %+v, %v; want %+v", all, err, docs)
+	}
+
+	err := MarshalFile(path, map[string]interface{}{"f": func() {}})
+	if err == nil || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("MarshalFile() = %v; want error prefixed with the path", err)
+	}
+}
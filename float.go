@@ -0,0 +1,110 @@
+package yaml
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// floatFormat holds the options for writing floating point values. The
+// zero value gives the same output as encoding/json.
+type floatFormat struct {
+	point        bool
+	small, large float64
+	precision    int
+}
+
+// FloatDecimalPoint always writes floating point values with a decimal
+// point, so that 1.0 is not written as 1 and read back as an integer.
+func FloatDecimalPoint() EncodeOpt {
+	return func(e *encoder) {
+		e.floats.point = true
+	}
+}
+
+// FloatNotation writes floating point values whose magnitude is below
+// small, or at least large, in scientific notation, and others in fixed
+// notation. By default, the thresholds of encoding/json are used, 1e-6 and
+// 1e21.
+func FloatNotation(small, large float64) EncodeOpt {
+	return func(e *encoder) {
+		e.floats.small, e.floats.large = small, large
+	}
+}
+
+// FloatPrecision rounds floating point values to at most the number of
+// significant digits when written.
+func FloatPrecision(digits int) EncodeOpt {
+	return func(e *encoder) {
+		e.floats.precision = digits
+	}
+}
+
+// set reports whether any of the options were used.
+func (f floatFormat) set() bool {
+	return f != floatFormat{}
+}
+
+// format writes the value according to the options.
+func (f floatFormat) format(v float64, bits int) string {
+	if f.precision > 0 {
+		v, _ = strconv.ParseFloat(strconv.FormatFloat(v, 'e', f.precision-1, bits), bits)
+	}
+	small, large := 1e-6, 1e21
+	if f.small != 0 || f.large != 0 {
+		small, large = f.small, f.large
+	}
+	verb := byte('f')
+	if abs := math.Abs(v); abs != 0 && (abs < small || large > 0 && abs >= large) {
+		verb = 'e'
+	}
+	s := strconv.FormatFloat(v, verb, -1, bits)
+	if verb == 'e' {
+		// Clean up e-09 to e-9, as encoding/json does.
+		if n := len(s); n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
+		}
+	}
+	if f.point && !strings.Contains(s, ".") {
+		if i := strings.IndexByte(s, 'e'); i >= 0 {
+			s = s[:i] + ".0" + s[i:]
+		} else {
+			s += ".0"
+		}
+	}
+	return s
+}
+
+// applyFloats rewrites the floating point values in the node tree
+// generated for the value.
+func applyFloats(n *yaml.Node, v reflect.Value, f floatFormat) {
+	walkValue(v, func(path []string, v reflect.Value, fd *field) bool {
+		if fd != nil && fd.quoted {
+			return false
+		}
+		bits := 64
+		switch v.Kind() {
+		case reflect.Float32:
+			bits = 32
+		case reflect.Float64:
+		default:
+			return true
+		}
+		target := n
+		for _, p := range path {
+			if target, _ = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		if target.Kind == yaml.DocumentNode && len(target.Content) > 0 {
+			target = target.Content[0]
+		}
+		if target.Kind == yaml.ScalarNode {
+			target.Tag, target.Style, target.Value = "!!float", 0, f.format(v.Float(), bits)
+		}
+		return false
+	})
+}
@@ -0,0 +1,59 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestFloatFormat(t *testing.T) {
+	tests := []struct {
+		f    floatFormat
+		v    float64
+		bits int
+		want string
+	}{
+		{floatFormat{}, 1, 64, "1"},
+		{floatFormat{}, 1e-7, 64, "1e-7"},
+		{floatFormat{}, 1e21, 64, "1e+21"},
+		{floatFormat{point: true}, 1, 64, "1.0"},
+		{floatFormat{point: true}, -3e30, 64, "-3.0e+30"},
+		{floatFormat{point: true}, 2.5, 64, "2.5"},
+		{floatFormat{small: 1e-3, large: 1e6}, 1234567, 64, "1.234567e+06"},
+		{floatFormat{small: 1e-3, large: 1e6}, 0.0001, 64, "1e-4"},
+		{floatFormat{small: 1e-3, large: 1e6}, 0, 64, "0"},
+		{floatFormat{precision: 3}, 3.14159, 64, "3.14"},
+		{floatFormat{precision: 3, point: true}, 1000.4, 64, "1000.0"},
+		{floatFormat{}, float64(float32(0.1)), 32, "0.1"},
+	}
+	for _, test := range tests {
+		if got := test.f.format(test.v, test.bits); got != test.want {
+			t.Errorf("%+v.format(%v) = %q; want %q", test.f, test.v, got, test.want)
+		}
+	}
+}
+
+func TestMarshalFloats(t *testing.T) {
+	v := struct {
+		Ratio  float64            `json:"ratio"`
+		Small  float32            `json:"small"`
+		Count  int                `json:"count"`
+		Quoted float64            `json:"quoted,string"`
+		List   []float64          `json:"list"`
+		Any    interface{}        `json:"any"`
+		Map    map[string]float64 `json:"map"`
+	}{Ratio: 1, Small: 0.5, Count: 2, Quoted: 3, List: []float64{4, 1e-9}, Any: 5.0, Map: map[string]float64{"x": 6}}
+	y, err := MarshalWithOptions(v, FloatDecimalPoint(), FloatPrecision(4))
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := "any: 5.0\ncount: 2\nlist:\n    - 4.0\n    - 1.0e-9\nmap:\n    x: 6.0\nquoted: \"3\"\nratio: 1.0\nsmall: 0.5\n"
+	if string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+
+	var back struct {
+		Ratio interface{} `json:"ratio"`
+	}
+	if err := Unmarshal(y, &back); err != nil || back.Ratio != 1.0 {
+		t.Errorf("Unmarshal() = %#v, %v", back.Ratio, err)
+	}
+}
@@ -0,0 +1,135 @@
+package yaml
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalMinified marshals the object like Marshal but produces the most
+// compact valid YAML: a single line in flow style, without a trailing new
+// line, and with strings only quoted when required. This is useful for
+// embedding YAML in annotations, environment variables or URLs.
+func MarshalMinified(o interface{}, opts ...EncodeOpt) ([]byte, error) {
+	n, err := StructToNode(o, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if n != nil {
+		writeFlow(&b, n)
+	}
+	return b.Bytes(), nil
+}
+
+// writeFlow writes the node on a single line in flow style. Comments are
+// dropped, while anchors, aliases and explicit tags are kept.
+func writeFlow(b *bytes.Buffer, n *yaml.Node) {
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) > 0 {
+			writeFlow(b, n.Content[0])
+		}
+		return
+	}
+	if n.Kind == yaml.AliasNode {
+		b.WriteString("*" + n.Value)
+		return
+	}
+	if n.Anchor != "" {
+		b.WriteString("&" + n.Anchor + " ")
+	}
+	if n.Tag != "" && n.Style&yaml.TaggedStyle != 0 {
+		b.WriteString(n.Tag + " ")
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeFlow(b, n.Content[i])
+			b.WriteString(": ")
+			writeFlow(b, n.Content[i+1])
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeFlow(b, c)
+		}
+		b.WriteByte(']')
+	case yaml.ScalarNode:
+		tagged := n.Tag != "" && n.Style&yaml.TaggedStyle != 0
+		switch {
+		case !tagged && n.Value == "" && n.ShortTag() == "!!null":
+			b.WriteString("null")
+		case tagged || n.ShortTag() != "!!str":
+			// The tag is written or resolved from the value, so the value
+			// only needs to be read back as the same text.
+			if flowSafe(n.Value) {
+				b.WriteString(n.Value)
+			} else {
+				b.WriteString(quoteFlow(n.Value))
+			}
+		case flowPlain(n.Value):
+			b.WriteString(n.Value)
+		default:
+			b.WriteString(quoteFlow(n.Value))
+		}
+	}
+}
+
+// flowPlain returns true if the string can be written as a plain scalar in
+// flow context and still be read back as the same string, including by
+// YAML 1.1 parsers.
+func flowPlain(s string) bool {
+	if !flowSafe(s) {
+		return false
+	}
+	if ambiguousScalars[strings.ToLower(s)] || sexagesimal.MatchString(s) || legacyOctal.MatchString(s) || leadingZeros.MatchString(s) {
+		return false
+	}
+	// Finally, make sure the value is not resolved to another type.
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte("["+s+"]"), &n); err != nil || len(n.Content) == 0 {
+		return false
+	}
+	seq := n.Content[0]
+	return len(seq.Content) == 1 && seq.Content[0].ShortTag() == "!!str" && seq.Content[0].Value == s
+}
+
+// flowSafe returns true if the text can be written as a plain scalar in
+// flow context and still be read back as the same text, whatever type it
+// resolves to.
+func flowSafe(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return false
+	}
+	if strings.ContainsAny(s[:1], "?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if s[0] == '-' && (len(s) == 1 || s[1] == ' ' || strings.HasPrefix(s, "---")) {
+		return false
+	}
+	if strings.ContainsAny(s, ",[]{}") || strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || r == 0xfeff {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteFlow returns the string in double quotes, escaped so it stays on a
+// single line. The escapes produced by Go are all valid in YAML.
+func quoteFlow(s string) string {
+	return strconv.Quote(s)
+}
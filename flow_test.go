@@ -0,0 +1,63 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestMarshalMinified(t *testing.T) {
+	type spec struct {
+		Name   string            `json:"name"`
+		Ports  []int             `json:"ports"`
+		Labels map[string]string `json:"labels"`
+		Empty  []string          `json:"empty"`
+		Extra  interface{}       `json:"extra"`
+	}
+	cases := []struct {
+		in   interface{}
+		want string
+	}{
+		{
+			spec{Name: "web", Ports: []int{80, 443}, Labels: map[string]string{"app": "web", "tier": "front end"}, Empty: []string{}},
+			`{empty: [],extra: null,labels: {app: web,tier: front end},name: web,ports: [80,443]}`,
+		},
+		{"plain text", `plain text`},
+		{"", `""`},
+		{"a: b", `"a: b"`},
+		{"a,b", `"a,b"`},
+		{"x #y", `"x #y"`},
+		{"#x", `"#x"`},
+		{"-1", `"-1"`},
+		{"-x", `-x`},
+		{"- x", `"- x"`},
+		{"true", `"true"`},
+		{"yes", `"yes"`},
+		{"0123", `"0123"`},
+		{"1.5", `"1.5"`},
+		{"null", `"null"`},
+		{"line\nbreak", `"line\nbreak"`},
+		{" padded", `" padded"`},
+		{"quote\"d", `quote"d`},
+		{"http://example.com/a?b=c", `"http://example.com/a?b=c"`},
+		{"a/b=c.d", `a/b=c.d`},
+		{1.5, `1.5`},
+		{nil, `null`},
+		{[]interface{}{"a", map[string]int{"b": 1}}, `[a,{b: 1}]`},
+	}
+	for _, c := range cases {
+		got, err := MarshalMinified(c.in)
+		if err != nil {
+			t.Errorf("MarshalMinified(%#v) = %v", c.in, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("MarshalMinified(%#v) = %s; want %s", c.in, got, c.want)
+		}
+		// The output must decode to the same JSON as the regular output.
+		y, _ := Marshal(c.in)
+		want, _ := YAMLToJSON(y)
+		j, err := YAMLToJSON(got)
+		if err != nil || string(j) != string(want) {
+			t.Errorf("YAMLToJSON(%s) = %s, %v; want %s", got, j, err, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatIndent is the number of spaces used by Format for each level of
+// indentation.
+const FormatIndent = 2
+
+// Format re-emits every document in the YAML stream using consistent
+// indentation and spacing while keeping comments, key order, anchors and
+// quoting styles intact. Formatting the output again will produce the
+// same result, making this suitable for pre-commit hooks and CI checks.
+func Format(src []byte) ([]byte, error) {
+	return transformStream(src, FormatIndent, nil)
+}
+
+// transformStream decodes each document in the stream into a node tree,
+// calls fn if provided, and re-emits the documents using the indentation.
+func transformStream(src []byte, indent int, fn func(*yaml.Node) error) ([]byte, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	buf := new(bytes.Buffer)
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(indent)
+	count := 0
+	for {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("error parsing YAML: %v", err)
+		}
+		if fn != nil {
+			if err := fn(&n); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.Encode(&n); err != nil {
+			return nil, fmt.Errorf("error encoding YAML: %v", err)
+		}
+		count++
+	}
+	if count == 0 {
+		return []byte{}, nil
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("error encoding YAML: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Compact appends to dst the YAML stream with each document written on a
+// single line in flow style, as MarshalMinified writes it, with documents
+// separated by "---" lines. Comments are dropped, while anchors, aliases
+// and explicit tags are kept. On error, dst is left unchanged.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	start := dst.Len()
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for count := 0; ; count++ {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			dst.Truncate(start)
+			return fmt.Errorf("error parsing YAML: %v", err)
+		}
+		if count > 0 {
+			dst.WriteString("\n---\n")
+		}
+		writeFlow(dst, &n)
+	}
+}
+
+// Indent appends to dst the YAML stream with every collection in block
+// style, indented by the given number of spaces for each level. As with
+// Format, comments, key order, anchors and quoting styles are kept. On
+// error, dst is left unchanged.
+func Indent(dst *bytes.Buffer, src []byte, indent int) error {
+	out, err := transformStream(src, indent, func(n *yaml.Node) error {
+		blockStyle(n)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	dst.Write(out)
+	return nil
+}
+
+// blockStyle removes the flow style from the collections in the tree.
+func blockStyle(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		n.Style &^= yaml.FlowStyle
+	}
+	for i, c := range n.Content {
+		if c.Kind != yaml.ScalarNode && c.Style&yaml.FlowStyle != 0 && c.LineComment != "" {
+			// The comment after a flow collection is moved to its key, or
+			// before it, as go-yaml misplaces the line comments of block
+			// collections.
+			if n.Kind == yaml.MappingNode && i%2 == 1 && n.Content[i-1].LineComment == "" {
+				n.Content[i-1].LineComment = c.LineComment
+			} else if c.HeadComment == "" {
+				c.HeadComment = c.LineComment
+			} else {
+				c.HeadComment += "\n" + c.LineComment
+			}
+			c.LineComment = ""
+		}
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,146 @@
+package yaml
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFormat(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{"", ""},
+		{
+			"# head\nname:    web   # trailing\nspec:\n      replicas: 2\n      ports:\n      - 80\n      -   443\n",
+			"# head\nname: web # trailing\nspec:\n  replicas: 2\n  ports:\n    - 80\n    - 443\n",
+		},
+		{
+			"a: 1\n---\nb:\n    c: 'x'\n",
+			"a: 1\n---\nb:\n  c: 'x'\n",
+		},
+		{
+			"base: &b {x: 1}\nuse: *b\n",
+			"base: &b {x: 1}\nuse: *b\n",
+		},
+	} {
+		got, err := Format([]byte(tc.src))
+		if err != nil {
+			t.Errorf("Format(%q) = %v", tc.src, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("Format(%q) = %q; want %q", tc.src, got, tc.want)
+		}
+		again, err := Format(got)
+		if err != nil || string(again) != string(got) {
+			t.Errorf("Format(%q) is not idempotent: %q", got, again)
+		}
+	}
+
+	if _, err := Format([]byte("a: [1, 2")); err == nil {
+		t.Errorf("Format(invalid) = nil; want error")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	for _, tc := range []struct {
+		src  string
+		want string
+	}{
+		{"", ""},
+		{
+			"# head\nname: web # trailing\nspec:\n  ports:\n    - 80\n    - \"443\"\n  text: |\n    a\n    b\n",
+			`{name: web,spec: {ports: [80,"443"],text: "a\nb\n"}}`,
+		},
+		{"a: &x 1\n---\n- !custom b\n- *x\n", "{a: &x 1}\n---\n[!custom b,*x]"},
+	} {
+		b := bytes.NewBufferString("prefix:")
+		if err := Compact(b, []byte(tc.src)); err != nil {
+			t.Errorf("Compact(%q) = %v", tc.src, err)
+			continue
+		}
+		if got := b.String(); got != "prefix:"+tc.want {
+			t.Errorf("Compact(%q) = %q; want %q", tc.src, got, "prefix:"+tc.want)
+		}
+	}
+
+	b := bytes.NewBufferString("prefix:")
+	if err := Compact(b, []byte("a: 1\n---\nb: [1, 2\n")); err == nil || b.String() != "prefix:" {
+		t.Errorf("Compact() = %v, wrote %q; want error and no output", err, b)
+	}
+}
+
+func TestCompactTaggedScalars(t *testing.T) {
+	for _, src := range []string{
+		"a: !custom 'x, y'\n",
+		"c: !env '${HOME}: x'\n",
+		"b: !!binary |\n  R0lGODlhDAAMAIQAAP//9/X1\n  7unp5WZmZgAAAOfn515eXvPz\n",
+		"d: !!str 123\ne: !!int 7\nf: !custom\ng: !custom '#x'\n",
+		"- !custom \"multi\\nline\"\n- !!str\n",
+	} {
+		var b bytes.Buffer
+		if err := Compact(&b, []byte(src)); err != nil {
+			t.Errorf("Compact(%q) = %v", src, err)
+			continue
+		}
+		if strings.Contains(b.String(), "\n") {
+			t.Errorf("Compact(%q) = %q; want a single line", src, b.String())
+		}
+		want, got := flatScalars(t, []byte(src)), flatScalars(t, b.Bytes())
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Compact(%q) = %q, read back as %q; want %q", src, b.String(), got, want)
+		}
+	}
+}
+
+// flatScalars lists the tag and value of every scalar in the document.
+func flatScalars(t *testing.T, y []byte) []string {
+	var n yaml.Node
+	if err := yaml.Unmarshal(y, &n); err != nil {
+		t.Fatalf("yaml.Unmarshal(%q) = %v", y, err)
+	}
+	var out []string
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.ScalarNode {
+			out = append(out, n.Tag+" "+n.Value)
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(&n)
+	return out
+}
+
+func TestIndent(t *testing.T) {
+	src := "# head\nname: web\nspec: {ports: [80, 443], env: {A: '1'}} # flow\n---\n- [a, {b: c}] # list\n"
+	want := `# head
+name: web
+spec: # flow
+    ports:
+        - 80
+        - 443
+    env:
+        A: '1'
+---
+# list
+- - a
+  - b: c
+`
+	var b bytes.Buffer
+	if err := Indent(&b, []byte(src), 4); err != nil {
+		t.Fatalf("Indent() = %v", err)
+	}
+	if b.String() != want {
+		t.Errorf("Indent() =\n%s\nwant\n%s", &b, want)
+	}
+	if err := Indent(&b, []byte("a: [1"), 2); err == nil {
+		t.Errorf("Indent() = nil; want error")
+	}
+}
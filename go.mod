@@ -1,5 +1,8 @@
 module github.com/invopop/yaml
 
-go 1.14
+go 1.18
 
-require gopkg.in/yaml.v3 v3.0.0
+require (
+	golang.org/x/text v0.14.0
+	gopkg.in/yaml.v3 v3.0.0
+)
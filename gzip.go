@@ -0,0 +1,50 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// DetectGzip configures the decoder to decompress input that starts with
+// the gzip magic bytes, so that compressed bundles can be read without
+// knowing in advance whether they were compressed. Other input is read as
+// is. Concatenated gzip streams are read as one. Limits such as
+// MemoryBudget apply to the decompressed input.
+func DetectGzip() DecodeOpt {
+	return func(d *Decoder) {
+		d.gzip = true
+	}
+}
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gzipReader decompresses the source if it starts with the gzip magic
+// bytes. The check is made on the first read, so that errors in the gzip
+// header are returned by Decode.
+type gzipReader struct {
+	r   io.Reader
+	src *bufio.Reader
+}
+
+func newGzipReader(r io.Reader) *gzipReader {
+	return &gzipReader{src: bufio.NewReader(r)}
+}
+
+func (g *gzipReader) Read(p []byte) (int, error) {
+	if g.r == nil {
+		magic, err := g.src.Peek(len(gzipMagic))
+		if err != nil && len(magic) == 0 {
+			return 0, err
+		}
+		if !bytes.Equal(magic, gzipMagic) {
+			g.r = g.src
+		} else if g.r, err = gzip.NewReader(g.src); err != nil {
+			g.r = nil
+			return 0, err
+		}
+	}
+	return g.r.Read(p)
+}
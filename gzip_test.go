@@ -0,0 +1,75 @@
+package yaml
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func gzipped(t *testing.T, parts ...string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	for _, s := range parts {
+		zw := gzip.NewWriter(&b)
+		if _, err := zw.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b.Bytes()
+}
+
+func TestDetectGzip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{"plain", []byte("a: 1\n---\nb: 2\n")},
+		{"gzip", gzipped(t, "a: 1\n---\nb: 2\n")},
+		{"concatenated", gzipped(t, "a: 1\n---\n", "b: 2\n")},
+	}
+	want := []interface{}{
+		map[string]interface{}{"a": 1.0},
+		map[string]interface{}{"b": 2.0},
+	}
+	for _, test := range tests {
+		d := NewDecoder(bytes.NewReader(test.in), DetectGzip())
+		var got []interface{}
+		for {
+			var v interface{}
+			err := d.Decode(&v)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: Decode() = %v", test.name, err)
+			}
+			got = append(got, v)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Decode() = %#v; want %#v", test.name, got, want)
+		}
+	}
+}
+
+func TestDetectGzipErrors(t *testing.T) {
+	var v interface{}
+	if err := NewDecoder(bytes.NewReader(nil), DetectGzip()).Decode(&v); !errors.Is(err, io.EOF) {
+		t.Errorf("Decode(empty) = %v; want io.EOF", err)
+	}
+	if err := NewDecoder(bytes.NewReader([]byte{0x1f, 0x8b, 0}), DetectGzip()).Decode(&v); err == nil {
+		t.Errorf("Decode(truncated) = nil; want error")
+	}
+
+	z := gzipped(t, "a: 1\nb: 2\n")
+	err := NewDecoder(bytes.NewReader(z), DetectGzip(), MemoryBudget(4)).Decode(&v)
+	var be *BudgetError
+	if !errors.As(err, &be) {
+		t.Errorf("Decode() = %v; want *BudgetError", err)
+	}
+}
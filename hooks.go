@@ -0,0 +1,384 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook transforms a value, returning the value to use in its place. Hooks
+// can be used to encrypt and decrypt values, normalize them, or convert
+// between units.
+type Hook func(v interface{}) (interface{}, error)
+
+// EncodeTypeHook registers a hook that will be called with every value of
+// the given type before it is encoded. The value returned is encoded in
+// its place using the same rules as Marshal. Values nested inside types
+// with custom JSON or text marshalers are not visited.
+func EncodeTypeHook(t reflect.Type, fn Hook) EncodeOpt {
+	return func(e *encoder) {
+		e.hooks = append(e.hooks, encodeHook{typ: t, fn: fn})
+	}
+}
+
+// EncodePathHook registers a hook that will be called with every value
+// found at the path before it is encoded. Paths use the same syntax as
+// Query, including wildcards, but negative indexes are not supported.
+func EncodePathHook(path string, fn Hook) EncodeOpt {
+	return func(e *encoder) {
+		segs, err := parsePath(path)
+		if err != nil {
+			e.err = err
+			return
+		}
+		e.hooks = append(e.hooks, encodeHook{path: segs, fn: fn})
+	}
+}
+
+// NodeHook changes a node of the document, such as its style, tag or
+// comments.
+type NodeHook func(n *yaml.Node) error
+
+// EncodeNodeHook registers a hook that will be called with every node
+// found at the path once the document has been converted to YAML and the
+// other options applied, for example to choose the style of specific
+// values. Paths use the same syntax as Query, including wildcards, but
+// negative indexes are not supported.
+func EncodeNodeHook(path string, fn NodeHook) EncodeOpt {
+	return func(e *encoder) {
+		segs, err := parsePath(path)
+		if err != nil {
+			e.err = err
+			return
+		}
+		e.nodeHooks = append(e.nodeHooks, nodeHook{path: segs, fn: fn})
+	}
+}
+
+// PathStyle sets the style of the values found at the path, such as
+// yaml.DoubleQuotedStyle or yaml.LiteralStyle for strings, or
+// yaml.FlowStyle for collections. Styles that don't apply to a value, such
+// as quotes for a number, are ignored so that its type is kept.
+func PathStyle(path string, style yaml.Style) EncodeOpt {
+	return EncodeNodeHook(path, func(n *yaml.Node) error {
+		tagged := n.Style & yaml.TaggedStyle
+		switch {
+		case n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str" && style&yaml.FlowStyle == 0:
+			n.Style = style | tagged
+		case (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode) && style&^yaml.FlowStyle == 0:
+			n.Style = style | tagged
+		}
+		return nil
+	})
+}
+
+// DecodeTypeHook registers a hook that will be called with every value of
+// the given type after it has been decoded. The hook must return a value
+// assignable to the type. For the pointer passed to Decode, the value the
+// returned pointer refers to is copied into it, so it must not be nil.
+func DecodeTypeHook(t reflect.Type, fn Hook) DecodeOpt {
+	return func(d *Decoder) {
+		d.hooks.types = append(d.hooks.types, typeHook{typ: t, fn: fn})
+	}
+}
+
+// DecodeNodeHook registers a hook that will be called with every node
+// found at the path once the document has been parsed, before it is
+// validated and converted, for example to change the tags of specific
+// values. Paths use the same syntax as Query, including wildcards, but
+// negative indexes are not supported.
+func DecodeNodeHook(path string, fn NodeHook) DecodeOpt {
+	return func(d *Decoder) {
+		segs, err := parsePath(path)
+		if err != nil {
+			d.hooks.err = err
+			return
+		}
+		d.hooks.nodes = append(d.hooks.nodes, nodeHook{path: segs, fn: fn})
+	}
+}
+
+// DecodePathHook registers a hook that will be called with every value
+// found at the path before it is decoded into the target. The hook
+// receives and should return values as YAMLToJSON would produce them,
+// for example strings, numbers, or map[string]interface{}. Paths use the
+// same syntax as Query, including wildcards.
+func DecodePathHook(path string, fn Hook) DecodeOpt {
+	return func(d *Decoder) {
+		segs, err := parsePath(path)
+		if err != nil {
+			d.hooks.err = err
+			return
+		}
+		d.hooks.paths = append(d.hooks.paths, pathHook{path: segs, fn: fn})
+	}
+}
+
+type encodeHook struct {
+	typ  reflect.Type
+	path []pathSegment
+	fn   Hook
+}
+
+type typeHook struct {
+	typ reflect.Type
+	fn  Hook
+}
+
+type pathHook struct {
+	path []pathSegment
+	fn   Hook
+}
+
+// decodeHooks holds the hooks registered with a Decoder.
+type decodeHooks struct {
+	types []typeHook
+	paths []pathHook
+	nodes []nodeHook
+	err   error
+}
+
+type nodeHook struct {
+	path []pathSegment
+	fn   NodeHook
+}
+
+// applyNodeHooks runs the hooks against the nodes of the tree found at
+// their paths.
+func applyNodeHooks(n *yaml.Node, hooks []nodeHook) error {
+	if n.Kind == yaml.DocumentNode {
+		for _, c := range n.Content {
+			if err := applyNodeHooks(c, hooks); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return applyNodeHooksAt(n, hooks, nil)
+}
+
+func applyNodeHooksAt(n *yaml.Node, hooks []nodeHook, path []string) error {
+	for _, h := range hooks {
+		if matchesPath(h.path, path) {
+			if err := h.fn(n); err != nil {
+				return fmt.Errorf("hook at %s: %w", formatPath(pathTokens(path)), err)
+			}
+		}
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i].Value
+			if err := applyNodeHooksAt(n.Content[i+1], hooks, append(path[:len(path):len(path)], k)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for i, c := range n.Content {
+			if err := applyNodeHooksAt(c, hooks, append(path[:len(path):len(path)], strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyEncodeHooks runs the hooks against the value and replaces the
+// matching nodes in the tree with the results.
+func applyEncodeHooks(n *yaml.Node, v reflect.Value, hooks []encodeHook) error {
+	var err error
+	walkValue(v, func(path []string, v reflect.Value, _ *field) bool {
+		if err != nil {
+			return false
+		}
+		for _, h := range hooks {
+			if (h.typ != nil && v.Type() != h.typ) || (h.typ == nil && !matchesPath(h.path, path)) {
+				continue
+			}
+			target := n
+			for _, p := range path {
+				if target, _ = childNode(target, p); target == nil {
+					return false
+				}
+			}
+			var r interface{}
+			if r, err = h.fn(v.Interface()); err != nil {
+				err = fmt.Errorf("hook at %s: %w", formatPath(pathTokens(path)), err)
+				return false
+			}
+			var rn *yaml.Node
+			if rn, err = StructToNode(r); err != nil {
+				return false
+			}
+			replaceNode(target, rn)
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// applyPaths runs the path hooks against the JSON compatible object.
+func (h decodeHooks) applyPaths(obj interface{}) (interface{}, error) {
+	if h.err != nil {
+		return nil, h.err
+	}
+	if len(h.paths) == 0 {
+		return obj, nil
+	}
+	return h.applyPathsAt(obj, nil)
+}
+
+func (h decodeHooks) applyPathsAt(obj interface{}, path []string) (interface{}, error) {
+	for _, ph := range h.paths {
+		if matchesPath(ph.path, path) {
+			r, err := ph.fn(obj)
+			if err != nil {
+				return nil, fmt.Errorf("hook at %s: %w", formatPath(pathTokens(path)), err)
+			}
+			return r, nil
+		}
+	}
+	var err error
+	switch t := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range t {
+			if t[k], err = h.applyPathsAt(v, append(path, k)); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, v := range t {
+			if t[i], err = h.applyPathsAt(v, append(path, strconv.Itoa(i))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return obj, nil
+}
+
+// applyTypes runs the type hooks against the decoded Go value.
+func (h decodeHooks) applyTypes(v reflect.Value) error {
+	if len(h.types) == 0 {
+		return nil
+	}
+	// The value passed to Decode can't be replaced, only what it points to.
+	var serr error
+	err := h.applyTypesTo(v, func(r reflect.Value) {
+		switch {
+		case v.CanSet():
+			v.Set(r)
+		case v.Kind() == reflect.Ptr && !r.IsNil():
+			v.Elem().Set(r.Elem())
+		default:
+			serr = fmt.Errorf("hook for %s returned nil for the decoded value", v.Type())
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+func (h decodeHooks) applyTypesTo(v reflect.Value, set func(reflect.Value)) error { //nolint:gocyclo
+	if !v.IsValid() {
+		return nil
+	}
+	for _, th := range h.types {
+		if v.Type() != th.typ {
+			continue
+		}
+		r, err := th.fn(v.Interface())
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(r)
+		if !rv.IsValid() {
+			rv = reflect.Zero(th.typ)
+		}
+		if !rv.Type().AssignableTo(th.typ) {
+			return fmt.Errorf("hook for %s returned %s", th.typ, rv.Type())
+		}
+		set(rv)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			e := v.Elem()
+			return h.applyTypesTo(e, func(r reflect.Value) { e.Set(r) })
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			// Interface contents aren't addressable, so work on a copy.
+			e := reflect.New(v.Elem().Type()).Elem()
+			e.Set(v.Elem())
+			if err := h.applyTypesTo(e, func(r reflect.Value) { e.Set(r) }); err != nil {
+				return err
+			}
+			if v.CanSet() {
+				v.Set(e)
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			if err := h.applyTypesTo(f, func(r reflect.Value) { f.Set(r) }); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			k := iter.Key()
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(iter.Value())
+			if err := h.applyTypesTo(e, func(r reflect.Value) { e.Set(r) }); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, e)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			e := v.Index(i)
+			if err := h.applyTypesTo(e, func(r reflect.Value) { e.Set(r) }); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesPath returns true if the concrete path matches the segments.
+func matchesPath(segs []pathSegment, path []string) bool {
+	if len(segs) != len(path) {
+		return false
+	}
+	for i, s := range segs {
+		switch {
+		case s.wildcard:
+		case s.isIndex:
+			if strconv.Itoa(s.index) != path[i] {
+				return false
+			}
+		case s.key != path[i]:
+			return false
+		}
+	}
+	return true
+}
+
+// pathTokens converts a path of strings into tokens for formatPath.
+func pathTokens(path []string) []interface{} {
+	tokens := make([]interface{}, len(path))
+	for i, p := range path {
+		tokens[i] = p
+	}
+	return tokens
+}
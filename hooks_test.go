@@ -0,0 +1,200 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type hookSecret string
+
+type hookConfig struct {
+	Name   string                `json:"name"`
+	Token  hookSecret            `json:"token"`
+	Tokens map[string]hookSecret `json:"tokens"`
+	Limits struct {
+		MemoryMB int `json:"memoryMB"`
+	} `json:"limits"`
+}
+
+func reverseHook(v interface{}) (interface{}, error) {
+	s := v.(hookSecret)
+	r := []rune(string(s))
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return hookSecret(r), nil
+}
+
+func TestEncodeHooks(t *testing.T) {
+	c := hookConfig{Name: "App", Token: "abc", Tokens: map[string]hookSecret{"x": "xyz"}}
+	c.Limits.MemoryMB = 512
+	y, err := MarshalWithOptions(c,
+		EncodeTypeHook(reflect.TypeOf(hookSecret("")), reverseHook),
+		EncodePathHook("name", func(v interface{}) (interface{}, error) {
+			return strings.ToLower(v.(string)), nil
+		}),
+		EncodePathHook("limits.*", func(v interface{}) (interface{}, error) {
+			return v.(int) * 1024, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := "limits:\n    memoryMB: 524288\nname: app\ntoken: cba\ntokens:\n    x: zyx\n"
+	if string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+
+	fail := errors.New("fail")
+	_, err = MarshalWithOptions(c, EncodePathHook("name", func(interface{}) (interface{}, error) { return nil, fail }))
+	if !errors.Is(err, fail) {
+		t.Errorf("MarshalWithOptions() = %v; want hook error", err)
+	}
+	if _, err := MarshalWithOptions(c, EncodePathHook("a[", reverseHook)); err == nil {
+		t.Errorf("MarshalWithOptions() = nil; want invalid path error")
+	}
+}
+
+func TestDecodeHooks(t *testing.T) {
+	y := []byte("name: APP\ntoken: cba\ntokens:\n  x: zyx\nlimits:\n  memoryMB: 2048\n")
+	c := hookConfig{}
+	err := UnmarshalWithOptions(y, &c,
+		DecodeTypeHook(reflect.TypeOf(hookSecret("")), reverseHook),
+		DecodePathHook("name", func(v interface{}) (interface{}, error) {
+			return strings.ToLower(v.(string)), nil
+		}),
+		DecodePathHook("limits.memoryMB", func(v interface{}) (interface{}, error) {
+			return v.(int) / 1024, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if c.Name != "app" || c.Token != "abc" || c.Tokens["x"] != "xyz" || c.Limits.MemoryMB != 2 {
+		t.Errorf("UnmarshalWithOptions() = %+v", c)
+	}
+
+	err = UnmarshalWithOptions(y, &c, DecodeTypeHook(reflect.TypeOf(hookSecret("")), func(interface{}) (interface{}, error) {
+		return 1, nil
+	}))
+	if err == nil {
+		t.Errorf("UnmarshalWithOptions() = nil; want type mismatch error")
+	}
+}
+
+func TestDecodeHooksTopLevelPointer(t *testing.T) {
+	y := []byte("name: app\n")
+	typ := reflect.TypeOf(&hookConfig{})
+	c := hookConfig{}
+	err := UnmarshalWithOptions(y, &c, DecodeTypeHook(typ, func(v interface{}) (interface{}, error) {
+		return &hookConfig{Name: v.(*hookConfig).Name + "-hooked"}, nil
+	}))
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if c.Name != "app-hooked" {
+		t.Errorf("UnmarshalWithOptions() = %+v; want the hook's value", c)
+	}
+
+	err = UnmarshalWithOptions(y, &c, DecodeTypeHook(typ, func(interface{}) (interface{}, error) {
+		return nil, nil
+	}))
+	if err == nil {
+		t.Errorf("UnmarshalWithOptions() = nil; want error for a nil replacement")
+	}
+}
+
+func TestEncodeNodeHooks(t *testing.T) {
+	type container struct {
+		Env map[string]string `json:"env"`
+	}
+	v := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{"note": "one line"},
+			"labels":      map[string]string{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"main":  container{Env: map[string]string{"PORT": "8080", "HOST": "a"}},
+			"ports": []int{80, 443},
+		},
+	}
+	y, err := MarshalWithOptions(v,
+		PathStyle("spec.*.env.*", yaml.DoubleQuotedStyle),
+		PathStyle("metadata.annotations.*", yaml.LiteralStyle),
+		PathStyle("metadata.labels", yaml.FlowStyle),
+		PathStyle("spec.ports", yaml.FlowStyle),
+		PathStyle("spec.ports[*]", yaml.SingleQuotedStyle),
+		EncodeNodeHook("spec", func(n *yaml.Node) error {
+			n.Content[0].LineComment = "# containers"
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := `metadata:
+    annotations:
+        note: |-
+            one line
+    labels: {app: web}
+spec:
+    main: # containers
+        env:
+            HOST: "a"
+            PORT: "8080"
+    ports: [80, 443]
+`
+	if string(y) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", y, want)
+	}
+
+	_, err = MarshalWithOptions(v, EncodeNodeHook("spec.ports[1]", func(n *yaml.Node) error {
+		return errors.New("failed")
+	}))
+	if err == nil || err.Error() != "hook at spec.ports.1: failed" {
+		t.Errorf("MarshalWithOptions() = %v; want hook error", err)
+	}
+	if _, err := MarshalWithOptions(v, PathStyle("a[", yaml.FlowStyle)); err == nil {
+		t.Errorf("MarshalWithOptions() = nil error; want invalid path")
+	}
+}
+
+func TestDecodeNodeHooks(t *testing.T) {
+	var v map[string]interface{}
+	y := "version: 1.10\nitems:\n  - id: 007\n  - id: 8\n"
+	err := UnmarshalWithOptions([]byte(y), &v,
+		DecodeNodeHook("version", func(n *yaml.Node) error {
+			n.Tag = "!!str"
+			return nil
+		}),
+		DecodeNodeHook("items[*].id", func(n *yaml.Node) error {
+			n.Tag, n.Style = "!!str", yaml.DoubleQuotedStyle
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	want := map[string]interface{}{
+		"version": "1.10",
+		"items":   []interface{}{map[string]interface{}{"id": "007"}, map[string]interface{}{"id": "8"}},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("UnmarshalWithOptions() = %#v; want %#v", v, want)
+	}
+
+	err = UnmarshalWithOptions([]byte(y), &v, DecodeNodeHook("items", func(n *yaml.Node) error {
+		return errors.New("failed")
+	}))
+	if err == nil || err.Error() != "hook at items: failed" {
+		t.Errorf("UnmarshalWithOptions() = %v; want hook error", err)
+	}
+	var s struct{}
+	if err := UnmarshalWithOptions([]byte(y), &s, DecodeNodeHook("a[", nil)); err == nil {
+		t.Errorf("UnmarshalWithOptions() = nil error; want invalid path")
+	}
+}
@@ -0,0 +1,259 @@
+package yaml
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedMediaType is returned by DecodeRequest when the request's
+// Content-Type is neither YAML nor JSON.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// The media types set by WriteResponse.
+const (
+	YAMLMediaType = "application/yaml"
+	JSONMediaType = "application/json"
+)
+
+// DecodeRequest decodes the body of the request into the object, for APIs
+// that accept both YAML and JSON. JSON bodies are read as YAML, of which
+// they are a subset, so that both formats are decoded with the same rules
+// and options. The JSON escapes that the YAML parser doesn't accept, "\/"
+// and surrogate pairs such as "\ud83d\ude00", are rewritten first. Requests
+// without a Content-Type are taken to be YAML, and other media types return
+// ErrUnsupportedMediaType.
+func DecodeRequest(r *http.Request, o interface{}, opts ...DecodeOpt) error {
+	body := io.Reader(r.Body)
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		t, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return err
+		}
+		switch {
+		case isJSONMediaType(t):
+			body = newJSONEscapeReader(body)
+		case !isYAMLMediaType(t):
+			return ErrUnsupportedMediaType
+		}
+	}
+	return NewDecoder(body, opts...).Decode(o)
+}
+
+// WriteResponse writes the object with the status code in the format
+// preferred by the request's Accept header, setting the Content-Type to
+// YAMLMediaType or JSONMediaType. Where the Accept header doesn't prefer
+// either, the format of the request's body is used, or YAML when that isn't
+// JSON. JSON is written by json.Marshal, so the options only apply to YAML.
+func WriteResponse(w http.ResponseWriter, r *http.Request, code int, o interface{}, opts ...EncodeOpt) error {
+	var (
+		b   []byte
+		err error
+		ct  string
+	)
+	if prefersJSON(r) {
+		b, err = json.Marshal(o)
+		ct = JSONMediaType
+	} else {
+		b, err = MarshalWithOptions(o, opts...)
+		ct = YAMLMediaType
+	}
+	if err != nil {
+		return err
+	}
+	h := w.Header()
+	h.Set("Content-Type", ct)
+	h.Add("Vary", "Accept")
+	w.WriteHeader(code)
+	_, err = w.Write(b)
+	return err
+}
+
+// prefersJSON reports whether JSON should be written in response to the
+// request.
+func prefersJSON(r *http.Request) bool {
+	var y, j acceptMatch
+	for _, a := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(a, ",") {
+			t, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if s, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(s, 64); err != nil {
+					continue
+				}
+			}
+			y.add(t, q, isYAMLMediaType)
+			j.add(t, q, isJSONMediaType)
+		}
+	}
+	if y.q != j.q {
+		return j.q > y.q
+	}
+	t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return isJSONMediaType(t)
+}
+
+// acceptMatch holds the quality of the most specific media range in an
+// Accept header that matches a format.
+type acceptMatch struct {
+	q           float64
+	specificity int // 0 when nothing matched, 3 for an exact type
+}
+
+func (m *acceptMatch) add(t string, q float64, match func(string) bool) {
+	s := 0
+	switch {
+	case match(t):
+		s = 3
+	case t == "*/*":
+		s = 1
+	case strings.HasSuffix(t, "/*"):
+		// Such as application/*, which matches both formats, and text/*,
+		// which only matches YAML.
+		sub := strings.TrimSuffix(t, "*")
+		if match(sub+"yaml") || match(sub+"json") {
+			s = 2
+		}
+	}
+	if s > m.specificity {
+		m.q, m.specificity = q, s
+	}
+}
+
+// isYAMLMediaType reports whether the media type, in lower case, is YAML.
+func isYAMLMediaType(t string) bool {
+	switch t {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return strings.HasPrefix(t, "application/") && strings.HasSuffix(t, "+yaml")
+}
+
+// isJSONMediaType reports whether the media type, in lower case, is JSON.
+func isJSONMediaType(t string) bool {
+	return t == "application/json" || strings.HasPrefix(t, "application/") && strings.HasSuffix(t, "+json")
+}
+
+// jsonEscapeReader rewrites the escapes in JSON strings that the YAML parser
+// rejects: "\/" becomes "/" and surrogate pairs become the characters they
+// encode, with unpaired surrogates replaced by U+FFFD as encoding/json does.
+// Everything else is passed through, so positions in errors are unchanged
+// on lines without such escapes.
+type jsonEscapeReader struct {
+	r        io.Reader
+	inString bool
+	esc      []byte // escape sequence read so far
+	buf      []byte
+	out      []byte // rewritten text not yet returned
+}
+
+func newJSONEscapeReader(r io.Reader) *jsonEscapeReader {
+	return &jsonEscapeReader{r: r, buf: make([]byte, 4096)}
+}
+
+func (j *jsonEscapeReader) Read(p []byte) (int, error) {
+	for len(j.out) == 0 {
+		n, err := j.r.Read(j.buf)
+		j.out = j.out[:0]
+		for _, c := range j.buf[:n] {
+			j.write(c)
+		}
+		if err != nil {
+			if len(j.out) == 0 && len(j.esc) > 0 {
+				j.out, j.esc = append(j.out, j.esc...), nil
+			}
+			if len(j.out) == 0 {
+				return 0, err
+			}
+		}
+		if n == 0 && err == nil {
+			break
+		}
+	}
+	n := copy(p, j.out)
+	j.out = j.out[n:]
+	return n, nil
+}
+
+func (j *jsonEscapeReader) write(c byte) {
+	switch {
+	case len(j.esc) > 0:
+		j.esc = append(j.esc, c)
+		j.escape()
+	case !j.inString:
+		j.inString = c == '"'
+		j.out = append(j.out, c)
+	case c == '\\':
+		j.esc = append(j.esc, c)
+	default:
+		j.inString = c != '"'
+		j.out = append(j.out, c)
+	}
+}
+
+// escape handles the escape sequence once enough of it has been read.
+func (j *jsonEscapeReader) escape() {
+	esc := j.esc
+	switch {
+	case len(esc) == 2 && esc[1] == '/':
+		j.out = append(j.out, '/')
+	case esc[1] != 'u':
+		j.out = append(j.out, esc...)
+	case len(esc) <= 6:
+		if len(esc) > 2 && !isHex(esc[len(esc)-1]) {
+			// Leave the invalid escape to the parser.
+			j.out = append(j.out, esc...)
+			break
+		}
+		if len(esc) < 6 {
+			return
+		}
+		r := hexRune(esc[2:6])
+		if !utf16.IsSurrogate(r) {
+			j.out = append(j.out, esc...)
+			break
+		}
+		if r >= 0xdc00 {
+			j.out = append(j.out, `\ufffd`...)
+			break
+		}
+		return
+	case len(esc) == 7 && esc[6] == '\\', len(esc) == 8 && esc[7] == 'u', len(esc) > 8 && len(esc) < 12 && isHex(esc[len(esc)-1]):
+		return
+	default:
+		if len(esc) == 12 && isHex(esc[11]) {
+			if r := utf16.DecodeRune(hexRune(esc[2:6]), hexRune(esc[8:])); r != utf8.RuneError {
+				j.out = utf8.AppendRune(j.out, r)
+				break
+			}
+		}
+		// The high surrogate is unpaired, so the text after it is read
+		// again.
+		j.out = append(j.out, `\ufffd`...)
+		j.esc = nil
+		for _, c := range esc[6:] {
+			j.write(c)
+		}
+		return
+	}
+	j.esc = j.esc[:0]
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// hexRune returns the value of the hexadecimal digits.
+func hexRune(b []byte) rune {
+	v, _ := strconv.ParseUint(string(b), 16, 16)
+	return rune(v)
+}
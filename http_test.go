@@ -0,0 +1,117 @@
+package yaml
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	type body struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+	tests := []struct {
+		ct   string
+		body string
+	}{
+		{"", "name: a\ncount: 2\n"},
+		{"application/yaml", "name: a\ncount: 2\n"},
+		{"text/x-yaml; charset=utf-8", "name: a\ncount: 2\n"},
+		{"application/json", "{\n\t\"name\": \"a\",\n\t\"count\": 2\n}"},
+		{"application/merge-patch+json", `{"name":"a","count":2}`},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(test.body))
+		if test.ct != "" {
+			r.Header.Set("Content-Type", test.ct)
+		}
+		var b body
+		if err := DecodeRequest(r, &b); err != nil {
+			t.Errorf("DecodeRequest(%q) = %v", test.ct, err)
+			continue
+		}
+		if b != (body{"a", 2}) {
+			t.Errorf("DecodeRequest(%q) decoded %+v", test.ct, b)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("<a/>"))
+	r.Header.Set("Content-Type", "application/xml")
+	var v interface{}
+	if err := DecodeRequest(r, &v); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("DecodeRequest(xml) = %v; want ErrUnsupportedMediaType", err)
+	}
+}
+
+func TestDecodeRequestJSONEscapes(t *testing.T) {
+	tests := []struct {
+		body string
+		want map[string]string
+	}{
+		{`{"a":"x\/y"}`, map[string]string{"a": "x/y"}},
+		{`{"a\/b":"c"}`, map[string]string{"a/b": "c"}},
+		{`{"a":"\ud83d\ude00"}`, map[string]string{"a": "\U0001F600"}},
+		{`{"a":"\ud83d"}`, map[string]string{"a": "\uFFFD"}},
+		{`{"a":"\ud83d\n\ude00"}`, map[string]string{"a": "\uFFFD\n\uFFFD"}},
+		{`{"a":"\\\/\"\u00e9"}`, map[string]string{"a": `\/"é`}},
+	}
+	for _, test := range tests {
+		// Read a byte at a time so that escapes are split between reads.
+		r := httptest.NewRequest("POST", "/", iotest.OneByteReader(strings.NewReader(test.body)))
+		r.Header.Set("Content-Type", "application/json")
+		var got map[string]string
+		if err := DecodeRequest(r, &got); err != nil {
+			t.Errorf("DecodeRequest(%s) = %v", test.body, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("DecodeRequest(%s) = %q; want %q", test.body, got, test.want)
+		}
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	tests := []struct {
+		accept string
+		ct     string
+		want   string
+	}{
+		{"", "", YAMLMediaType},
+		{"*/*", "", YAMLMediaType},
+		{"*/*", "application/json", JSONMediaType},
+		{"application/json", "", JSONMediaType},
+		{"application/yaml", "application/json", YAMLMediaType},
+		{"application/yaml;q=0.5, application/json", "", JSONMediaType},
+		{"application/json;q=0.5, application/*", "", YAMLMediaType},
+		{"text/*, application/json;q=0.9", "", YAMLMediaType},
+		{"text/html, */*;q=0.1", "application/vnd.api+json", JSONMediaType},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		if test.accept != "" {
+			r.Header.Set("Accept", test.accept)
+		}
+		if test.ct != "" {
+			r.Header.Set("Content-Type", test.ct)
+		}
+		w := httptest.NewRecorder()
+		if err := WriteResponse(w, r, http.StatusCreated, map[string]int{"a": 1}); err != nil {
+			t.Fatalf("WriteResponse() = %v", err)
+		}
+		if got := w.Header().Get("Content-Type"); got != test.want {
+			t.Errorf("Accept %q, Content-Type %q: wrote %s; want %s", test.accept, test.ct, got, test.want)
+		}
+		want := "a: 1\n"
+		if test.want == JSONMediaType {
+			want = `{"a":1}`
+		}
+		if w.Code != http.StatusCreated || w.Body.String() != want {
+			t.Errorf("Accept %q: wrote %d %q; want %d %q", test.accept, w.Code, w.Body, http.StatusCreated, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package yaml
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IncludeTag is the YAML tag used to include the contents of another
+// document in place of a scalar, for example:
+//
+//	database: !include database.yaml
+const IncludeTag = "!include"
+
+// DefaultMaxIncludeDepth is the maximum number of nested includes that
+// will be resolved unless configured otherwise with MaxIncludeDepth.
+const DefaultMaxIncludeDepth = 10
+
+// IncludeResolver loads the raw YAML content referenced by the name used
+// in an !include tag. Names are passed exactly as they appear in the
+// document, so they can refer to files, URLs, or in-memory entries.
+type IncludeResolver func(name string) ([]byte, error)
+
+// ResolveIncludes configures the Decoder to replace scalars tagged with
+// !include by the content provided by the resolver. Included documents may
+// include others, up to the maximum depth, and cycles are reported as
+// errors.
+func ResolveIncludes(resolver IncludeResolver) DecodeOpt {
+	return func(d *Decoder) {
+		d.includes = resolver
+	}
+}
+
+// MaxIncludeDepth sets how deeply nested includes may be resolved.
+func MaxIncludeDepth(depth int) DecodeOpt {
+	return func(d *Decoder) {
+		d.includeDepth = depth
+	}
+}
+
+// IncludeMap provides a resolver that looks up included documents in the
+// map, useful for embedded or generated content.
+func IncludeMap(m map[string][]byte) IncludeResolver {
+	return func(name string) ([]byte, error) {
+		y, ok := m[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: not found", name)
+		}
+		return y, nil
+	}
+}
+
+// resolveIncludes replaces all the nodes in the tree tagged with !include.
+func resolveIncludes(n *yaml.Node, resolver IncludeResolver, maxDepth int, stack []string) error {
+	if n.Kind == yaml.ScalarNode && n.Tag == IncludeTag {
+		name := n.Value
+		for _, s := range stack {
+			if s == name {
+				return fmt.Errorf("include cycle detected: %s -> %s", strings.Join(stack, " -> "), name)
+			}
+		}
+		if len(stack) >= maxDepth {
+			return fmt.Errorf("include of %s exceeds maximum depth of %d", name, maxDepth)
+		}
+		y, err := resolver(name)
+		if err != nil {
+			return fmt.Errorf("error including %s: %w", name, err)
+		}
+		doc, err := parseDocument(y)
+		if err != nil {
+			return fmt.Errorf("error parsing included %s: %v", name, err)
+		}
+		if len(doc.Content) == 0 {
+			*n = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: n.Line, Column: n.Column}
+			return nil
+		}
+		if err := resolveIncludes(doc.Content[0], resolver, maxDepth, append(stack, name)); err != nil {
+			return err
+		}
+		*n = *doc.Content[0]
+		return nil
+	}
+	for _, c := range n.Content {
+		if err := resolveIncludes(c, resolver, maxDepth, stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
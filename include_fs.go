@@ -0,0 +1,16 @@
+//go:build go1.16
+// +build go1.16
+
+package yaml
+
+import (
+	"io/fs"
+)
+
+// IncludeFS provides a resolver that reads included documents from the
+// file system, with names relative to its root.
+func IncludeFS(fsys fs.FS) IncludeResolver {
+	return func(name string) ([]byte, error) {
+		return fs.ReadFile(fsys, name)
+	}
+}
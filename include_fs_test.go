@@ -0,0 +1,23 @@
+//go:build go1.16
+// +build go1.16
+
+package yaml
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestIncludeFS(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for name, data := range includeTestFiles {
+		fsys[name] = &fstest.MapFile{Data: data}
+	}
+	var out map[string]interface{}
+	if err := UnmarshalWithOptions([]byte(includeTestDoc), &out, ResolveIncludes(IncludeFS(fsys))); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if db, _ := out["database"].(map[string]interface{}); db["host"] != "localhost" {
+		t.Errorf("UnmarshalWithOptions() = %#v", out)
+	}
+}
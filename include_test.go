@@ -0,0 +1,46 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+var includeTestFiles = map[string][]byte{
+	"db.yaml":    []byte("host: localhost\nport: !include port.yaml\n"),
+	"port.yaml":  []byte("5432\n"),
+	"empty.yaml": []byte(""),
+	"a.yaml":     []byte("next: !include b.yaml\n"),
+	"b.yaml":     []byte("next: !include a.yaml\n"),
+}
+
+const includeTestDoc = "name: app\ndatabase: !include db.yaml\nextra: !include empty.yaml\n"
+
+func TestResolveIncludes(t *testing.T) {
+	files := includeTestFiles
+	y := []byte(includeTestDoc)
+
+	var out map[string]interface{}
+	if err := UnmarshalWithOptions(y, &out, ResolveIncludes(IncludeMap(files))); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	db, _ := out["database"].(map[string]interface{})
+	if db["host"] != "localhost" || db["port"] != float64(5432) || out["extra"] != nil {
+		t.Errorf("UnmarshalWithOptions() = %#v", out)
+	}
+
+	for _, tc := range []struct {
+		y    string
+		opts []DecodeOpt
+		want string
+	}{
+		{"x: !include a.yaml", nil, "include cycle detected: a.yaml -> b.yaml -> a.yaml"},
+		{"x: !include db.yaml", []DecodeOpt{MaxIncludeDepth(1)}, "include of port.yaml exceeds maximum depth of 1"},
+		{"x: !include missing.yaml", nil, "error including missing.yaml"},
+	} {
+		opts := append([]DecodeOpt{ResolveIncludes(IncludeMap(files))}, tc.opts...)
+		err := UnmarshalWithOptions([]byte(tc.y), &out, opts...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("UnmarshalWithOptions(%q) = %v; want %q", tc.y, err, tc.want)
+		}
+	}
+}
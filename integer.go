@@ -0,0 +1,125 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IntegerMode selects how integers written with a base prefix or leading
+// zeros are read.
+type IntegerMode int
+
+const (
+	// IntegersGoYAML reads integers as go-yaml does, where 0x1F is
+	// hexadecimal and both 0o17 and the YAML 1.1 form 017 are octal.
+	IntegersGoYAML IntegerMode = iota
+	// IntegersYAML12 follows the YAML 1.2 core schema, where 0x1F is
+	// hexadecimal and 0o17 is octal, but 017 is the decimal 17 and other
+	// prefixed forms, such as the binary 0b101, are strings.
+	IntegersYAML12
+	// IntegersDecimal only reads decimal integers, so 017 is 17 and
+	// prefixed forms such as 0x1F and 0o17 are strings.
+	IntegersDecimal
+)
+
+// ResolveIntegers configures how integers written with a base prefix or
+// leading zeros are read. Decoding fails if the mode is not one of the
+// IntegerMode constants.
+func ResolveIntegers(mode IntegerMode) DecodeOpt {
+	return func(d *Decoder) {
+		switch mode {
+		case IntegersGoYAML, IntegersYAML12, IntegersDecimal:
+			d.retag.integers = mode
+		default:
+			d.err = fmt.Errorf("unsupported integer mode %d", mode)
+		}
+	}
+}
+
+// integerTag provides the tag and value for integers that the mode reads
+// differently from go-yaml.
+func integerTag(n *yaml.Node, mode IntegerMode) (string, string) {
+	if mode == IntegersGoYAML || n.Style != 0 || n.ShortTag() != "!!int" {
+		return "", ""
+	}
+	plain := strings.ReplaceAll(n.Value, "_", "")
+	sign := ""
+	if plain != "" && (plain[0] == '-' || plain[0] == '+') {
+		sign, plain = plain[:1], plain[1:]
+	}
+	switch {
+	case len(plain) > 2 && plain[0] == '0' && strings.ContainsRune("xXoObB", rune(plain[1])):
+		// YAML 1.2 only has the lowercase hexadecimal and octal prefixes.
+		if mode == IntegersDecimal || plain[1] != 'x' && plain[1] != 'o' {
+			return "!!str", n.Value
+		}
+	case len(plain) > 1 && plain[0] == '0':
+		// Read the leading zeros as a decimal number rather than octal.
+		digits := strings.TrimLeft(plain, "0")
+		if digits == "" {
+			digits = "0"
+		}
+		if _, err := strconv.ParseUint(digits, 10, 64); err != nil {
+			return "", ""
+		}
+		return "!!int", sign + digits
+	}
+	return "", ""
+}
+
+// IntegerBase writes integers in the base given, which may be 2, 8, 10 or
+// 16, using the 0b, 0o and 0x prefixes of YAML 1.2.
+func IntegerBase(base int) EncodeOpt {
+	return func(e *encoder) {
+		switch base {
+		case 2, 8, 16:
+			e.intBase = base
+		case 10:
+			e.intBase = 0
+		default:
+			e.err = fmt.Errorf("unsupported integer base %d", base)
+		}
+	}
+}
+
+// applyIntegerBase rewrites the integers in the node tree generated for
+// the value in the base.
+func applyIntegerBase(n *yaml.Node, v reflect.Value, base int) {
+	prefix := map[int]string{2: "0b", 8: "0o", 16: "0x"}[base]
+	walkValue(v, func(path []string, v reflect.Value, f *field) bool {
+		if f != nil && f.quoted {
+			return false
+		}
+		var s string
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i := v.Int()
+			if i < 0 {
+				s = "-" + prefix + strconv.FormatUint(uint64(-i), base)
+			} else {
+				s = prefix + strconv.FormatUint(uint64(i), base)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			s = prefix + strconv.FormatUint(v.Uint(), base)
+		default:
+			return true
+		}
+		target := n
+		for _, p := range path {
+			if target, _ = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		if target.Kind == yaml.DocumentNode && len(target.Content) > 0 {
+			target = target.Content[0]
+		}
+		if target.Kind == yaml.ScalarNode {
+			target.Tag, target.Style, target.Value = "!!int", 0, s
+		}
+		return false
+	})
+}
@@ -0,0 +1,84 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveIntegers(t *testing.T) {
+	y := []byte("oct: 0o17\nlegacy: 017\nhex: 0x1F\nneg: -010\nzero: 00\nquoted: '017'\nlist: [010, 0b11]\n")
+	tests := []struct {
+		mode IntegerMode
+		want map[string]interface{}
+	}{
+		{IntegersGoYAML, map[string]interface{}{
+			"oct": 15.0, "legacy": 15.0, "hex": 31.0, "neg": -8.0, "zero": 0.0, "quoted": "017", "list": []interface{}{8.0, 3.0},
+		}},
+		{IntegersYAML12, map[string]interface{}{
+			"oct": 15.0, "legacy": 17.0, "hex": 31.0, "neg": -10.0, "zero": 0.0, "quoted": "017", "list": []interface{}{10.0, "0b11"},
+		}},
+		{IntegersDecimal, map[string]interface{}{
+			"oct": "0o17", "legacy": 17.0, "hex": "0x1F", "neg": -10.0, "zero": 0.0, "quoted": "017", "list": []interface{}{10.0, "0b11"},
+		}},
+	}
+	for _, test := range tests {
+		var got map[string]interface{}
+		if err := UnmarshalWithOptions(y, &got, ResolveIntegers(test.mode)); err != nil {
+			t.Fatalf("UnmarshalWithOptions(%d) = %v", test.mode, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("UnmarshalWithOptions(%d) = %v; want %v", test.mode, got, test.want)
+		}
+	}
+
+	var s struct {
+		Mode   int    `json:"mode"`
+		Serial string `json:"serial"`
+	}
+	if err := UnmarshalWithOptions([]byte("mode: 0755\nserial: 0x1F\n"), &s, ResolveIntegers(IntegersYAML12)); err != nil || s.Mode != 755 || s.Serial != "31" {
+		t.Errorf("UnmarshalWithOptions() = %+v, %v", s, err)
+	}
+
+	var v interface{}
+	if err := UnmarshalWithOptions([]byte("a: 1\n"), &v, ResolveIntegers(IntegerMode(7))); err == nil {
+		t.Errorf("UnmarshalWithOptions() = nil; want error for mode 7")
+	}
+	if _, err := YAMLToJSONWithOptions([]byte("a: 1\n"), ResolveIntegers(-1)); err == nil {
+		t.Errorf("YAMLToJSONWithOptions() = nil; want error for mode -1")
+	}
+}
+
+func TestIntegerBase(t *testing.T) {
+	v := struct {
+		Mode  uint32 `json:"mode"`
+		Delta int    `json:"delta"`
+		Name  string `json:"name"`
+		Size  int    `json:"size,string"`
+	}{Mode: 0o755, Delta: -31, Name: "0x1", Size: 8}
+	tests := []struct {
+		base int
+		want string
+	}{
+		{8, "delta: -0o37\nmode: 0o755\nname: \"0x1\"\nsize: \"8\"\n"},
+		{16, "delta: -0x1f\nmode: 0x1ed\nname: \"0x1\"\nsize: \"8\"\n"},
+		{2, "delta: -0b11111\nmode: 0b111101101\nname: \"0x1\"\nsize: \"8\"\n"},
+		{10, "delta: -31\nmode: 493\nname: \"0x1\"\nsize: \"8\"\n"},
+	}
+	for _, test := range tests {
+		y, err := MarshalWithOptions(v, IntegerBase(test.base))
+		if err != nil {
+			t.Fatalf("MarshalWithOptions(%d) = %v", test.base, err)
+		}
+		if string(y) != test.want {
+			t.Errorf("MarshalWithOptions(%d) = %q; want %q", test.base, y, test.want)
+		}
+		back := v
+		back.Mode, back.Delta = 0, 0
+		if err := Unmarshal(y, &back); err != nil || back != v {
+			t.Errorf("Unmarshal(%q) = %+v, %v; want %+v", y, back, err, v)
+		}
+	}
+	if _, err := MarshalWithOptions(v, IntegerBase(3)); err == nil {
+		t.Errorf("MarshalWithOptions() = nil; want error for base 3")
+	}
+}
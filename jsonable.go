@@ -0,0 +1,83 @@
+package yaml
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// ToJSONable converts the value, as produced by other YAML libraries, into
+// one that encoding/json accepts. Every map[interface{}]interface{} found
+// is replaced by a map[string]interface{}, with keys converted in the same
+// way as YAMLToJSON does, so booleans and numbers become their string form.
+// Maps and slices are copied rather than changed in place.
+func ToJSONable(v interface{}) (interface{}, error) {
+	return toJSONable(v, nil)
+}
+
+func toJSONable(v interface{}, path []interface{}) (interface{}, error) {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			ks, err := jsonableKey(k)
+			if err != nil {
+				if len(path) > 0 {
+					err = fmt.Errorf("%v at %s", err, formatPath(path))
+				}
+				return nil, err
+			}
+			if m[ks], err = toJSONable(e, append(path, ks)); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			var err error
+			if m[k], err = toJSONable(e, append(path, k)); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, e := range t {
+			var err error
+			if s[i], err = toJSONable(e, append(path, i)); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	}
+	return v, nil
+}
+
+// jsonableKey converts the map key into a string.
+func jsonableKey(k interface{}) (string, error) {
+	switch t := k.(type) {
+	case string:
+		return t, nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case encoding.TextMarshaler:
+		b, err := t.MarshalText()
+		return string(b), err
+	}
+	v := reflect.ValueOf(k)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("unsupported map key of type: %T, key: %+#v", k, k)
+}
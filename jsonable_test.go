@@ -0,0 +1,48 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToJSONable(t *testing.T) {
+	in := map[interface{}]interface{}{
+		"name": "web",
+		1:      "one",
+		true:   []interface{}{map[interface{}]interface{}{1.5: nil}},
+		uint8(2): map[string]interface{}{
+			"nested": map[interface{}]interface{}{"a": 1},
+		},
+	}
+	got, err := ToJSONable(in)
+	if err != nil {
+		t.Fatalf("ToJSONable() = %v", err)
+	}
+	want := map[string]interface{}{
+		"name": "web",
+		"1":    "one",
+		"true": []interface{}{map[string]interface{}{"1.5": nil}},
+		"2": map[string]interface{}{
+			"nested": map[string]interface{}{"a": 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToJSONable() = %#v; want %#v", got, want)
+	}
+	if _, err := json.Marshal(got); err != nil {
+		t.Errorf("json.Marshal() = %v", err)
+	}
+	if _, ok := in[uint8(2)].(map[string]interface{})["nested"].(map[interface{}]interface{}); !ok {
+		t.Errorf("ToJSONable() modified its input")
+	}
+
+	if v, err := ToJSONable("x"); v != "x" || err != nil {
+		t.Errorf("ToJSONable() = %v, %v; want the scalar unchanged", v, err)
+	}
+	_, err = ToJSONable(map[string]interface{}{"a": map[interface{}]interface{}{struct{}{}: 1}})
+	if err == nil || !strings.Contains(err.Error(), "at a") {
+		t.Errorf("ToJSONable() = %v; want unsupported key error at a", err)
+	}
+}
@@ -0,0 +1,40 @@
+package yaml
+
+import "bytes"
+
+// MarshalJSONWith configures MarshalWithOptions, StructToNode and the
+// Encoder to convert values into JSON with the function, such as the
+// Marshal function of another JSON library or one that escapes strings
+// differently, instead of encoding/json. The JSON produced is then
+// converted to YAML as usual.
+func MarshalJSONWith(fn func(v interface{}) ([]byte, error)) EncodeOpt {
+	return func(e *encoder) {
+		e.jsonMarshal = fn
+	}
+}
+
+// UnmarshalJSONWith configures the Decoder to decode the JSON converted
+// from each document into the object with the function, such as the
+// Unmarshal function of another JSON library, instead of encoding/json.
+// Documents are then always converted to JSON, rather than decoded
+// directly when the object allows it. The options for encoding/json can't
+// be applied to the function, so decoding returns an error if KnownFields
+// or JSONOptions is also used.
+func UnmarshalJSONWith(fn func(data []byte, v interface{}) error) DecodeOpt {
+	return func(d *Decoder) {
+		d.jsonUnmarshal = fn
+	}
+}
+
+// encodeJSON writes the JSON for the object to the buffer.
+func (e *encoder) encodeJSON(b *bytes.Buffer, o interface{}) error {
+	if e.jsonMarshal == nil {
+		return encodeJSON(b, o)
+	}
+	j, err := e.jsonMarshal(o)
+	if err != nil {
+		return err
+	}
+	b.Write(j)
+	return nil
+}
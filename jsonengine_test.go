@@ -0,0 +1,89 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalJSONWith(t *testing.T) {
+	noEscape := func(v interface{}) ([]byte, error) {
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(v)
+		return b.Bytes(), err
+	}
+	v := map[string]string{"query": "a < b && c > d"}
+	y, err := MarshalWithOptions(v, MarshalJSONWith(noEscape))
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	if want := "query: a < b && c > d\n"; string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+
+	n, err := StructToNode(v, MarshalJSONWith(func(interface{}) ([]byte, error) {
+		return []byte(`{"replaced":true}`), nil
+	}))
+	if err != nil {
+		t.Fatalf("StructToNode() = %v", err)
+	}
+	if len(n.Content) != 2 || n.Content[0].Value != "replaced" {
+		t.Errorf("StructToNode() = %#v; want the custom JSON", n)
+	}
+
+	_, err = MarshalWithOptions(v, MarshalJSONWith(func(interface{}) ([]byte, error) {
+		return nil, errors.New("failed")
+	}))
+	if err == nil || err.Error() != "error marshaling into JSON: failed" {
+		t.Errorf("MarshalWithOptions() = %v; want marshal error", err)
+	}
+}
+
+func TestUnmarshalJSONWith(t *testing.T) {
+	calls := 0
+	counting := func(data []byte, v interface{}) error {
+		calls++
+		return json.Unmarshal(data, v)
+	}
+	type config struct {
+		Name  string `json:"name"`
+		Ports []int  `json:"ports"`
+	}
+	var c config
+	y := "name: web\nports: [80, 443]\n"
+	if err := UnmarshalWithOptions([]byte(y), &c, UnmarshalJSONWith(counting)); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if want := (config{Name: "web", Ports: []int{80, 443}}); !reflect.DeepEqual(c, want) {
+		t.Errorf("UnmarshalWithOptions() = %#v; want %#v", c, want)
+	}
+	if calls != 1 {
+		t.Errorf("custom unmarshal called %d times; want 1", calls)
+	}
+
+	err := UnmarshalWithOptions([]byte(y), &c, UnmarshalJSONWith(func([]byte, interface{}) error {
+		return errors.New("failed")
+	}))
+	if err == nil || err.Error() != "error unmarshaling JSON: failed" {
+		t.Errorf("UnmarshalWithOptions() = %v; want unmarshal error", err)
+	}
+
+	// The encoding/json options can't be passed to the function.
+	calls = 0
+	if err := UnmarshalWithOptions([]byte(y), &c, UnmarshalJSONWith(counting), JSONOptions(UseNumber)); err == nil {
+		t.Errorf("UnmarshalWithOptions(JSONOptions) = nil; want error")
+	}
+	d := NewDecoder(strings.NewReader(y), UnmarshalJSONWith(counting))
+	d.KnownFields(true)
+	if err := d.Decode(&c); err == nil {
+		t.Errorf("Decode(KnownFields) = nil; want error")
+	}
+	if calls != 0 {
+		t.Errorf("custom unmarshal called %d times; want 0", calls)
+	}
+}
@@ -0,0 +1,112 @@
+//go:build goexperiment.jsonv2
+// +build goexperiment.jsonv2
+
+package yaml
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// encodingJSONv2 is true as encoding/json is implemented by
+// encoding/json/v2, which the direct decoder follows where the results
+// differ.
+const encodingJSONv2 = true
+
+var (
+	jsonMarshalerToType     = reflect.TypeOf((*json.MarshalerTo)(nil)).Elem()
+	jsonUnmarshalerFromType = reflect.TypeOf((*json.UnmarshalerFrom)(nil)).Elem()
+)
+
+// implementsMarshalerTo reports whether the type provides MarshalJSONTo,
+// which encoding/json uses in place of the fields of the value.
+func implementsMarshalerTo(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerToType)
+}
+
+// implementsUnmarshalerFrom reports whether the type provides
+// UnmarshalJSONFrom, which encoding/json uses in place of the fields of the
+// value.
+func implementsUnmarshalerFrom(t reflect.Type) bool {
+	return t.Implements(jsonUnmarshalerFromType)
+}
+
+// parseJSON reads the JSON into the same values that yaml.Unmarshal
+// produces for it, using jsontext instead of running the YAML parser over
+// the text. It returns false when the input is not a single valid JSON
+// value, so the YAML parser can handle it or report the error.
+func parseJSON(j []byte) (interface{}, bool) {
+	dec := jsontext.NewDecoder(bytes.NewReader(j))
+	v, err := readJSON(dec)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := dec.ReadToken(); !errors.Is(err, io.EOF) {
+		return nil, false
+	}
+	return v, true
+}
+
+func readJSON(dec *jsontext.Decoder) (interface{}, error) {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return nil, err
+	}
+	switch tok.Kind() {
+	case 'n':
+		return nil, nil
+	case 't', 'f':
+		return tok.Bool(), nil
+	case '"':
+		return tok.String(), nil
+	case '0':
+		return resolveJSONNumber(tok.String()), nil
+	case '{':
+		m := make(map[string]interface{})
+		for dec.PeekKind() != '}' {
+			k, err := dec.ReadToken()
+			if err != nil {
+				return nil, err
+			}
+			if m[k.String()], err = readJSON(dec); err != nil {
+				return nil, err
+			}
+		}
+		_, err := dec.ReadToken()
+		return m, err
+	case '[':
+		s := make([]interface{}, 0)
+		for dec.PeekKind() != ']' {
+			v, err := readJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		_, err := dec.ReadToken()
+		return s, err
+	}
+	return nil, errors.New("unexpected JSON token")
+}
+
+// resolveJSONNumber picks the same type for the number as go-yaml.
+func resolveJSONNumber(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i == int64(int(i)) {
+			return int(i)
+		}
+		return i
+	}
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
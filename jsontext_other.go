@@ -0,0 +1,28 @@
+//go:build !goexperiment.jsonv2
+// +build !goexperiment.jsonv2
+
+package yaml
+
+import "reflect"
+
+// encodingJSONv2 is false as encoding/json is not implemented by
+// encoding/json/v2.
+const encodingJSONv2 = false
+
+// implementsMarshalerTo is always false, as the interface is only defined
+// by encoding/json/v2.
+func implementsMarshalerTo(t reflect.Type) bool {
+	return false
+}
+
+// implementsUnmarshalerFrom is always false, as the interface is only
+// defined by encoding/json/v2.
+func implementsUnmarshalerFrom(t reflect.Type) bool {
+	return false
+}
+
+// parseJSON always leaves the JSON to the YAML parser when encoding/json/v2
+// is not available.
+func parseJSON(j []byte) (interface{}, bool) {
+	return nil, false
+}
@@ -0,0 +1,87 @@
+//go:build goexperiment.jsonv2
+// +build goexperiment.jsonv2
+
+package yaml
+
+import (
+	"encoding/json/jsontext"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseJSON(t *testing.T) {
+	tests := []string{
+		`null`,
+		`{}`,
+		`[]`,
+		`"yes"`,
+		`{"b": [1, -2, 1.5, 1e3, -0, 0.0], "a": {"c": true, "d": false, "e": null}}`,
+		`[9223372036854775807, 9223372036854775808, 18446744073709551616, -9223372036854775809]`,
+		`[1e400, 2.5E-3, "é\n\"", "2001-12-14"]`,
+		` {"nested": [[], [{}], {"x": [""]}]} `,
+	}
+	for _, j := range tests {
+		got, ok := parseJSON([]byte(j))
+		if !ok {
+			t.Errorf("parseJSON(%q) failed", j)
+			continue
+		}
+		var want interface{}
+		if err := yaml.Unmarshal([]byte(j), &want); err != nil {
+			t.Fatalf("yaml.Unmarshal(%q) = %v", j, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("parseJSON(%q) = %#v; want %#v", j, got, want)
+		}
+	}
+
+	for _, j := range []string{``, `{"a": 1, "a": 2}`, `[1] [2]`, `a: 1`, `{"a": }`} {
+		if _, ok := parseJSON([]byte(j)); ok {
+			t.Errorf("parseJSON(%q) succeeded; want it left to the YAML parser", j)
+		}
+	}
+}
+
+// streamingID only implements the json/v2 streaming interfaces.
+type streamingID string
+
+func (id streamingID) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return enc.WriteToken(jsontext.String("id-" + string(id)))
+}
+
+func (id *streamingID) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	v, err := dec.ReadValue()
+	*id = streamingID(v)
+	return err
+}
+
+func TestStreamingMarshalers(t *testing.T) {
+	type doc struct {
+		ID   streamingID            `json:"id"`
+		Refs map[string]streamingID `json:"refs"`
+	}
+	var s doc
+	y := []byte("id: 12\nrefs: {a: [1, x]}\n")
+	if err := Unmarshal(y, &s); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := doc{ID: "12", Refs: map[string]streamingID{"a": `[1,"x"]`}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Unmarshal() = %#v; want %#v", s, want)
+	}
+	n, _ := decodeYAMLNode(yaml.NewDecoder(strings.NewReader(string(y))))
+	if canDecodeDirect(n, &s) {
+		t.Errorf("canDecodeDirect() = true; want false for UnmarshalJSONFrom")
+	}
+
+	out, err := Marshal(doc{ID: "7"})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if string(out) != "id: id-7\nrefs: null\n" {
+		t.Errorf("Marshal() = %q; want id from MarshalJSONTo", out)
+	}
+}
@@ -0,0 +1,249 @@
+package yaml
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint rule identifiers.
+const (
+	RuleSyntax          = "syntax"
+	RuleIndentation     = "indentation"
+	RuleDuplicateKeys   = "duplicate-keys"
+	RuleAmbiguousScalar = "ambiguous-scalar"
+	RuleLineLength      = "line-length"
+	RuleTabs            = "tabs"
+	RuleTrailingSpaces  = "trailing-spaces"
+)
+
+// DefaultMaxLineLength is the longest line Lint accepts by default.
+const DefaultMaxLineLength = 120
+
+// Diagnostic describes a single problem found by Lint.
+type Diagnostic struct {
+	Line    int    // 1-based line number
+	Column  int    // 1-based column number
+	Rule    string // identifier of the rule that was broken
+	Message string
+}
+
+// String provides the diagnostic in the common "line:col: message" form.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s (%s)", d.Line, d.Column, d.Message, d.Rule)
+}
+
+// LintOpt configures the rules applied by Lint.
+type LintOpt func(*linter)
+
+// MaxLineLength sets the maximum number of characters allowed per line.
+// Zero disables the check.
+func MaxLineLength(n int) LintOpt {
+	return func(l *linter) {
+		l.maxLineLength = n
+	}
+}
+
+// DisableRules prevents the rules with the given identifiers from being
+// reported.
+func DisableRules(rules ...string) LintOpt {
+	return func(l *linter) {
+		for _, r := range rules {
+			l.disabled[r] = true
+		}
+	}
+}
+
+type linter struct {
+	maxLineLength int
+	disabled      map[string]bool
+	templates     bool
+	diags         []Diagnostic
+	steps         map[string]int
+}
+
+var syntaxErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// ambiguousScalars are the plain scalars that YAML 1.1 parsers resolve as
+// booleans, while YAML 1.2 treats them as strings.
+var ambiguousScalars = map[string]bool{
+	"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true,
+}
+
+var (
+	sexagesimal  = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+	legacyOctal  = regexp.MustCompile(`^[-+]?0[0-7_]+$`)
+	leadingZeros = regexp.MustCompile(`^0[0-9]+$`)
+)
+
+// Lint checks the YAML stream for problems and returns the diagnostics
+// found, ordered by position. Problems include syntax errors, inconsistent
+// indentation, duplicate keys, scalars that are interpreted differently by
+// YAML 1.1 parsers, long lines, tabs, and trailing spaces.
+func Lint(y []byte, opts ...LintOpt) []Diagnostic {
+	l := &linter{
+		maxLineLength: DefaultMaxLineLength,
+		disabled:      make(map[string]bool),
+		steps:         make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.lintLines(y)
+	if l.templates {
+		y = maskTemplates(y).masked
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(y))
+	for {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			if !errors.Is(err, io.EOF) {
+				l.syntaxError(err)
+			}
+			break
+		}
+		l.lintNode(&n, nil)
+	}
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].Line != l.diags[j].Line {
+			return l.diags[i].Line < l.diags[j].Line
+		}
+		return l.diags[i].Column < l.diags[j].Column
+	})
+	return l.diags
+}
+
+func (l *linter) report(line, col int, rule, format string, args ...interface{}) {
+	if l.disabled[rule] {
+		return
+	}
+	l.diags = append(l.diags, Diagnostic{
+		Line:    line,
+		Column:  col,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) syntaxError(err error) {
+	msg := strings.Split(err.Error(), "\n")[0]
+	line := 1
+	if m := syntaxErrorLine.FindStringSubmatch(msg); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		msg = m[2]
+	} else {
+		msg = strings.TrimPrefix(msg, "yaml: ")
+	}
+	l.report(line, 1, RuleSyntax, "%s", msg)
+}
+
+// lintLines checks the rules that apply to the raw text.
+func (l *linter) lintLines(y []byte) {
+	s := bufio.NewScanner(bytes.NewReader(y))
+	s.Buffer(nil, len(y)+1)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSuffix(s.Text(), "\r")
+		if l.maxLineLength > 0 {
+			if n := utf8.RuneCountInString(text); n > l.maxLineLength {
+				l.report(line, l.maxLineLength+1, RuleLineLength, "line too long (%d > %d characters)", n, l.maxLineLength)
+			}
+		}
+		indent := len(text) - len(strings.TrimLeft(text, " \t"))
+		if i := strings.IndexByte(text[:indent], '\t'); i >= 0 {
+			l.report(line, i+1, RuleTabs, "tab character used for indentation")
+		}
+		if trimmed := strings.TrimRight(text, " \t"); len(trimmed) < len(text) {
+			l.report(line, utf8.RuneCountInString(trimmed)+1, RuleTrailingSpaces, "trailing whitespace")
+		}
+	}
+}
+
+// lintNode checks the rules that apply to the parsed node tree.
+func (l *linter) lintNode(n *yaml.Node, parent *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Kind == yaml.ScalarNode {
+				if seen[k.Value] {
+					l.report(k.Line, k.Column, RuleDuplicateKeys, "duplicate key %q", k.Value)
+				}
+				seen[k.Value] = true
+			}
+			l.checkScalar(k)
+			if n.Style&yaml.FlowStyle == 0 && v.Style&yaml.FlowStyle == 0 && v.Line > k.Line {
+				l.checkStep(v, k)
+			}
+			l.lintNode(v, k)
+		}
+	case yaml.SequenceNode, yaml.DocumentNode:
+		for _, c := range n.Content {
+			l.lintNode(c, n)
+		}
+	case yaml.ScalarNode:
+		l.checkScalar(n)
+	}
+}
+
+// checkStep ensures block collections nested under a key are indented by
+// the same amount as the rest of the document.
+func (l *linter) checkStep(v, key *yaml.Node) {
+	var kind string
+	switch v.Kind {
+	case yaml.MappingNode:
+		kind = "mapping"
+	case yaml.SequenceNode:
+		kind = "sequence"
+	default:
+		return
+	}
+	step := v.Column - key.Column
+	expected, ok := l.steps[kind]
+	if !ok {
+		l.steps[kind] = step
+		return
+	}
+	if step != expected {
+		l.report(v.Line, v.Column, RuleIndentation, "wrong indentation: expected %d spaces but found %d", expected, step)
+	}
+}
+
+// checkScalar reports plain scalars that other parsers may interpret
+// differently.
+func (l *linter) checkScalar(n *yaml.Node) {
+	if msg := ambiguousScalar(n); msg != "" {
+		l.report(n.Line, n.Column, RuleAmbiguousScalar, "%s", msg)
+	}
+}
+
+// ambiguousScalar describes how a plain scalar would be interpreted
+// differently by YAML 1.1 parsers, or returns an empty string.
+func ambiguousScalar(n *yaml.Node) string {
+	if n.Kind != yaml.ScalarNode || n.Style != 0 || n.Tag != "!!str" && n.Tag != "!!int" {
+		return ""
+	}
+	v := n.Value
+	switch {
+	case ambiguousScalars[strings.ToLower(v)]:
+		return fmt.Sprintf("%q is a boolean in YAML 1.1, quote it to keep it a string", v)
+	case sexagesimal.MatchString(v):
+		return fmt.Sprintf("%q is a base 60 number in YAML 1.1, quote it to keep it a string", v)
+	case legacyOctal.MatchString(v):
+		return fmt.Sprintf("%q is an octal number in YAML 1.1, use 0o or quotes", v)
+	case leadingZeros.MatchString(v):
+		return fmt.Sprintf("%q has leading zeros, quote it to keep it a string", v)
+	}
+	return ""
+}
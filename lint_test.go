@@ -0,0 +1,58 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	src := "name: web \n" +
+		"on: push\n" +
+		"spec:\n" +
+		"  replicas: 2\n" +
+		"  time: 1:30\n" +
+		"  mode: 0755\n" +
+		"  zip: '0123'\n" +
+		"  nested:\n" +
+		"      deep: true\n" +
+		"  name: a\n" +
+		"  name: b\n" +
+		"description: " + strings.Repeat("x", 30) + "\n"
+
+	var got []string
+	for _, d := range Lint([]byte(src), MaxLineLength(40)) {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"1:10: trailing whitespace (trailing-spaces)",
+		"2:1: \"on\" is a boolean in YAML 1.1, quote it to keep it a string (ambiguous-scalar)",
+		"5:9: \"1:30\" is a base 60 number in YAML 1.1, quote it to keep it a string (ambiguous-scalar)",
+		"6:9: \"0755\" is an octal number in YAML 1.1, use 0o or quotes (ambiguous-scalar)",
+		"9:7: wrong indentation: expected 2 spaces but found 4 (indentation)",
+		"11:3: duplicate key \"name\" (duplicate-keys)",
+		"12:41: line too long (43 > 40 characters) (line-length)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	diags := Lint([]byte(src), MaxLineLength(0), DisableRules(RuleAmbiguousScalar, RuleTrailingSpaces))
+	if len(diags) != 2 {
+		t.Errorf("Lint() with disabled rules = %v", diags)
+	}
+}
+
+func TestLintSyntax(t *testing.T) {
+	diags := Lint([]byte("a:\n\tb: 1\n"))
+	var rules []string
+	for _, d := range diags {
+		rules = append(rules, d.Rule)
+	}
+	if want := []string{RuleTabs, RuleSyntax}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("Lint() = %v; want rules %v", diags, want)
+	}
+	if len(Lint([]byte("a: 1\n---\nb: [1, 2]\n"))) != 0 {
+		t.Errorf("Lint() reported problems in a clean document")
+	}
+}
@@ -0,0 +1,77 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentKind holds the fields that identify a Kubernetes style manifest.
+type DocumentKind struct {
+	APIVersion string
+	Kind       string
+	Name       string
+}
+
+// PeekDocumentKind reads the apiVersion, kind and metadata.name of every
+// document in the stream without decoding the rest of each document, so
+// that documents can be routed to the decoder for their type. Missing
+// fields are left empty, and there is one result for every document.
+func PeekDocumentKind(y []byte) ([]DocumentKind, error) {
+	docs, err := PeekKeys(y, "apiVersion", "kind", "metadata.name")
+	if err != nil {
+		return nil, err
+	}
+	kinds := make([]DocumentKind, len(docs))
+	for i, d := range docs {
+		kinds[i] = DocumentKind{
+			APIVersion: d["apiVersion"],
+			Kind:       d["kind"],
+			Name:       d["metadata.name"],
+		}
+	}
+	return kinds, nil
+}
+
+// PeekKeys reads the scalar values found at the path expressions, as used
+// by Query, from every document in the stream. Each document gives a map
+// from the path to the value, without the paths that don't match a scalar.
+func PeekKeys(y []byte, paths ...string) ([]map[string]string, error) {
+	segs := make([][]pathSegment, len(paths))
+	for i, p := range paths {
+		var err error
+		if segs[i], err = parsePath(p); err != nil {
+			return nil, err
+		}
+	}
+	var docs []map[string]string
+	dec := yaml.NewDecoder(bytes.NewReader(y))
+	for {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("error parsing YAML document %d: %w", len(docs), err)
+		}
+		docs = append(docs, peekNode(&n, paths, segs))
+	}
+}
+
+// peekNode reads the scalar values found at the paths in the document.
+func peekNode(n *yaml.Node, paths []string, segs [][]pathSegment) map[string]string {
+	values := make(map[string]string, len(paths))
+	if len(n.Content) > 0 {
+		for i, p := range paths {
+			if m := matchPath(n.Content[0], segs[i]); len(m) > 0 {
+				if v := resolveAlias(m[0]); v.Kind == yaml.ScalarNode && v.ShortTag() != "!!null" {
+					values[p] = v.Value
+				}
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,58 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+const manifestStream = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# An empty document.
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: &n api, labels: {app: *n}}
+spec:
+  replicas: 3
+---
+kind: [not, a, scalar]
+metadata:
+  name: ~
+`
+
+func TestPeekDocumentKind(t *testing.T) {
+	got, err := PeekDocumentKind([]byte(manifestStream))
+	if err != nil {
+		t.Fatalf("PeekDocumentKind() = %v", err)
+	}
+	want := []DocumentKind{
+		{APIVersion: "v1", Kind: "Service", Name: "web"},
+		{},
+		{APIVersion: "apps/v1", Kind: "Deployment", Name: "api"},
+		{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PeekDocumentKind() = %+v; want %+v", got, want)
+	}
+
+	if _, err := PeekDocumentKind([]byte("kind: a\n---\nkind: [\n")); err == nil {
+		t.Errorf("PeekDocumentKind() = nil; want parse error")
+	}
+}
+
+func TestPeekKeys(t *testing.T) {
+	got, err := PeekKeys([]byte(manifestStream), "metadata.labels.app", "spec.replicas")
+	if err != nil {
+		t.Fatalf("PeekKeys() = %v", err)
+	}
+	want := []map[string]string{{}, {}, {"metadata.labels.app": "api", "spec.replicas": "3"}, {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PeekKeys() = %v; want %v", got, want)
+	}
+	if _, err := PeekKeys(nil, "a[x"); err == nil {
+		t.Errorf("PeekKeys() = nil; want path error")
+	}
+}
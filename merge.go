@@ -0,0 +1,455 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrMergeConflict is returned by Merge when ErrorOnConflict is used and
+// two documents provide different values for the same path.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// MergeOpt configures the strategy used to merge documents.
+type MergeOpt func(*merger)
+
+// AppendLists will append sequences from later documents to those of
+// earlier ones, instead of replacing them.
+func AppendLists() MergeOpt {
+	return func(m *merger) {
+		m.lists = listAppend
+	}
+}
+
+// MergeListsByKey will merge sequences of mappings by matching items that
+// share the same value for the first of the keys they contain, for example
+// "name". Items without a match are appended.
+func MergeListsByKey(keys ...string) MergeOpt {
+	return func(m *merger) {
+		m.lists = listMergeByKey
+		m.listKeys = keys
+	}
+}
+
+// ErrorOnConflict makes Merge fail instead of overriding a value that was
+// already defined by an earlier document with a different value.
+func ErrorOnConflict() MergeOpt {
+	return func(m *merger) {
+		m.conflicts = true
+	}
+}
+
+// StrategicMerge enables Kubernetes style strategic merge patching. The
+// merge keys map the names of fields containing lists of mappings to the key
+// used to match their items, for example "containers" to "name". Lists
+// without a merge key follow the list strategy in use. Additionally:
+//
+//   - null values remove the key,
+//   - "$patch: replace" replaces a mapping, or a list when included as an
+//     item, instead of merging it,
+//   - "$patch: delete" removes a mapping or matching list item, and
+//   - "$deleteFromPrimitiveList/<field>" removes values from a list of
+//     scalars.
+//
+// The "$retainKeys" and "$setElementOrder/<field>" directives are accepted
+// but ignored. Other keys starting with "$" are merged as regular data.
+func StrategicMerge(mergeKeys map[string]string) MergeOpt {
+	return func(m *merger) {
+		m.strategic = true
+		m.strategicKeys = mergeKeys
+	}
+}
+
+const (
+	patchDirective          = "$patch"
+	retainKeysDirective     = "$retainKeys"
+	deleteFromPrimitiveList = "$deleteFromPrimitiveList/"
+	setElementOrder         = "$setElementOrder/"
+)
+
+// isDirective returns true if the mapping key is a strategic merge
+// directive. Other keys starting with "$", such as "$ref" in a schema, are
+// regular data.
+func isDirective(key string) bool {
+	return key == patchDirective || key == retainKeysDirective ||
+		strings.HasPrefix(key, deleteFromPrimitiveList) ||
+		strings.HasPrefix(key, setElementOrder)
+}
+
+type listStrategy int
+
+const (
+	listReplace listStrategy = iota
+	listAppend
+	listMergeByKey
+)
+
+// merger holds the strategy used to combine node trees.
+type merger struct {
+	lists     listStrategy
+	listKeys  []string
+	conflicts bool
+
+	strategic     bool
+	strategicKeys map[string]string
+}
+
+// Merge combines the YAML documents provided so that values in later
+// documents take precedence over those in earlier ones. Mappings are merged
+// recursively, while scalars and, by default, sequences are replaced. The
+// first document's comments, key order, and indentation are preserved in
+// the output.
+func Merge(docs [][]byte, opts ...MergeOpt) ([]byte, error) {
+	doc, err := mergeDocuments(docs, opts)
+	if err != nil {
+		return nil, err
+	}
+	indent := defaultIndent
+	if len(docs) > 0 {
+		indent = detectIndent(docs[0])
+	}
+	return encodeDocument(doc, indent)
+}
+
+// UnmarshalMerged merges the YAML documents in the same way as Merge and
+// unmarshals the result into the object.
+func UnmarshalMerged(docs [][]byte, o interface{}, opts ...MergeOpt) error {
+	doc, err := mergeDocuments(docs, opts)
+	if err != nil {
+		return err
+	}
+	return NodeToStruct(doc, o)
+}
+
+// MergeNodes merges the src node tree into dst, in place.
+func MergeNodes(dst, src *yaml.Node, opts ...MergeOpt) error {
+	m := new(merger)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m.merge(dst, src, nil)
+}
+
+func mergeDocuments(docs [][]byte, opts []MergeOpt) (*yaml.Node, error) {
+	var dst *yaml.Node
+	for i, y := range docs {
+		doc, err := parseDocument(y)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing YAML document %d: %v", i, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		if dst == nil {
+			dst = doc
+			continue
+		}
+		if err := MergeNodes(dst, doc, opts...); err != nil {
+			return nil, err
+		}
+	}
+	if dst == nil {
+		dst = &yaml.Node{Kind: yaml.DocumentNode}
+	}
+	return dst, nil
+}
+
+func (m *merger) merge(dst, src *yaml.Node, path []interface{}) error {
+	if dst.Kind == yaml.DocumentNode && src.Kind == yaml.DocumentNode {
+		if len(src.Content) == 0 {
+			return nil
+		}
+		if len(dst.Content) == 0 {
+			dst.Content = []*yaml.Node{copyNode(src.Content[0])}
+			return nil
+		}
+		return m.merge(dst.Content[0], src.Content[0], path)
+	}
+	src = resolveAlias(src)
+	if dst.Kind == yaml.AliasNode {
+		// Avoid modifying the anchored node shared with other paths.
+		replaceNode(dst, copyNode(resolveAlias(dst)))
+	}
+
+	if m.strategic {
+		if handled, err := m.mergeStrategic(dst, src, path); handled || err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		return m.mergeMapping(dst, src, path)
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		switch m.lists {
+		case listAppend:
+			for _, c := range src.Content {
+				dst.Content = append(dst.Content, m.copyNode(c))
+			}
+			return nil
+		case listMergeByKey:
+			return m.mergeSequenceByKey(dst, src, m.listKeys, path)
+		}
+	}
+
+	if m.conflicts && !nodesEqual(dst, src) {
+		return fmt.Errorf("%w at %s", ErrMergeConflict, formatPath(path))
+	}
+	replaceNode(dst, m.copyNode(src))
+	return nil
+}
+
+// copyNode copies the source node, removing any strategic merge directives.
+// Aliases are replaced with copies of the nodes they refer to, and anchors
+// dropped, as the anchors may not be copied into the result along with
+// them.
+func (m *merger) copyNode(n *yaml.Node) *yaml.Node {
+	n = expandAliases(n, make(map[*yaml.Node]bool))
+	if m.strategic {
+		stripDirectives(n)
+	}
+	return n
+}
+
+func (m *merger) mergeMapping(dst, src *yaml.Node, path []interface{}) error {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		k, v := src.Content[i], src.Content[i+1]
+		if m.strategic && isDirective(k.Value) {
+			continue
+		}
+		found := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value != k.Value {
+				continue
+			}
+			found = true
+			if m.strategic && isDeletion(v) {
+				dst.Content = append(dst.Content[:j], dst.Content[j+2:]...)
+				break
+			}
+			if err := m.merge(dst.Content[j+1], v, append(path, k.Value)); err != nil {
+				return err
+			}
+			break
+		}
+		if !found && !(m.strategic && isDeletion(v)) {
+			dst.Content = append(dst.Content, copyNode(k), m.copyNode(v))
+		}
+	}
+	return nil
+}
+
+// mergeSequenceByKey merges the items of the sequences that match on one
+// of the keys, using the merger's own strategy for their contents.
+func (m *merger) mergeSequenceByKey(dst, src *yaml.Node, keys []string, path []interface{}) error {
+	for i, item := range src.Content {
+		item = resolveAlias(item)
+		var match *yaml.Node
+		if key, value, ok := itemKey(item, keys); ok {
+			for _, d := range dst.Content {
+				if dk, dv, ok := itemKey(resolveAlias(d), keys); ok && dk == key && dv == value {
+					match = d
+					break
+				}
+			}
+		}
+		if match == nil {
+			dst.Content = append(dst.Content, m.copyNode(item))
+			continue
+		}
+		if err := m.merge(match, item, append(path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemKey returns the name and value of the first of the merge keys found
+// in the sequence item.
+func itemKey(n *yaml.Node, keys []string) (string, string, bool) {
+	if n.Kind != yaml.MappingNode {
+		return "", "", false
+	}
+	for _, key := range keys {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key && n.Content[i+1].Kind == yaml.ScalarNode {
+				return key, n.Content[i+1].Value, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// copyNode makes a deep copy of the node tree. Aliases will continue to
+// point to the original anchored nodes.
+func copyNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, cn := range n.Content {
+			c.Content[i] = copyNode(cn)
+		}
+	}
+	return &c
+}
+
+// expandAliases makes a deep copy of the node tree without anchors, with
+// aliases replaced by copies of their anchored nodes. The nodes being
+// expanded are tracked in active, so that recursive aliases are kept.
+func expandAliases(n *yaml.Node, active map[*yaml.Node]bool) *yaml.Node {
+	if n.Kind == yaml.AliasNode && n.Alias != nil && !active[n.Alias] {
+		active[n.Alias] = true
+		defer delete(active, n.Alias)
+		c := expandAliases(n.Alias, active)
+		c.HeadComment, c.LineComment, c.FootComment = n.HeadComment, n.LineComment, n.FootComment
+		return c
+	}
+	c := *n
+	c.Anchor = ""
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, cn := range n.Content {
+			c.Content[i] = expandAliases(cn, active)
+		}
+	}
+	return &c
+}
+
+// nodesEqual compares the values represented by the two nodes as JSON
+// values, ignoring styles, comments, and positions, so that 1 and 1.0 are
+// equal, in the same way as Equal.
+func nodesEqual(a, b *yaml.Node) bool {
+	var av, bv interface{}
+	if NodeToStruct(a, &av, UseNumber) != nil || NodeToStruct(b, &bv, UseNumber) != nil {
+		return false
+	}
+	return reflect.DeepEqual(exactNumbers(av), exactNumbers(bv))
+}
+
+// mergeStrategic applies the strategic merge rules that differ from a
+// regular merge, returning true if the node was handled.
+func (m *merger) mergeStrategic(dst, src *yaml.Node, path []interface{}) (bool, error) {
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		if directiveValue(src) == "replace" {
+			replaceNode(dst, m.copyNode(src))
+			return true, nil
+		}
+		// Remove values from lists of scalars before merging.
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			field := strings.TrimPrefix(src.Content[i].Value, deleteFromPrimitiveList)
+			if field == src.Content[i].Value {
+				continue
+			}
+			list, _ := childNode(dst, field)
+			if list != nil && list.Kind == yaml.SequenceNode {
+				list.Content = removeScalars(list.Content, src.Content[i+1])
+			}
+		}
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		for _, item := range src.Content {
+			if directiveValue(item) == "replace" {
+				items := make([]*yaml.Node, 0, len(src.Content))
+				for _, c := range src.Content {
+					if c != item {
+						items = append(items, m.copyNode(c))
+					}
+				}
+				dst.Content = items
+				return true, nil
+			}
+		}
+		var field string
+		if len(path) > 0 {
+			field, _ = path[len(path)-1].(string)
+		}
+		key, ok := m.strategicKeys[field]
+		if !ok {
+			return false, nil
+		}
+		keys := []string{key}
+		for _, item := range src.Content {
+			item = resolveAlias(item)
+			if directiveValue(item) != "delete" {
+				continue
+			}
+			_, value, ok := itemKey(item, keys)
+			content := dst.Content[:0]
+			for _, d := range dst.Content {
+				if _, dv, dok := itemKey(resolveAlias(d), keys); !ok || !dok || dv != value {
+					content = append(content, d)
+				}
+			}
+			dst.Content = content
+		}
+		items := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range src.Content {
+			if directiveValue(item) != "delete" {
+				items.Content = append(items.Content, item)
+			}
+		}
+		return true, m.mergeSequenceByKey(dst, items, keys, path)
+	}
+	return false, nil
+}
+
+// directiveValue returns the value of the "$patch" directive contained in
+// a mapping node.
+func directiveValue(n *yaml.Node) string {
+	n = resolveAlias(n)
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+	v, _ := childNode(n, patchDirective)
+	if v == nil {
+		return ""
+	}
+	return v.Value
+}
+
+// isDeletion returns true if the strategic patch value removes the key.
+func isDeletion(n *yaml.Node) bool {
+	n = resolveAlias(n)
+	return isNullNode(n) || directiveValue(n) == "delete"
+}
+
+// stripDirectives removes strategic merge directives from the node tree.
+func stripDirectives(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if !isDirective(n.Content[i].Value) {
+				content = append(content, n.Content[i], n.Content[i+1])
+			}
+		}
+		n.Content = content
+	}
+	for _, c := range n.Content {
+		stripDirectives(c)
+	}
+}
+
+// removeScalars returns the items that don't match any of the scalar
+// values in the list node.
+func removeScalars(items []*yaml.Node, list *yaml.Node) []*yaml.Node {
+	out := items[:0]
+	for _, item := range items {
+		remove := false
+		for _, r := range list.Content {
+			if item.Kind == yaml.ScalarNode && item.Value == r.Value {
+				remove = true
+				break
+			}
+		}
+		if !remove {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var mergeBase = []byte(`# defaults
+name: app
+replicas: 1
+env:
+  - name: LOG
+    value: info
+  - name: PORT
+    value: "80"
+labels:
+  tier: web
+`)
+
+var mergeOverlay = []byte(`replicas: 3
+env:
+  - name: PORT
+    value: "8080"
+  - name: DEBUG
+    value: "true"
+labels:
+  team: core
+`)
+
+func TestMerge(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []MergeOpt
+		want string
+	}{
+		{
+			"override",
+			nil,
+			"# defaults\nname: app\nreplicas: 3\nenv:\n  - name: PORT\n    value: \"8080\"\n  - name: DEBUG\n    value: \"true\"\nlabels:\n  tier: web\n  team: core\n",
+		},
+		{
+			"append",
+			[]MergeOpt{AppendLists()},
+			"# defaults\nname: app\nreplicas: 3\nenv:\n  - name: LOG\n    value: info\n  - name: PORT\n    value: \"80\"\n  - name: PORT\n    value: \"8080\"\n  - name: DEBUG\n    value: \"true\"\nlabels:\n  tier: web\n  team: core\n",
+		},
+		{
+			"by key",
+			[]MergeOpt{MergeListsByKey("name")},
+			"# defaults\nname: app\nreplicas: 3\nenv:\n  - name: LOG\n    value: info\n  - name: PORT\n    value: \"8080\"\n  - name: DEBUG\n    value: \"true\"\nlabels:\n  tier: web\n  team: core\n",
+		},
+	} {
+		got, err := Merge([][]byte{mergeBase, nil, mergeOverlay}, tc.opts...)
+		if err != nil {
+			t.Errorf("Merge(%s) = %v", tc.name, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("Merge(%s) = %q; want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMergeConflict(t *testing.T) {
+	_, err := Merge([][]byte{mergeBase, mergeOverlay}, ErrorOnConflict(), MergeListsByKey("name"))
+	if !errors.Is(err, ErrMergeConflict) || err.Error() != "merge conflict at replicas" {
+		t.Errorf("Merge() = %v; want conflict at replicas", err)
+	}
+
+	// Identical values are not conflicts.
+	if _, err := Merge([][]byte{mergeBase, []byte("replicas: 1\nextra: x\n")}, ErrorOnConflict()); err != nil {
+		t.Errorf("Merge() = %v; want no error", err)
+	}
+}
+
+func TestMergeAnchors(t *testing.T) {
+	dst := []byte("a: 1\nb: 2\nc: {x: 1}\n")
+	src := []byte("a: &x {k: v}\nb: *x\nc: &y {y: 2}\nd: *y\ne: [*x]\n")
+	got, err := Merge([][]byte{dst, src})
+	if err != nil {
+		t.Fatalf("Merge() = %v", err)
+	}
+	var v interface{}
+	if err := Unmarshal(got, &v); err != nil {
+		t.Fatalf("Unmarshal(%q) = %v", got, err)
+	}
+	kv := map[string]interface{}{"k": "v"}
+	want := map[string]interface{}{
+		"a": kv,
+		"b": kv,
+		"c": map[string]interface{}{"x": float64(1), "y": float64(2)},
+		"d": map[string]interface{}{"y": float64(2)},
+		"e": []interface{}{kv},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Merge() = %q; want %v", got, want)
+	}
+}
+
+func TestUnmarshalMerged(t *testing.T) {
+	var cfg struct {
+		Name     string            `json:"name"`
+		Replicas int               `json:"replicas"`
+		Labels   map[string]string `json:"labels"`
+	}
+	if err := UnmarshalMerged([][]byte{mergeBase, mergeOverlay}, &cfg); err != nil {
+		t.Fatalf("UnmarshalMerged() = %v", err)
+	}
+	if cfg.Name != "app" || cfg.Replicas != 3 || len(cfg.Labels) != 2 {
+		t.Errorf("UnmarshalMerged() = %+v", cfg)
+	}
+}
+
+func TestStrategicMerge(t *testing.T) {
+	base := []byte(`spec:
+  containers:
+    - name: app
+      image: app:1.0
+      args: [--a, --b]
+    - name: sidecar
+      image: proxy:1.0
+  volumes:
+    - name: data
+  finalizers: [a, b, c]
+  selector:
+    app: web
+    tier: front
+  debug: true
+`)
+	patch := []byte(`spec:
+  containers:
+    - name: app
+      image: app:2.0
+    - name: sidecar
+      $patch: delete
+    - name: logger
+      image: fluent:1.0
+  volumes:
+    - $patch: replace
+    - name: cache
+  $deleteFromPrimitiveList/finalizers: [b]
+  selector:
+    $patch: replace
+    app: api
+  debug: null
+`)
+	want := `spec:
+  containers:
+    - name: app
+      image: app:2.0
+      args: [--a, --b]
+    - name: logger
+      image: fluent:1.0
+  volumes:
+    - name: cache
+  finalizers: [a, c]
+  selector:
+    app: api
+`
+	got, err := Merge([][]byte{base, patch}, StrategicMerge(map[string]string{"containers": "name"}))
+	if err != nil {
+		t.Fatalf("Merge() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Merge() = %q; want %q", got, want)
+	}
+}
+
+func TestStrategicMergeNested(t *testing.T) {
+	base := []byte(`containers:
+  - name: app
+    args: [x, y]
+    ports:
+      - port: 80
+schema:
+  $schema: draft-07
+  $ref: "#/defs/a"
+`)
+	patch := []byte(`containers:
+  - name: app
+    args: [z]
+    ports:
+      - port: 443
+    $setElementOrder/ports:
+      - port: 443
+schema:
+  $ref: "#/defs/b"
+  $id: c
+`)
+	want := `containers:
+  - name: app
+    args: [z]
+    ports:
+      - port: 443
+schema:
+  $schema: draft-07
+  $ref: "#/defs/b"
+  $id: c
+`
+	got, err := Merge([][]byte{base, patch}, StrategicMerge(map[string]string{"containers": "name"}))
+	if err != nil {
+		t.Fatalf("Merge() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("Merge() = %q; want %q", got, want)
+	}
+}
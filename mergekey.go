@@ -0,0 +1,166 @@
+package yaml
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// MergeKeyMode selects how merge keys (<<) are handled when decoding.
+type MergeKeyMode int
+
+const (
+	// MergeKeysResolve merges the keys of the mappings referenced by a merge
+	// key into the mapping that holds it, as go-yaml does. Keys written in
+	// the mapping itself take precedence, followed by those of the mappings
+	// listed first. This is the default.
+	MergeKeysResolve MergeKeyMode = iota
+	// MergeKeysReject returns a *MergeKeyError for the first merge key in
+	// the document.
+	MergeKeysReject
+	// MergeKeysKeep reads merge keys as ordinary "<<" keys, so that the
+	// referenced mappings are kept as their value.
+	MergeKeysKeep
+)
+
+// MergeKeys configures how merge keys (<<) are handled.
+func MergeKeys(mode MergeKeyMode) DecodeOpt {
+	return func(d *Decoder) {
+		d.mergeKeys = mode
+	}
+}
+
+// MergeKeyError is returned when merge keys are rejected, or when a merge
+// key refers to something other than mappings.
+type MergeKeyError struct {
+	Line    int
+	Column  int
+	Invalid bool // true if the merge key's value is not a mapping
+}
+
+func (e *MergeKeyError) Error() string {
+	if e.Invalid {
+		return positionPrefix(e.Line, e.Column) + "merge key requires a mapping or a sequence of mappings"
+	}
+	return positionPrefix(e.Line, e.Column) + "merge keys are not allowed"
+}
+
+// isMergeKey reports whether the mapping key is a merge key.
+func isMergeKey(k *yaml.Node) bool {
+	return k.Kind == yaml.ScalarNode && k.ShortTag() == "!!merge"
+}
+
+// applyMergeKeys prepares the merge keys in the node tree according to the
+// mode.
+func applyMergeKeys(n *yaml.Node, mode MergeKeyMode) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if k := n.Content[i]; isMergeKey(k) {
+				if mode == MergeKeysReject {
+					return &MergeKeyError{Line: k.Line, Column: k.Column}
+				}
+				k.Tag = "!!str"
+			}
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+	default:
+		return nil
+	}
+	for _, c := range n.Content {
+		if err := applyMergeKeys(c, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FlattenMergeKeys replaces every merge key (<<) in the node tree with
+// copies of the keys it merges, following the same rules as Unmarshal, so
+// that the tree can be written out or inspected without them.
+func FlattenMergeKeys(n *yaml.Node) error {
+	return flattenMergeKeys(n, make(map[*yaml.Node]bool))
+}
+
+func flattenMergeKeys(n *yaml.Node, done map[*yaml.Node]bool) error {
+	n = resolveAlias(n)
+	if n == nil || done[n] {
+		return nil
+	}
+	done[n] = true
+	for _, c := range n.Content {
+		if err := flattenMergeKeys(c, done); err != nil {
+			return err
+		}
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	set := make(map[string]bool, len(n.Content)/2)
+	merges := false
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := n.Content[i]; isMergeKey(k) {
+			merges = true
+		} else {
+			set[k.Value] = true
+		}
+	}
+	if !merges {
+		return nil
+	}
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if !isMergeKey(k) {
+			content = append(content, k, v)
+			continue
+		}
+		sources, err := mergeSources(v)
+		if err != nil {
+			return err
+		}
+		for _, src := range sources {
+			for j := 0; j+1 < len(src.Content); j += 2 {
+				if sk := src.Content[j]; !set[sk.Value] {
+					set[sk.Value] = true
+					content = append(content, unanchored(sk), unanchored(src.Content[j+1]))
+				}
+			}
+		}
+	}
+	n.Content = content
+	return nil
+}
+
+// mergeSources returns the mappings referenced by the value of a merge key.
+func mergeSources(v *yaml.Node) ([]*yaml.Node, error) {
+	invalid := &MergeKeyError{Line: v.Line, Column: v.Column, Invalid: true}
+	v = resolveAlias(v)
+	switch v.Kind {
+	case yaml.MappingNode:
+		return []*yaml.Node{v}, nil
+	case yaml.SequenceNode:
+		sources := make([]*yaml.Node, len(v.Content))
+		for i, c := range v.Content {
+			if sources[i] = resolveAlias(c); sources[i].Kind != yaml.MappingNode {
+				return nil, invalid
+			}
+		}
+		return sources, nil
+	}
+	return nil, invalid
+}
+
+// unanchored copies the node tree without its anchors, which would
+// otherwise be defined twice.
+func unanchored(n *yaml.Node) *yaml.Node {
+	c := copyNode(n)
+	var clear func(n *yaml.Node)
+	clear = func(n *yaml.Node) {
+		n.Anchor = ""
+		for _, c := range n.Content {
+			clear(c)
+		}
+	}
+	clear(c)
+	return c
+}
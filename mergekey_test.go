@@ -0,0 +1,84 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const mergeKeysYAML = `base: &base {x: 1, y: 2}
+extra: &extra {y: 5, z: 6}
+c:
+  <<: [*base, *extra]
+  y: 3
+`
+
+func TestMergeKeys(t *testing.T) {
+	tests := []struct {
+		mode MergeKeyMode
+		want string
+	}{
+		{MergeKeysResolve, `{"base":{"x":1,"y":2},"c":{"x":1,"y":3,"z":6},"extra":{"y":5,"z":6}}`},
+		{MergeKeysKeep, `{"base":{"x":1,"y":2},"c":{"\u003c\u003c":[{"x":1,"y":2},{"y":5,"z":6}],"y":3},"extra":{"y":5,"z":6}}`},
+	}
+	for _, test := range tests {
+		j, err := YAMLToJSONWithOptions([]byte(mergeKeysYAML), MergeKeys(test.mode))
+		if err != nil {
+			t.Fatalf("YAMLToJSONWithOptions(%d) = %v", test.mode, err)
+		}
+		if string(j) != test.want {
+			t.Errorf("YAMLToJSONWithOptions(%d) = %s; want %s", test.mode, j, test.want)
+		}
+	}
+
+	var v interface{}
+	err := UnmarshalWithOptions([]byte(mergeKeysYAML), &v, MergeKeys(MergeKeysReject))
+	var merr *MergeKeyError
+	if !errors.As(err, &merr) || merr.Line != 4 || merr.Column != 3 || merr.Invalid {
+		t.Errorf("UnmarshalWithOptions() = %v; want merge key error at line 4, column 3", err)
+	}
+	if err := UnmarshalWithOptions([]byte("'<<': 1\n"), &v, MergeKeys(MergeKeysReject)); err != nil {
+		t.Errorf("UnmarshalWithOptions() = %v; want quoted key allowed", err)
+	}
+}
+
+func TestFlattenMergeKeys(t *testing.T) {
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(mergeKeysYAML+"d:\n  <<: *base\n  <<: {w: &w 0}\n"), &n); err != nil {
+		t.Fatal(err)
+	}
+	if err := FlattenMergeKeys(&n); err != nil {
+		t.Fatalf("FlattenMergeKeys() = %v", err)
+	}
+	y, err := yaml.Marshal(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "base: &base {x: 1, y: 2}\nextra: &extra {y: 5, z: 6}\nc:\n    x: 1\n    z: 6\n    y: 3\nd:\n    x: 1\n    y: 2\n    w: 0\n"
+	if string(y) != want {
+		t.Errorf("FlattenMergeKeys() = %q; want %q", y, want)
+	}
+
+	var got, orig interface{}
+	if err := Unmarshal(y, &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal([]byte(mergeKeysYAML), &orig); err != nil {
+		t.Fatal(err)
+	}
+	delete(got.(map[string]interface{}), "d")
+	if !reflect.DeepEqual(got, orig) {
+		t.Errorf("FlattenMergeKeys() = %v; want %v", got, orig)
+	}
+
+	if err := yaml.Unmarshal([]byte("a: {<<: [1]}\n"), &n); err != nil {
+		t.Fatal(err)
+	}
+	err = FlattenMergeKeys(&n)
+	var merr *MergeKeyError
+	if !errors.As(err, &merr) || !merr.Invalid {
+		t.Errorf("FlattenMergeKeys() = %v; want invalid merge key error", err)
+	}
+}
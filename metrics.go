@@ -0,0 +1,127 @@
+package yaml
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeStats describes a call that decoded YAML. For a Decoder, Bytes is
+// the amount read from the reader during the call, which includes any data
+// buffered ahead of the document.
+type DecodeStats struct {
+	Bytes     int           // bytes of YAML read
+	Documents int           // documents decoded
+	Aliases   int           // aliases expanded
+	Duration  time.Duration // time spent decoding
+	Err       error         // error returned, if any
+}
+
+// EncodeStats describes a call that encoded YAML.
+type EncodeStats struct {
+	Bytes    int           // bytes of YAML produced
+	Duration time.Duration // time spent encoding
+	Err      error         // error returned, if any
+}
+
+// Metrics receives measurements of the work done by the package, for
+// example to export them to a monitoring system. Implementations must be
+// safe for concurrent use.
+type Metrics interface {
+	Decoded(s DecodeStats)
+	Encoded(s EncodeStats)
+}
+
+// metricsHolder allows a nil Metrics to be stored in an atomic.Value.
+type metricsHolder struct {
+	m Metrics
+}
+
+var globalMetrics atomic.Value // metricsHolder
+
+// SetMetrics registers the metrics used by Marshal, Unmarshal, YAMLToJSON
+// and decoders that do not set their own, replacing any previous one. A nil
+// value disables metrics.
+func SetMetrics(m Metrics) {
+	globalMetrics.Store(metricsHolder{m})
+}
+
+// ReportMetrics configures the decoder to send the statistics for each
+// document to the metrics instead of those set with SetMetrics.
+func ReportMetrics(m Metrics) DecodeOpt {
+	return func(d *Decoder) {
+		d.metrics = m
+	}
+}
+
+func currentMetrics() Metrics {
+	h, _ := globalMetrics.Load().(metricsHolder)
+	return h.m
+}
+
+// decodeTracker collects the statistics of a decode. A nil tracker is
+// valid and does nothing, so the cost is avoided without metrics.
+type decodeTracker struct {
+	m     Metrics
+	start time.Time
+	stats DecodeStats
+}
+
+func startDecode(m Metrics) *decodeTracker {
+	if m == nil {
+		return nil
+	}
+	return &decodeTracker{m: m, start: time.Now()}
+}
+
+// document records a decoded document.
+func (t *decodeTracker) document(n *yaml.Node) {
+	if t == nil || n == nil {
+		return
+	}
+	t.stats.Documents++
+	t.stats.Aliases += countAliases(n)
+}
+
+func (t *decodeTracker) done(bytes int, err error) {
+	if t == nil {
+		return
+	}
+	t.stats.Bytes = bytes
+	t.stats.Duration = time.Since(t.start)
+	t.stats.Err = err
+	t.m.Decoded(t.stats)
+}
+
+func countAliases(n *yaml.Node) int {
+	if n.Kind == yaml.AliasNode {
+		return 1
+	}
+	c := 0
+	for _, child := range n.Content {
+		c += countAliases(child)
+	}
+	return c
+}
+
+// observeEncode reports the statistics of an encode started at the time.
+func observeEncode(m Metrics, start time.Time, y []byte, err error) {
+	if m == nil {
+		return
+	}
+	m.Encoded(EncodeStats{Bytes: len(y), Duration: time.Since(start), Err: err})
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
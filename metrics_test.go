@@ -0,0 +1,91 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+)
+
+type testMetrics struct {
+	mu      sync.Mutex
+	decoded []DecodeStats
+	encoded []EncodeStats
+}
+
+func (m *testMetrics) Decoded(s DecodeStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.decoded = append(m.decoded, s)
+}
+
+func (m *testMetrics) Encoded(s EncodeStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.encoded = append(m.encoded, s)
+}
+
+func TestSetMetrics(t *testing.T) {
+	m := new(testMetrics)
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	y := []byte("base: &b [1, 2]\na: *b\nc: *b\n")
+	var v interface{}
+	if err := Unmarshal(y, &v); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	if _, err := YAMLToJSON([]byte("a: [\n")); err == nil {
+		t.Fatalf("YAMLToJSON() = nil; want error")
+	}
+	out, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+
+	if len(m.decoded) != 2 {
+		t.Fatalf("decoded = %+v; want 2 calls", m.decoded)
+	}
+	if s := m.decoded[0]; s.Bytes != len(y) || s.Documents != 1 || s.Aliases != 2 || s.Err != nil || s.Duration <= 0 {
+		t.Errorf("decoded[0] = %+v", s)
+	}
+	if s := m.decoded[1]; s.Err == nil || s.Documents != 0 {
+		t.Errorf("decoded[1] = %+v; want error", s)
+	}
+	if len(m.encoded) != 1 || m.encoded[0].Bytes != len(out) {
+		t.Errorf("encoded = %+v; want %d bytes", m.encoded, len(out))
+	}
+
+	SetMetrics(nil)
+	if err := Unmarshal(y, &v); err != nil || len(m.decoded) != 2 {
+		t.Errorf("Unmarshal() reported metrics after SetMetrics(nil)")
+	}
+}
+
+func TestReportMetrics(t *testing.T) {
+	m := new(testMetrics)
+	y := []byte("a: 1\n---\nb: 2\n")
+	d := NewDecoder(bytes.NewReader(y), ReportMetrics(m))
+	var v interface{}
+	for {
+		if err := d.Decode(&v); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("Decode() = %v", err)
+		}
+	}
+	if len(m.decoded) != 2 {
+		t.Fatalf("decoded = %+v; want 2 documents", m.decoded)
+	}
+	total := 0
+	for _, s := range m.decoded {
+		if s.Documents != 1 {
+			t.Errorf("decoded = %+v; want 1 document", s)
+		}
+		total += s.Bytes
+	}
+	if total != len(y) {
+		t.Errorf("decoded bytes = %d; want %d", total, len(y))
+	}
+}
@@ -0,0 +1,30 @@
+package yaml
+
+// MustMarshal behaves like MarshalWithOptions but panics if the object
+// can't be encoded. It is intended for tests and values known to be valid,
+// such as embedded defaults.
+func MustMarshal(o interface{}, opts ...EncodeOpt) []byte {
+	y, err := MarshalWithOptions(o, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return y
+}
+
+// MustUnmarshal behaves like Unmarshal but panics if the document can't be
+// decoded into the object.
+func MustUnmarshal(y []byte, o interface{}, opts ...JSONOpt) {
+	if err := Unmarshal(y, o, opts...); err != nil {
+		panic(err)
+	}
+}
+
+// MustYAMLToJSON behaves like YAMLToJSON but panics if the document can't
+// be converted.
+func MustYAMLToJSON(y []byte) []byte {
+	j, err := YAMLToJSON(y)
+	if err != nil {
+		panic(err)
+	}
+	return j
+}
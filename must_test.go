@@ -0,0 +1,36 @@
+package yaml
+
+import (
+	"testing"
+)
+
+// mustPanic reports whether the function panics.
+func mustPanic(fn func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	fn()
+	return false
+}
+
+func TestMust(t *testing.T) {
+	if y := MustMarshal(map[string]int{"a": 1}); string(y) != "a: 1\n" {
+		t.Errorf("MustMarshal() = %q", y)
+	}
+	var m map[string]int
+	MustUnmarshal([]byte("a: 1\n"), &m)
+	if m["a"] != 1 {
+		t.Errorf("MustUnmarshal() = %v", m)
+	}
+	if j := MustYAMLToJSON([]byte("a: 1\n")); string(j) != `{"a":1}` {
+		t.Errorf("MustYAMLToJSON() = %s", j)
+	}
+
+	if !mustPanic(func() { MustMarshal(func() {}) }) {
+		t.Errorf("MustMarshal() did not panic")
+	}
+	if !mustPanic(func() { MustUnmarshal([]byte("a: x\n"), &m) }) {
+		t.Errorf("MustUnmarshal() did not panic")
+	}
+	if !mustPanic(func() { MustYAMLToJSON([]byte("a: [\n")) }) {
+		t.Errorf("MustYAMLToJSON() did not panic")
+	}
+}
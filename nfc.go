@@ -0,0 +1,34 @@
+package yaml
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+)
+
+// NormalizeKeys converts mapping keys to Unicode normalization form C
+// before decoding, so that keys which look the same but are written with
+// different code points, such as "é" as one character or as "e" with a
+// combining accent, match the same field. Keys that only differ in their
+// form are reported as duplicates.
+func NormalizeKeys() DecodeOpt {
+	return func(d *Decoder) {
+		d.normalizeKeys = true
+	}
+}
+
+// normalizeKeys converts the string keys in the node tree to NFC.
+func normalizeKeys(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if k := n.Content[i]; k.Kind == yaml.ScalarNode && !norm.NFC.IsNormalString(k.Value) {
+				k.Value = norm.NFC.String(k.Value)
+			}
+			normalizeKeys(n.Content[i+1])
+		}
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			normalizeKeys(c)
+		}
+	}
+}
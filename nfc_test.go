@@ -0,0 +1,39 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeKeys(t *testing.T) {
+	type cafe struct {
+		Cafe string `json:"café"`
+	}
+	decomposed := []byte("cafe\u0301: open\n")
+
+	var c cafe
+	if err := UnmarshalWithOptions(decomposed, &c); err != nil || c.Cafe != "" {
+		t.Errorf("UnmarshalWithOptions() = %+v, %v; want unset field", c, err)
+	}
+	if err := UnmarshalWithOptions(decomposed, &c, NormalizeKeys()); err != nil || c.Cafe != "open" {
+		t.Errorf("UnmarshalWithOptions(NormalizeKeys()) = %+v, %v; want open", c, err)
+	}
+
+	j, err := YAMLToJSONWithOptions([]byte("list:\n  - cafe\u0301: 1\n"), NormalizeKeys())
+	if err != nil {
+		t.Fatalf("YAMLToJSONWithOptions() = %v", err)
+	}
+	if want := "{\"list\":[{\"caf\u00e9\":1}]}"; string(j) != want {
+		t.Errorf("YAMLToJSONWithOptions() = %s; want %s", j, want)
+	}
+
+	both := []byte("caf\u00e9: a\ncafe\u0301: b\n")
+	if err := UnmarshalWithOptions(both, &c); err != nil {
+		t.Errorf("UnmarshalWithOptions() = %v; want nil", err)
+	}
+	err = UnmarshalWithOptions(both, &c, NormalizeKeys())
+	var dup *DuplicateKeyError
+	if !errors.As(err, &dup) || dup.Line != 2 || dup.PrevLine != 1 {
+		t.Errorf("UnmarshalWithOptions(NormalizeKeys()) = %v; want duplicate key on line 2", err)
+	}
+}
@@ -0,0 +1,81 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DisallowUnknownFields configures the JSON decoder to error out if unknown
+// fields come along, instead of dropping them by default.
+func DisallowUnknownFields(d *json.Decoder) *json.Decoder {
+	d.DisallowUnknownFields()
+	return d
+}
+
+// UseNumber configures the JSON decoder to decode numbers into interface
+// values as json.Number, instead of float64.
+func UseNumber(d *json.Decoder) *json.Decoder {
+	d.UseNumber()
+	return d
+}
+
+// NodeToStruct decodes a go-yaml node tree into the object using the same
+// rules as Unmarshal, so JSON struct tags and custom JSON methods are
+// respected. This is useful when a node tree has already been parsed in
+// order to inspect comments or positions. Pass DisallowUnknownFields to
+// reject keys that don't map to a field.
+func NodeToStruct(n *yaml.Node, o interface{}, opts ...JSONOpt) error {
+	if n == nil {
+		return nil
+	}
+	if err := checkDuplicateKeys(n); err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %w", err)
+	}
+	defer retagTarget(n, reflect.TypeOf(o), retagOpts{})()
+	if knownFields, ok := directOpts(opts); ok && canDecodeDirect(n, o) {
+		if err := decodeDirect(n, o, directConfig{knownFields: knownFields}); err != nil {
+			return fmt.Errorf("error unmarshaling JSON: %w", err)
+		}
+		setPositions(n, o)
+		return nil
+	}
+	vo := reflect.ValueOf(o)
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := writeNodeJSON(b, n, &vo); err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+
+	j := b.Bytes()
+	if err := jsonUnmarshal(j, n, o, opts...); err != nil {
+		return fmt.Errorf("error unmarshaling JSON: %w", err)
+	}
+	setPositions(n, o)
+	return nil
+}
+
+// StructToNode marshals the object into JSON and then converts the result
+// into a go-yaml node tree, ready to be inspected, annotated, or encoded.
+// Comments from any CommentedValue will be included, and the same options as
+// Marshal are supported. The node returned is
+// the document's root value, not a document node.
+func StructToNode(o interface{}, opts ...EncodeOpt) (*yaml.Node, error) {
+	e := newEncoder(opts)
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := e.encodeJSON(b, o); err != nil {
+		return nil, fmt.Errorf("error marshaling into JSON: %v", err)
+	}
+
+	n, err := jsonToNode(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error converting JSON to YAML: %v", err)
+	}
+	if err := e.apply(n, o); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
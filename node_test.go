@@ -0,0 +1,70 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNodeToStruct(t *testing.T) {
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte("# comment\na: 1\nb: two\n"), &n); err != nil {
+		t.Fatalf("parsing YAML: %v", err)
+	}
+
+	s := UnmarshalStringStruct{}
+	if err := NodeToStruct(&n, &s); err != nil {
+		t.Fatalf("NodeToStruct() = %v", err)
+	}
+	if want := (UnmarshalStringStruct{A: "1", B: "two"}); s != want {
+		t.Errorf("NodeToStruct() = %+v; want %+v", s, want)
+	}
+
+	// Sub-trees can be decoded directly.
+	n = yaml.Node{}
+	if err := yaml.Unmarshal([]byte("outer:\n  a: x\n"), &n); err != nil {
+		t.Fatalf("parsing YAML: %v", err)
+	}
+	ns := NestedString{}
+	if err := NodeToStruct(n.Content[0].Content[1], &ns); err != nil {
+		t.Fatalf("NodeToStruct() = %v", err)
+	}
+	if ns.A != "x" {
+		t.Errorf("NodeToStruct() = %+v; want A=x", ns)
+	}
+}
+
+func TestNodeToStructKnownFields(t *testing.T) {
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte("a: 1\nc: 2\n"), &n); err != nil {
+		t.Fatalf("parsing YAML: %v", err)
+	}
+	s := UnmarshalStringStruct{}
+	err := NodeToStruct(&n, &s, DisallowUnknownFields)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "c"`) {
+		t.Errorf("NodeToStruct() = %v; want unknown field error", err)
+	}
+}
+
+func TestStructToNode(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	n, err := StructToNode(Person{Name: "John", Age: 30})
+	if err != nil {
+		t.Fatalf("StructToNode() = %v", err)
+	}
+	if n.Kind != yaml.MappingNode || len(n.Content) != 4 {
+		t.Fatalf("StructToNode() = %+v; want mapping with two keys", n)
+	}
+	n.Content[2].HeadComment = "# full name"
+	out, err := yaml.Marshal(n)
+	if err != nil {
+		t.Fatalf("encoding node: %v", err)
+	}
+	if want := "# full name\nname: John\n"; !strings.HasSuffix(string(out), want) {
+		t.Errorf("encoded node = %q; want suffix %q", out, want)
+	}
+}
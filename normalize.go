@@ -0,0 +1,29 @@
+package yaml
+
+import "crypto/sha256"
+
+// Normalize re-emits the YAML document in a canonical form, so that
+// documents holding the same values, as compared by Equal, give the same
+// bytes. Comments and anchors are dropped, aliases and merge keys are
+// expanded, keys are sorted, numbers are written in decimal without a
+// fraction when they have none, strings are only quoted where needed,
+// timestamps are written in RFC 3339 format, and every collection is in
+// block style.
+func Normalize(data []byte) ([]byte, error) {
+	j, err := YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return JSONToYAML(j)
+}
+
+// Hash returns the SHA-256 digest of the document in the form given by
+// Normalize, so that documents holding the same values have the same hash
+// whatever their formatting, key order or comments.
+func Hash(data []byte) ([32]byte, error) {
+	n, err := Normalize(data)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(n), nil
+}
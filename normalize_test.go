@@ -0,0 +1,67 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		y    string
+		want string
+	}{
+		{"", "null\n"},
+		{
+			"# config\nname: 'web'\nreplicas: 0x3\nratio: 1.0\nbase: &b {port: 80}\nsvc:\n  <<: *b\n  tls: true\non: yes\n",
+			"base:\n    port: 80\nname: web\n\"on\": \"yes\"\nratio: 1\nreplicas: 3\nsvc:\n    port: 80\n    tls: true\n",
+		},
+		{"- 2001-12-14\n- [1, 2.5]\n", "- \"2001-12-14T00:00:00Z\"\n- - 1\n  - 2.5\n"},
+	}
+	for _, test := range tests {
+		got, err := Normalize([]byte(test.y))
+		if err != nil {
+			t.Errorf("Normalize(%q) = %v", test.y, err)
+			continue
+		}
+		if string(got) != test.want {
+			t.Errorf("Normalize(%q) = %q; want %q", test.y, got, test.want)
+		}
+		again, err := Normalize(got)
+		if err != nil || string(again) != string(got) {
+			t.Errorf("Normalize(%q) is not idempotent: %q, %v", got, again, err)
+		}
+	}
+
+	a, _ := Normalize([]byte(diffA))
+	b, _ := Normalize([]byte("labels:\n  old: x\n  tier: web\nports: [80, 443, 8443]\nreplicas: 1\nname: web\n"))
+	if string(a) != string(b) {
+		t.Errorf("Normalize() of equal documents differ:\n%s\n%s", a, b)
+	}
+
+	if _, err := Normalize([]byte("a: [1")); err == nil {
+		t.Errorf("Normalize() = nil error; want error")
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, err := Hash([]byte(diffA))
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	b, err := Hash([]byte("{name: web, replicas: 1, ports: [80, 443, 8443], labels: {old: x, tier: web}} # flow"))
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	if a != b {
+		t.Errorf("Hash() of equal documents differ: %x, %x", a, b)
+	}
+	c, err := Hash([]byte(diffB))
+	if err != nil {
+		t.Fatalf("Hash() = %v", err)
+	}
+	if a == c {
+		t.Errorf("Hash() of different documents are equal: %x", a)
+	}
+	if _, err := Hash([]byte("a: [1")); err == nil {
+		t.Errorf("Hash() = nil error; want error")
+	}
+}
@@ -0,0 +1,119 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Nullable holds a value that may be given, explicitly null, or absent
+// altogether, as needed for PATCH requests and tri-state fields. Decoding
+// "key: null" sets Null, while a missing key leaves Set false. When
+// marshaled by this package, absent struct fields are omitted, null ones
+// are written as null, and others as their value.
+type Nullable[T any] struct {
+	Value T
+	Set   bool // the key was present
+	Null  bool // the value was null
+}
+
+// NewNullable provides a Nullable that holds the value.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Set: true}
+}
+
+// Null provides a Nullable that is explicitly null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Set: true, Null: true}
+}
+
+// IsZero reports whether the value is absent, for use with omitzero.
+func (n Nullable[T]) IsZero() bool {
+	return !n.Set
+}
+
+// absent is used by the encoder to find the fields to leave out.
+func (n Nullable[T]) absent() bool {
+	return !n.Set
+}
+
+// MarshalJSON provides the value, or null when it is null or absent.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if !n.Set || n.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.Value)
+}
+
+// UnmarshalJSON records that the value was present, and whether it was
+// null.
+func (n *Nullable[T]) UnmarshalJSON(b []byte) error {
+	var zero T
+	n.Value, n.Set, n.Null = zero, true, bytes.Equal(bytes.TrimSpace(b), []byte("null"))
+	if n.Null {
+		return nil
+	}
+	return json.Unmarshal(b, &n.Value)
+}
+
+// absentValue is implemented by values that may be left out.
+type absentValue interface {
+	absent() bool
+}
+
+var absentValueType = reflect.TypeOf((*absentValue)(nil)).Elem()
+
+// holdsAbsent reports whether values of the type may contain a struct
+// field that can be absent.
+func holdsAbsent(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Implements(jsonMarshalerType) || implementsMarshalerTo(t) || t.Implements(textMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Array, reflect.Slice:
+		return holdsAbsent(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range typeFields(t) {
+			if f.typ.Implements(absentValueType) || holdsAbsent(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// removeAbsent removes the struct fields that are absent from the node
+// tree generated for the value.
+func removeAbsent(n *yaml.Node, v reflect.Value) {
+	walkValue(v, func(path []string, v reflect.Value, f *field) bool {
+		if f == nil || len(path) == 0 || !v.Type().Implements(absentValueType) {
+			return true
+		}
+		if !v.Interface().(absentValue).absent() {
+			return true
+		}
+		parent := n
+		for _, p := range path[:len(path)-1] {
+			if parent, _ = childNode(parent, p); parent == nil {
+				return false
+			}
+		}
+		if parent.Kind == yaml.DocumentNode && len(parent.Content) > 0 {
+			parent = parent.Content[0]
+		}
+		key := path[len(path)-1]
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == key {
+				parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+				break
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,65 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+type nullablePatch struct {
+	Name    Nullable[string]            `json:"name"`
+	Count   Nullable[int]               `json:"count"`
+	Labels  Nullable[map[string]string] `json:"labels"`
+	Nested  *nullablePatch              `json:"nested,omitempty"`
+	Default string                      `json:"default"`
+}
+
+func TestNullableDecode(t *testing.T) {
+	for name, decode := range map[string]func([]byte, interface{}) error{
+		"Unmarshal": func(y []byte, o interface{}) error { return Unmarshal(y, o) },
+		"UnmarshalWithOptions": func(y []byte, o interface{}) error {
+			return UnmarshalWithOptions(y, o)
+		},
+	} {
+		var p nullablePatch
+		if err := decode([]byte("name: web\ncount: null\nnested: {labels: {a: b}}\n"), &p); err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+		want := nullablePatch{
+			Name:   NewNullable("web"),
+			Count:  Null[int](),
+			Nested: &nullablePatch{Labels: NewNullable(map[string]string{"a": "b"})},
+		}
+		if !reflect.DeepEqual(p, want) {
+			t.Errorf("%s() = %+v; want %+v", name, p, want)
+		}
+	}
+
+	var p nullablePatch
+	if err := Unmarshal([]byte("count: x\n"), &p); err == nil {
+		t.Errorf("Unmarshal() = nil; want type error")
+	}
+}
+
+func TestNullableMarshal(t *testing.T) {
+	p := nullablePatch{
+		Name:   NewNullable("web"),
+		Count:  Null[int](),
+		Nested: &nullablePatch{Count: NewNullable(0)},
+	}
+	y, err := Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := "count: null\ndefault: \"\"\nname: web\nnested:\n    count: 0\n    default: \"\"\n"
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+
+	var back nullablePatch
+	if err := Unmarshal(y, &back); err != nil || !reflect.DeepEqual(back, p) {
+		t.Errorf("Unmarshal() = %+v, %v; want %+v", back, err, p)
+	}
+	if !(Nullable[int]{}).IsZero() || NewNullable(0).IsZero() {
+		t.Errorf("IsZero() does not report absence")
+	}
+}
@@ -0,0 +1,148 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Number holds the text of a numeric scalar exactly as written in the
+// source, like json.Number, so that values beyond the range or precision
+// of int64 and float64 are not lost. It is written back out verbatim.
+type Number string
+
+var rawNumberType = reflect.TypeOf(Number(""))
+
+// String provides the text of the number.
+func (n Number) String() string {
+	return string(n)
+}
+
+// plain removes the underscores allowed in YAML numbers.
+func (n Number) plain() string {
+	return strings.ReplaceAll(string(n), "_", "")
+}
+
+// Int64 provides the number as an int64, accepting the hexadecimal,
+// octal and binary forms of YAML.
+func (n Number) Int64() (int64, error) {
+	return strconv.ParseInt(n.plain(), 0, 64)
+}
+
+// Float64 provides the number as a float64.
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(n.plain(), 64)
+}
+
+// BigInt provides the number as an arbitrary precision integer.
+func (n Number) BigInt() (*big.Int, error) {
+	i, ok := new(big.Int).SetString(n.plain(), 0)
+	if !ok {
+		return nil, fmt.Errorf("yaml: invalid integer %q", string(n))
+	}
+	return i, nil
+}
+
+// MarshalJSON writes the number as it is when it is a valid JSON number,
+// and as a string otherwise. Marshal writes it verbatim either way.
+func (n Number) MarshalJSON() ([]byte, error) {
+	if n == "" {
+		return []byte("0"), nil
+	}
+	if jsonNumberText.MatchString(string(n)) {
+		return []byte(n), nil
+	}
+	return json.Marshal(string(n))
+}
+
+// yamlNumberText matches the integers and floats of YAML, whether or not
+// they fit in an int64 or float64.
+var yamlNumberText = regexp.MustCompile(`^[-+]?(0b[01_]+|0o[0-7_]+|0x[0-9a-fA-F_]+|[0-9][0-9_]*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|\.[0-9][0-9_]*([eE][-+]?[0-9]+)?|\.(inf|Inf|INF))$|^\.(nan|NaN|NAN)$`)
+
+// UnmarshalJSON accepts a number, or a string holding one. As with
+// json.Number, null leaves the number unchanged.
+func (n *Number) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+	}
+	if !yamlNumberText.MatchString(s) {
+		return fmt.Errorf("yaml: invalid number %q", s)
+	}
+	*n = Number(s)
+	return nil
+}
+
+// numberTag provides the tag for numbers decoded into a Number, so that
+// they keep their text.
+func numberTag(n *yaml.Node, t reflect.Type) string {
+	if t != rawNumberType {
+		return ""
+	}
+	switch n.ShortTag() {
+	case "!!int", "!!float":
+		return "!!str"
+	}
+	return ""
+}
+
+// holdsType reports whether values of the type t may contain a value of
+// the type target.
+func holdsType(t, target reflect.Type, seen map[reflect.Type]bool) bool {
+	if t == target {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Implements(jsonMarshalerType) || implementsMarshalerTo(t) || t.Implements(textMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Array, reflect.Slice:
+		return holdsType(t.Elem(), target, seen)
+	case reflect.Struct:
+		for _, f := range typeFields(t) {
+			if holdsType(f.typ, target, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyNumbers writes the Number values in the node tree generated for
+// the value verbatim.
+func applyNumbers(n *yaml.Node, v reflect.Value) {
+	walkValue(v, func(path []string, v reflect.Value, f *field) bool {
+		if v.Type() != rawNumberType || f != nil && f.quoted {
+			return true
+		}
+		target := n
+		for _, p := range path {
+			if target, _ = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		if target.Kind == yaml.DocumentNode && len(target.Content) > 0 {
+			target = target.Content[0]
+		}
+		if s := v.String(); target.Kind == yaml.ScalarNode && s != "" {
+			target.Tag, target.Style, target.Value = "", 0, s
+			target.Tag = target.ShortTag()
+		}
+		return false
+	})
+}
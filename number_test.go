@@ -0,0 +1,108 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+type numberTarget struct {
+	ID     Number            `json:"id"`
+	Price  Number            `json:"price"`
+	Mask   Number            `json:"mask"`
+	Values []Number          `json:"values"`
+	ByName map[string]Number `json:"byName"`
+	Count  int               `json:"count"`
+}
+
+func TestNumberDecode(t *testing.T) {
+	y := "id: 123456789012345678901234567890\nprice: 19.90\nmask: 0x_FF\nvalues: [1e3, -0.0]\nbyName: {a: 007}\ncount: 2\n"
+	want := numberTarget{
+		ID:     "123456789012345678901234567890",
+		Price:  "19.90",
+		Mask:   "0x_FF",
+		Values: []Number{"1e3", "-0.0"},
+		ByName: map[string]Number{"a": "007"},
+		Count:  2,
+	}
+	for name, decode := range map[string]func([]byte, interface{}) error{
+		"Unmarshal":            func(y []byte, o interface{}) error { return Unmarshal(y, o) },
+		"UnmarshalWithOptions": func(y []byte, o interface{}) error { return UnmarshalWithOptions(y, o) },
+	} {
+		var s numberTarget
+		if err := decode([]byte(y), &s); err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+		if !reflect.DeepEqual(s, want) {
+			t.Errorf("%s() = %+v; want %+v", name, s, want)
+		}
+	}
+
+	var s numberTarget
+	if err := Unmarshal([]byte("id: abc\n"), &s); err == nil {
+		t.Errorf("Unmarshal() = nil; want error for a string")
+	}
+}
+
+func TestNumberAccessors(t *testing.T) {
+	if i, err := Number("0x_FF").Int64(); err != nil || i != 255 {
+		t.Errorf("Int64() = %d, %v; want 255", i, err)
+	}
+	if f, err := Number("19.90").Float64(); err != nil || f != 19.9 {
+		t.Errorf("Float64() = %v, %v; want 19.9", f, err)
+	}
+	if b, err := Number("123456789012345678901234567890").BigInt(); err != nil || b.String() != "123456789012345678901234567890" {
+		t.Errorf("BigInt() = %v, %v", b, err)
+	}
+	if _, err := Number("1.5").BigInt(); err == nil {
+		t.Errorf("BigInt() = nil error; want error for a float")
+	}
+}
+
+func TestNumberMarshal(t *testing.T) {
+	s := numberTarget{ID: "123456789012345678901234567890", Price: "19.90", Mask: "0xFF", Values: []Number{"1e3"}}
+	y, err := Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := "byName: null\ncount: 0\nid: 123456789012345678901234567890\nmask: 0xFF\nprice: 19.90\nvalues:\n    - 1e3\n"
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+}
+
+func TestNumberBeyondFloat64(t *testing.T) {
+	type target struct {
+		N      Number            `json:"n"`
+		Ptr    *Number           `json:"ptr"`
+		List   []Number          `json:"list"`
+		Ptrs   []*Number         `json:"ptrs"`
+		Nested map[string]Number `json:"nested"`
+		None   Number            `json:"none"`
+	}
+	y := "n: 1e400\nptr: 0.10\nlist: [1.50, 2e999]\nptrs: [-1e-400, 010]\nnested: {a: 1.0}\nnone: ~\n"
+	for name, decode := range map[string]func([]byte, interface{}) error{
+		"Unmarshal":            func(y []byte, o interface{}) error { return Unmarshal(y, o) },
+		"UnmarshalWithOptions": func(y []byte, o interface{}) error { return UnmarshalWithOptions(y, o) },
+	} {
+		s := target{None: "5"}
+		if err := decode([]byte(y), &s); err != nil {
+			t.Fatalf("%s() = %v", name, err)
+		}
+		if s.N != "1e400" || s.Ptr == nil || *s.Ptr != "0.10" || s.None != "5" {
+			t.Errorf("%s() = %+v", name, s)
+		}
+		if !reflect.DeepEqual(s.List, []Number{"1.50", "2e999"}) || !reflect.DeepEqual(s.Nested, map[string]Number{"a": "1.0"}) {
+			t.Errorf("%s() = %+v", name, s)
+		}
+		if len(s.Ptrs) != 2 || *s.Ptrs[0] != "-1e-400" || *s.Ptrs[1] != "010" {
+			t.Errorf("%s() = %+v", name, s.Ptrs)
+		}
+	}
+
+	var n Number
+	for _, s := range []string{`"12abc"`, `"1.2.3"`, `"_1"`, `true`} {
+		if err := n.UnmarshalJSON([]byte(s)); err == nil {
+			t.Errorf("UnmarshalJSON(%s) = nil; want error", s)
+		}
+	}
+}
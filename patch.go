@@ -0,0 +1,278 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to the YAML document and
+// returns the result. The patch may be provided as either JSON or YAML.
+// Keys set to null in the patch are removed, mappings are merged
+// recursively, and any other value replaces the original. Comments, key
+// order, and indentation of the untouched parts of the document are
+// preserved.
+func MergePatch(y, patch []byte) ([]byte, error) {
+	doc, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	p, err := parseDocument(patch)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing patch: %v", err)
+	}
+	if len(p.Content) == 0 {
+		return encodeDocument(doc, detectIndent(y))
+	}
+	// Normalize the patch so that JSON styles don't leak into the output.
+	pv, err := nodeToJSONable(p)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing patch: %v", err)
+	}
+	pn, err := StructToNode(pv)
+	if err != nil {
+		return nil, err
+	}
+	var target *yaml.Node
+	if len(doc.Content) > 0 {
+		target = doc.Content[0]
+	}
+	doc.Content = []*yaml.Node{mergePatchNode(target, pn)}
+	return encodeDocument(doc, detectIndent(y))
+}
+
+// mergePatchNode applies the patch to the target node following RFC 7386,
+// returning the resulting node. The target may be nil.
+func mergePatchNode(target, patch *yaml.Node) *yaml.Node {
+	patch = resolveAlias(patch)
+	if patch.Kind != yaml.MappingNode {
+		n := copyNode(patch)
+		if target != nil {
+			replaceNode(target, n)
+			return target
+		}
+		return n
+	}
+	if target != nil && target.Kind == yaml.AliasNode {
+		replaceNode(target, copyNode(resolveAlias(target)))
+	}
+	if target == nil || target.Kind != yaml.MappingNode {
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if target != nil {
+			replaceNode(target, n)
+			n = target
+		}
+		target = n
+	}
+	for i := 0; i+1 < len(patch.Content); i += 2 {
+		k, v := patch.Content[i], resolveAlias(patch.Content[i+1])
+		idx := -1
+		for j := 0; j+1 < len(target.Content); j += 2 {
+			if target.Content[j].Value == k.Value {
+				idx = j
+				break
+			}
+		}
+		switch {
+		case isNullNode(v):
+			if idx >= 0 {
+				target.Content = append(target.Content[:idx], target.Content[idx+2:]...)
+			}
+		case idx >= 0:
+			target.Content[idx+1] = mergePatchNode(target.Content[idx+1], v)
+		default:
+			target.Content = append(target.Content, copyNode(k), mergePatchNode(nil, v))
+		}
+	}
+	return target
+}
+
+// isNullNode returns true if the node represents a null value.
+func isNullNode(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
+
+// PatchOperation is a single operation of a JSON Patch (RFC 6902).
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON ensures the value is always included for operations that
+// require it, even when null.
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	type plain PatchOperation
+	switch o.Op {
+	case "add", "replace", "test":
+		return json.Marshal(struct {
+			plain
+			Value interface{} `json:"value"`
+		}{plain(o), o.Value})
+	}
+	return json.Marshal(plain(o))
+}
+
+// JSONPatch applies the operations of a JSON Patch (RFC 6902), provided as
+// either JSON or YAML, to the YAML document and returns the result. All
+// operations are supported, with paths defined as JSON Pointers. If any
+// operation fails, including "test", an error is returned and no changes
+// are made. Comments, key order, and indentation are preserved for the
+// rest of the document.
+func JSONPatch(y, patch []byte) ([]byte, error) {
+	var ops []PatchOperation
+	// Numbers are kept as written, as float64 would round large integers.
+	if err := Unmarshal(patch, &ops, UseNumber); err != nil {
+		return nil, fmt.Errorf("error parsing patch: %v", err)
+	}
+	doc, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	if err := applyPatch(doc, ops); err != nil {
+		return nil, err
+	}
+	return encodeDocument(doc, detectIndent(y))
+}
+
+// applyPatch applies the operations to the document node in order.
+func applyPatch(doc *yaml.Node, ops []PatchOperation) error {
+	for i, op := range ops {
+		if err := applyPatchOperation(doc, op); err != nil {
+			return fmt.Errorf("error applying patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+func applyPatchOperation(doc *yaml.Node, op PatchOperation) error { //nolint:gocyclo
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add":
+		n, err := StructToNode(op.Value)
+		if err != nil {
+			return err
+		}
+		return addPointer(doc, path, n)
+	case "remove":
+		_, err := removePointer(doc, path)
+		return err
+	case "replace":
+		n, err := StructToNode(op.Value)
+		if err != nil {
+			return err
+		}
+		if _, _, err := lookupPointer(doc, path); err != nil {
+			return err
+		}
+		return setPointer(doc, path, n)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		var n *yaml.Node
+		if op.Op == "move" {
+			if len(from) < len(path) && equalTokens(from, path[:len(from)]) {
+				return fmt.Errorf("cannot move %s into one of its children", op.From)
+			}
+			n, err = removePointer(doc, from)
+		} else {
+			n, _, err = lookupPointer(doc, from)
+			n = copyNode(n)
+		}
+		if err != nil {
+			return err
+		}
+		return addPointer(doc, path, n)
+	case "test":
+		n, _, err := lookupPointer(doc, path)
+		if err != nil {
+			return err
+		}
+		v, err := StructToNode(op.Value)
+		if err != nil {
+			return err
+		}
+		if !nodesEqual(n, v) {
+			return fmt.Errorf("test failed: value does not match")
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// equalTokens reports whether the two lists of reference tokens are the
+// same.
+func equalTokens(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// addPointer adds the node to the location referenced by the tokens,
+// inserting into sequences rather than replacing existing items.
+func addPointer(doc *yaml.Node, tokens []string, n *yaml.Node) error {
+	if len(tokens) == 0 {
+		return setPointer(doc, tokens, n)
+	}
+	parent, _, err := lookupPointer(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	parent = resolveAlias(parent)
+	if parent.Kind == yaml.SequenceNode {
+		last := tokens[len(tokens)-1]
+		if last == "-" {
+			parent.Content = append(parent.Content, n)
+			return nil
+		}
+		i, ok := arrayIndex(last)
+		if !ok || i > len(parent.Content) {
+			return fmt.Errorf("%w: %s", ErrPathNotFound, formatPointer(tokens))
+		}
+		parent.Content = append(parent.Content, nil)
+		copy(parent.Content[i+1:], parent.Content[i:])
+		parent.Content[i] = n
+		return nil
+	}
+	return setPointer(doc, tokens, n)
+}
+
+// removePointer removes the node at the location referenced by the tokens
+// and returns it.
+func removePointer(doc *yaml.Node, tokens []string) (*yaml.Node, error) {
+	n, _, err := lookupPointer(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		doc.Content = nil
+		return n, nil
+	}
+	parent, _, _ := lookupPointer(doc, tokens[:len(tokens)-1])
+	parent = resolveAlias(parent)
+	for i, c := range parent.Content {
+		if c != n {
+			continue
+		}
+		if parent.Kind == yaml.MappingNode {
+			parent.Content = append(parent.Content[:i-1], parent.Content[i+1:]...)
+		} else {
+			parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+		}
+		break
+	}
+	return n, nil
+}
@@ -0,0 +1,198 @@
+package yaml
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	src := `# service
+name: web # keep
+spec:
+  replicas: 1
+  ports: [80]
+  debug: true
+`
+	for _, tc := range []struct {
+		patch string
+		want  string
+	}{
+		{
+			`{"spec": {"replicas": 3, "debug": null}}`,
+			"# service\nname: web # keep\nspec:\n  replicas: 3\n  ports: [80]\n",
+		},
+		{
+			"spec:\n  ports: [80, 443]\nmetadata:\n  team: core\n",
+			"# service\nname: web # keep\nspec:\n  replicas: 1\n  ports: [80, 443]\n  debug: true\nmetadata:\n  team: core\n",
+		},
+		{
+			`{"name": {"first": "web"}}`,
+			"# service\nname:\n  first: web\nspec:\n  replicas: 1\n  ports: [80]\n  debug: true\n",
+		},
+		{
+			`["replaced"]`,
+			"- replaced\n",
+		},
+		{
+			"",
+			"# service\nname: web # keep\nspec:\n  replicas: 1\n  ports: [80]\n  debug: true\n",
+		},
+	} {
+		got, err := MergePatch([]byte(src), []byte(tc.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%q) = %v", tc.patch, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("MergePatch(%q) = %q; want %q", tc.patch, got, tc.want)
+		}
+	}
+}
+
+// TestMergePatchRFC runs a selection of the examples from RFC 7386
+// Appendix A.
+func TestMergePatchRFC(t *testing.T) {
+	for _, tc := range []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		y, err := MergePatch([]byte(tc.target), []byte(tc.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s) = %v", tc.target, tc.patch, err)
+			continue
+		}
+		j, err := YAMLToJSON(y)
+		if err != nil {
+			t.Errorf("YAMLToJSON(%q) = %v", y, err)
+			continue
+		}
+		if string(j) != tc.want {
+			t.Errorf("MergePatch(%s, %s) = %s; want %s", tc.target, tc.patch, j, tc.want)
+		}
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	src := `# service
+name: web # keep
+spec:
+  ports:
+    - 80
+    - 443
+`
+	for _, tc := range []struct {
+		patch string
+		want  string
+	}{
+		{
+			`[{"op": "add", "path": "/spec/ports/1", "value": 8080}]`,
+			"# service\nname: web # keep\nspec:\n  ports:\n    - 80\n    - 8080\n    - 443\n",
+		},
+		{
+			"- op: replace\n  path: /name\n  value: api\n- op: remove\n  path: /spec/ports/0\n",
+			"# service\nname: api # keep\nspec:\n  ports:\n    - 443\n",
+		},
+		{
+			`[{"op": "copy", "from": "/spec/ports", "path": "/ports"}, {"op": "move", "from": "/name", "path": "/spec/name"}]`,
+			"spec:\n  ports:\n    - 80\n    - 443\n  name: web # keep\nports:\n  - 80\n  - 443\n",
+		},
+		{
+			`[{"op": "test", "path": "/spec/ports/1", "value": 443}, {"op": "add", "path": "/spec/tls", "value": null}]`,
+			"# service\nname: web # keep\nspec:\n  ports:\n    - 80\n    - 443\n  tls: null\n",
+		},
+	} {
+		got, err := JSONPatch([]byte(src), []byte(tc.patch))
+		if err != nil {
+			t.Errorf("JSONPatch(%q) = %v", tc.patch, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("JSONPatch(%q) = %q; want %q", tc.patch, got, tc.want)
+		}
+	}
+
+	for _, patch := range []string{
+		`[{"op": "test", "path": "/name", "value": "api"}]`,
+		`[{"op": "remove", "path": "/missing"}]`,
+		`[{"op": "replace", "path": "/missing", "value": 1}]`,
+		`[{"op": "add", "path": "/spec/ports/5", "value": 1}]`,
+		`[{"op": "move", "from": "/spec", "path": "/spec/inner"}]`,
+		`[{"op": "unknown", "path": "/name"}]`,
+		`[{"op": "remove", "path": "/spec/ports/01"}]`,
+		`[{"op": "remove", "path": "/spec/ports/+1"}]`,
+		`[{"op": "add", "path": "/spec/ports/-0", "value": 1}]`,
+		`[{"op": "test", "path": "/spec/ports/1", "value": "443"}]`,
+	} {
+		if _, err := JSONPatch([]byte(src), []byte(patch)); err == nil {
+			t.Errorf("JSONPatch(%q) = nil; want error", patch)
+		}
+	}
+}
+
+func TestJSONPatchMoveShortPath(t *testing.T) {
+	// The path is shorter in bytes than the source, but has more tokens.
+	patch := `[{"op": "move", "from": "/longer", "path": "/a/b"}]`
+	got, err := JSONPatch([]byte("a: {}\nlonger: 1\n"), []byte(patch))
+	if err != nil {
+		t.Fatalf("JSONPatch() = %v", err)
+	}
+	if want := "a: {b: 1}\n"; string(got) != want {
+		t.Errorf("JSONPatch() = %q; want %q", got, want)
+	}
+}
+
+func TestJSONPatchTestValues(t *testing.T) {
+	src := "a: 1.0\nb: {x: 'y'} # comment\nc: [1, 2.50]\n"
+	patch := `[
+		{"op": "test", "path": "/a", "value": 1},
+		{"op": "test", "path": "/b", "value": {"x": "y"}},
+		{"op": "test", "path": "/c", "value": [1.0, 2.5]}
+	]`
+	if _, err := JSONPatch([]byte(src), []byte(patch)); err != nil {
+		t.Errorf("JSONPatch() = %v", err)
+	}
+}
+
+func TestJSONPatchLargeIntegers(t *testing.T) {
+	patch := `[
+		{"op": "add", "path": "/id", "value": 12345678901234567891},
+		{"op": "replace", "path": "/owner", "value": {"id": 9007199254740993}}
+	]`
+	got, err := JSONPatch([]byte("owner: {}\n"), []byte(patch))
+	if err != nil {
+		t.Fatalf("JSONPatch() = %v", err)
+	}
+	if want := "owner: {id: 9007199254740993}\nid: 12345678901234567891\n"; string(got) != want {
+		t.Errorf("JSONPatch() = %q; want %q", got, want)
+	}
+
+	test := `[{"op": "test", "path": "/owner/id", "value": 9007199254740992}]`
+	if _, err := JSONPatch(got, []byte(test)); err == nil {
+		t.Errorf("JSONPatch(%s) = nil; want test failure", test)
+	}
+}
+
+func TestPatchOperationMarshal(t *testing.T) {
+	ops := []PatchOperation{
+		{Op: "add", Path: "/a", Value: nil},
+		{Op: "remove", Path: "/b"},
+	}
+	j, err := json.Marshal(ops)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	if want := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"}]`; string(j) != want {
+		t.Errorf("json.Marshal() = %s; want %s", j, want)
+	}
+}
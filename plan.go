@@ -0,0 +1,89 @@
+package yaml
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typePlan holds what the conversions need to know about a type, derived
+// the first time the type is seen rather than on every call.
+type typePlan struct {
+	fields   []field           // struct fields, as found by encoding/json
+	names    map[string]*field // fields by exact name
+	comments bool              // values may hold a CommentedValue
+	formats  bool              // values may hold fields with a format tag
+	absent   bool              // values may hold fields that can be absent
+	numbers  bool              // values may hold a Number
+}
+
+var planCache sync.Map // map[reflect.Type]*typePlan
+
+// cachedTypePlan provides the plan for the type, building it if required.
+func cachedTypePlan(t reflect.Type) *typePlan {
+	if p, ok := planCache.Load(t); ok {
+		return p.(*typePlan)
+	}
+	p := &typePlan{
+		comments: holdsComments(t, make(map[reflect.Type]bool)),
+		formats:  holdsFormats(t, make(map[reflect.Type]bool)),
+		absent:   holdsAbsent(t, make(map[reflect.Type]bool)),
+		numbers:  holdsType(t, rawNumberType, make(map[reflect.Type]bool)),
+	}
+	if t.Kind() == reflect.Struct {
+		p.fields = typeFields(t)
+	}
+	if p.fields == nil {
+		p.fields = []field{}
+	}
+	p.names = make(map[string]*field, len(p.fields))
+	for i := range p.fields {
+		p.names[p.fields[i].name] = &p.fields[i]
+	}
+	actual, _ := planCache.LoadOrStore(t, p)
+	return actual.(*typePlan)
+}
+
+// field finds the field that encoding/json would decode the key into,
+// preferring an exact match over a case-insensitive one.
+func (p *typePlan) field(key string) *field {
+	if f := p.names[key]; f != nil {
+		return f
+	}
+	keyBytes := []byte(key)
+	for i := range p.fields {
+		if ff := &p.fields[i]; ff.equalFold(ff.nameBytes, keyBytes) {
+			return ff
+		}
+	}
+	return nil
+}
+
+// holdsComments reports whether values of the type may contain a
+// CommentedValue that walkValue would find. Interfaces may hold anything.
+func holdsComments(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if t == commentedValueType || t.Kind() == reflect.Ptr && t.Elem() == commentedValueType {
+		return true
+	}
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Implements(jsonMarshalerType) || implementsMarshalerTo(t) || t.Implements(textMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Map, reflect.Array:
+		return holdsComments(t.Elem(), seen)
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8 && holdsComments(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range typeFields(t) {
+			if holdsComments(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
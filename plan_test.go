@@ -0,0 +1,72 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypePlanField(t *testing.T) {
+	type s struct {
+		Name   string `json:"name"`
+		NAME   string `json:"NAME"`
+		Kelvin string `json:"k"`
+		Skip   string `json:"-"`
+	}
+	p := cachedTypePlan(reflect.TypeOf(s{}))
+	tests := []struct {
+		key, want string
+	}{
+		{"name", "name"},
+		{"NAME", "NAME"},
+		{"Name", "name"},
+		{"K", "k"},
+		{"K", "k"},
+		{"Skip", ""},
+		{"-", ""},
+		{"other", ""},
+	}
+	for _, test := range tests {
+		got := ""
+		if f := p.field(test.key); f != nil {
+			got = f.name
+		}
+		if got != test.want {
+			t.Errorf("field(%q) = %q; want %q", test.key, got, test.want)
+		}
+	}
+	if cachedTypePlan(reflect.TypeOf(s{})) != p {
+		t.Errorf("cachedTypePlan() built the plan twice")
+	}
+}
+
+func TestTypePlanComments(t *testing.T) {
+	type list struct {
+		Next *list `json:"next"`
+		Name string
+	}
+	type commented struct {
+		Items []map[string]*CommentedValue
+	}
+	tests := []struct {
+		v    interface{}
+		want bool
+	}{
+		{1, false},
+		{time.Time{}, false},
+		{list{}, false},
+		{[]byte{}, false},
+		{commented{}, true},
+		{CommentedValue{}, true},
+		{[]interface{}{}, true},
+		{struct{ Any interface{} }{}, true},
+		{struct {
+			Hidden CommentedValue `json:"-"`
+		}{}, false},
+	}
+	for _, test := range tests {
+		if got := cachedTypePlan(reflect.TypeOf(test.v)).comments; got != test.want {
+			t.Errorf("comments for %T = %v; want %v", test.v, got, test.want)
+		}
+	}
+}
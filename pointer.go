@@ -0,0 +1,198 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrPathNotFound is returned when a path or pointer does not match any
+// value in the document.
+var ErrPathNotFound = errors.New("path not found")
+
+// GetPath returns the value found in the YAML document at the location
+// defined by the JSON Pointer (RFC 6901), for example "/spec/replicas". The
+// value is returned in the same form YAMLToJSON would produce it, so maps
+// will always have string keys.
+func GetPath(y []byte, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	n, _, err := lookupPointer(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return nodeToJSONable(n)
+}
+
+// SetPath replaces, or adds, the value at the location defined by the JSON
+// Pointer and returns the updated YAML document. The value is converted
+// using the same rules as Marshal. As with SetValue, the new value is
+// spliced into the original bytes wherever possible, so that the rest of
+// the document, including comments, blank lines, quoting and indentation,
+// is left untouched. Otherwise the document is re-emitted, preserving
+// comments, key order, anchors, quoting styles and indentation as far as
+// go-yaml allows. As with JSON Patch, the "-" token may be used to append
+// an item to the end of a sequence.
+func SetPath(y []byte, pointer string, value interface{}) ([]byte, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	n, err := StructToNode(value)
+	if err != nil {
+		return nil, err
+	}
+	indent := detectIndent(y)
+	spliced, ok := splicePointer(y, doc, tokens, n, indent)
+	if err := setPointer(doc, tokens, n); err != nil {
+		return nil, err
+	}
+	if ok {
+		// Only keep the splice when it reads back as the updated tree.
+		if sdoc, err := parseDocument(spliced); err == nil && nodesEqual(sdoc, doc) {
+			return spliced, nil
+		}
+	}
+	return encodeDocument(doc, indent)
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return []string{}, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+	return tokens, nil
+}
+
+// formatPointer builds a JSON Pointer from the reference tokens.
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+	}
+	return b.String()
+}
+
+// lookupPointer finds the node referenced by the tokens, returning it along
+// with its key node when the parent is a mapping.
+func lookupPointer(doc *yaml.Node, tokens []string) (*yaml.Node, *yaml.Node, error) {
+	if len(doc.Content) == 0 {
+		if len(tokens) == 0 {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}, nil, nil
+		}
+		return nil, nil, fmt.Errorf("%w: %s", ErrPathNotFound, formatPointer(tokens))
+	}
+	n, key := doc.Content[0], (*yaml.Node)(nil)
+	for i, t := range tokens {
+		n, key = childNode(resolveAlias(n), t)
+		if n == nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrPathNotFound, formatPointer(tokens[:i+1]))
+		}
+	}
+	return n, key, nil
+}
+
+// setPointer places the new node at the location referenced by the tokens.
+func setPointer(doc *yaml.Node, tokens []string, n *yaml.Node) error {
+	if len(tokens) == 0 {
+		if len(doc.Content) > 0 {
+			replaceNode(doc.Content[0], n)
+		} else {
+			doc.Content = []*yaml.Node{n}
+		}
+		return nil
+	}
+	parent, _, err := lookupPointer(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	parent = resolveAlias(parent)
+	last := tokens[len(tokens)-1]
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == last {
+				replaceNode(parent.Content[i+1], n)
+				return nil
+			}
+		}
+		parent.Content = append(parent.Content, newScalarNode(last), n)
+		return nil
+	case yaml.SequenceNode:
+		if last == "-" {
+			parent.Content = append(parent.Content, n)
+			return nil
+		}
+		i, ok := arrayIndex(last)
+		if !ok || i >= len(parent.Content) {
+			return fmt.Errorf("%w: %s", ErrPathNotFound, formatPointer(tokens))
+		}
+		replaceNode(parent.Content[i], n)
+		return nil
+	}
+	return fmt.Errorf("cannot set %s: parent is not a mapping or sequence", formatPointer(tokens))
+}
+
+// arrayIndex parses the reference token as an array index, which RFC 6901
+// restricts to "0" or a number without leading zeros or signs.
+func arrayIndex(t string) (int, bool) {
+	if t == "" || t[0] == '0' && len(t) > 1 {
+		return 0, false
+	}
+	for i := 0; i < len(t); i++ {
+		if t[i] < '0' || t[i] > '9' {
+			return 0, false
+		}
+	}
+	i, err := strconv.Atoi(t)
+	return i, err == nil
+}
+
+// replaceNode overwrites the old node with the new one in place, carrying
+// over comments, the anchor, and the quoting style where they still apply.
+func replaceNode(old, n *yaml.Node) {
+	if old.Kind == yaml.ScalarNode && n.Kind == yaml.ScalarNode && n.Style == 0 && n.Tag == "!!str" {
+		n.Style = old.Style
+	}
+	if old.Kind == n.Kind && old.Kind != yaml.ScalarNode {
+		n.Style |= old.Style & yaml.FlowStyle
+	}
+	n.Anchor = old.Anchor
+	n.HeadComment = old.HeadComment
+	if old.Kind != yaml.ScalarNode || n.Kind == yaml.ScalarNode {
+		// Line comments on scalars can't be placed on collections.
+		n.LineComment = old.LineComment
+	}
+	n.FootComment = old.FootComment
+	*old = *n
+}
+
+// nodeToJSONable decodes the node into a JSON compatible value.
+func nodeToJSONable(n *yaml.Node) (interface{}, error) {
+	yamlObj, err := decodeNodeObject(n)
+	if err != nil {
+		return nil, err
+	}
+	return convertToJSONableObject(yamlObj, nil)
+}
@@ -0,0 +1,141 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pointerDoc = `# Deployment
+kind: Deployment
+spec:
+  replicas: 2 # keep low
+  name: 'web'
+  ports:
+    - 80
+    - 443
+`
+
+func TestGetPath(t *testing.T) {
+	for _, tc := range []struct {
+		pointer string
+		want    interface{}
+	}{
+		{"/kind", "Deployment"},
+		{"/spec/replicas", 2},
+		{"/spec/ports/1", 443},
+		{"/spec/ports", []interface{}{80, 443}},
+	} {
+		got, err := GetPath([]byte(pointerDoc), tc.pointer)
+		if err != nil {
+			t.Errorf("GetPath(%q) = %v", tc.pointer, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("GetPath(%q) = %#v; want %#v", tc.pointer, got, tc.want)
+		}
+	}
+
+	if _, err := GetPath([]byte(pointerDoc), "/spec/missing"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("GetPath(missing) = %v; want ErrPathNotFound", err)
+	}
+	if _, err := GetPath([]byte(pointerDoc), "spec"); err == nil {
+		t.Errorf("GetPath(invalid) = nil; want error")
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	for _, tc := range []struct {
+		pointer string
+		value   interface{}
+		want    string
+	}{
+		{
+			"/spec/replicas", 5,
+			"# Deployment\nkind: Deployment\nspec:\n  replicas: 5 # keep low\n  name: 'web'\n  ports:\n    - 80\n    - 443\n",
+		},
+		{
+			"/spec/name", "api",
+			"# Deployment\nkind: Deployment\nspec:\n  replicas: 2 # keep low\n  name: 'api'\n  ports:\n    - 80\n    - 443\n",
+		},
+		{
+			"/spec/ports/-", 8080,
+			"# Deployment\nkind: Deployment\nspec:\n  replicas: 2 # keep low\n  name: 'web'\n  ports:\n    - 80\n    - 443\n    - 8080\n",
+		},
+		{
+			"/metadata", map[string]string{"name": "web"},
+			"# Deployment\nkind: Deployment\nspec:\n  replicas: 2 # keep low\n  name: 'web'\n  ports:\n    - 80\n    - 443\nmetadata:\n  name: web\n",
+		},
+	} {
+		got, err := SetPath([]byte(pointerDoc), tc.pointer, tc.value)
+		if err != nil {
+			t.Errorf("SetPath(%q) = %v", tc.pointer, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("SetPath(%q) = %q; want %q", tc.pointer, got, tc.want)
+		}
+	}
+
+	if _, err := SetPath([]byte(pointerDoc), "/spec/missing/name", 1); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("SetPath(missing parent) = %v; want ErrPathNotFound", err)
+	}
+}
+
+func TestSetPathSplice(t *testing.T) {
+	src := `# top comment
+
+kind:   Deployment   # spaced
+metadata:
+  name: "web"
+
+
+  labels: {app: web}
+spec:
+  containers:
+  - name: app
+    image: 'nginx'   # image
+
+  - name: sidecar
+    ports: [80]
+  # trailing comment
+tail: yes
+`
+	for _, tc := range []struct {
+		pointer string
+		value   interface{}
+		old     []string // lines of src that change
+		new     []string // lines that replace them
+	}{
+		{"/spec/containers/0/image", "nginx:1.25", []string{"    image: 'nginx'   # image"}, []string{"    image: 'nginx:1.25'   # image"}},
+		{"/spec/containers/1/ports", []int{80, 443}, []string{"    ports: [80]"}, []string{"    ports: [80, 443]"}},
+		{"/spec/containers/0", map[string]string{"name": "new"}, []string{"  - name: app", "    image: 'nginx'   # image"}, []string{"  - name: new"}},
+		{"/spec/containers/-", map[string]string{"name": "x"}, []string{"    ports: [80]"}, []string{"    ports: [80]", "  - name: x"}},
+		{"/metadata/annotations", map[string]string{"a": "b"}, []string{"  labels: {app: web}"}, []string{"  labels: {app: web}", "  annotations:", "    a: b"}},
+		{"/tail", false, []string{"tail: yes"}, []string{"tail: false"}},
+	} {
+		got, err := SetPath([]byte(src), tc.pointer, tc.value)
+		if err != nil {
+			t.Errorf("SetPath(%q) = %v", tc.pointer, err)
+			continue
+		}
+		want := strings.Replace(src, strings.Join(tc.old, "\n")+"\n", strings.Join(tc.new, "\n")+"\n", 1)
+		if string(got) != want {
+			t.Errorf("SetPath(%q) = %q; want %q", tc.pointer, got, want)
+		}
+	}
+}
+
+func TestPointerEscaping(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/c~0d")
+	if err != nil {
+		t.Fatalf("parsePointer() = %v", err)
+	}
+	if want := []string{"a/b", "c~d"}; !reflect.DeepEqual(tokens, want) {
+		t.Errorf("parsePointer() = %q; want %q", tokens, want)
+	}
+	if p := formatPointer(tokens); p != "/a~1b/c~0d" {
+		t.Errorf("formatPointer() = %q", p)
+	}
+}
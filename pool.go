@@ -0,0 +1,46 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBuffer is the capacity above which buffers are dropped instead of
+// being returned to the pool, so that converting a single large document
+// doesn't keep its memory alive.
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers used for the intermediate JSON, which are
+// only needed for the duration of a single call.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
+
+// encodeJSON writes the same JSON as json.Marshal to the buffer.
+func encodeJSON(b *bytes.Buffer, v interface{}) error {
+	if err := json.NewEncoder(b).Encode(v); err != nil {
+		return err
+	}
+	// Drop the new line added by the encoder.
+	b.Truncate(b.Len() - 1)
+	return nil
+}
+
+// directPool holds decoders for the direct conversion, keeping the memory
+// used to track the path between calls.
+var directPool = sync.Pool{
+	New: func() interface{} { return new(directDecoder) },
+}
@@ -0,0 +1,39 @@
+package yaml
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	for _, v := range []interface{}{
+		nil,
+		"<a & b>",
+		map[string]interface{}{"b": []interface{}{1.5, true}, "a": "x"},
+	} {
+		want, _ := json.Marshal(v)
+		b := getBuffer()
+		if err := encodeJSON(b, v); err != nil || b.String() != string(want) {
+			t.Errorf("encodeJSON(%v) = %q, %v; want %q", v, b.String(), err, want)
+		}
+		putBuffer(b)
+	}
+	b := getBuffer()
+	if err := encodeJSON(b, make(chan int)); err == nil {
+		t.Errorf("encodeJSON(chan) = nil; want error")
+	}
+	putBuffer(b)
+}
+
+func TestPooledBuffersNotShared(t *testing.T) {
+	first, err := YAMLToJSONWithOptions([]byte("a: 1\n"))
+	if err != nil {
+		t.Fatalf("YAMLToJSONWithOptions() = %v", err)
+	}
+	if _, err := YAMLToJSONWithOptions([]byte("b: 2\n")); err != nil {
+		t.Fatalf("YAMLToJSONWithOptions() = %v", err)
+	}
+	if string(first) != `{"a":1}` {
+		t.Errorf("YAMLToJSONWithOptions() = %s after reuse; want %s", first, `{"a":1}`)
+	}
+}
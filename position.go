@@ -0,0 +1,130 @@
+package yaml
+
+import (
+	"reflect"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PositionSetter is implemented by types that want to know where their
+// value was defined in the YAML source. SetYAMLPosition is called after
+// decoding with the line and column of the node the value came from.
+type PositionSetter interface {
+	SetYAMLPosition(line, column int)
+}
+
+// Position records the location of a decoded value in the source. Embed it
+// in a struct to capture where each instance of the struct was defined.
+type Position struct {
+	Line   int `json:"-"`
+	Column int `json:"-"`
+}
+
+// SetYAMLPosition implements PositionSetter.
+func (p *Position) SetYAMLPosition(line, column int) {
+	p.Line, p.Column = line, column
+}
+
+var positionSetterType = reflect.TypeOf((*PositionSetter)(nil)).Elem()
+
+// setPositions calls SetYAMLPosition on every value decoded from the node
+// that implements PositionSetter.
+func setPositions(n *yaml.Node, o interface{}) {
+	v := reflect.ValueOf(o)
+	if n == nil || !v.IsValid() || !hasPositionSetter(v.Type()) {
+		return
+	}
+	setPositionsAt(n, v)
+}
+
+func setPositionsAt(n *yaml.Node, v reflect.Value) {
+	n = resolveAlias(n)
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return
+		}
+		n = resolveAlias(n.Content[0])
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(positionSetterType) {
+		v.Addr().Interface().(PositionSetter).SetYAMLPosition(n.Line, n.Column)
+	}
+	if unmarshalsJSON(v.Type()) {
+		return
+	}
+
+	switch {
+	case v.Kind() == reflect.Struct && n.Kind == yaml.MappingNode:
+		plan := cachedTypePlan(v.Type())
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			f := plan.field(n.Content[i].Value)
+			if f == nil || !hasPositionSetter(f.typ) {
+				continue
+			}
+			if fv, ok := fieldByIndex(v, f.index); ok {
+				setPositionsAt(n.Content[i+1], fv)
+			}
+		}
+	case v.Kind() == reflect.Map && n.Kind == yaml.MappingNode:
+		// Only values behind pointers can be updated in a map.
+		kt, et := v.Type().Key(), v.Type().Elem()
+		if kt.Kind() != reflect.String || et.Kind() != reflect.Ptr || !hasPositionSetter(et) {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if ev := v.MapIndex(reflect.ValueOf(n.Content[i].Value).Convert(kt)); ev.IsValid() {
+				setPositionsAt(n.Content[i+1], ev)
+			}
+		}
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && n.Kind == yaml.SequenceNode:
+		if !hasPositionSetter(v.Type().Elem()) {
+			return
+		}
+		for i, c := range n.Content {
+			if i >= v.Len() {
+				break
+			}
+			setPositionsAt(c, v.Index(i))
+		}
+	}
+}
+
+var positionSetterCache sync.Map // map[reflect.Type]bool
+
+// hasPositionSetter returns true if values of the type may contain a
+// PositionSetter, so decoding can skip the walk for most types.
+func hasPositionSetter(t reflect.Type) bool {
+	if b, ok := positionSetterCache.Load(t); ok {
+		return b.(bool)
+	}
+	b := containsPositionSetter(t, make(map[reflect.Type]bool))
+	positionSetterCache.Store(t, b)
+	return b
+}
+
+func containsPositionSetter(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	if t.Implements(positionSetterType) || reflect.PtrTo(t).Implements(positionSetterType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsPositionSetter(t.Elem(), seen)
+	case reflect.Struct:
+		for _, f := range cachedTypeFields(t) {
+			if containsPositionSetter(f.typ, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package yaml
+
+import (
+	"bytes"
+	"testing"
+)
+
+type positionContainer struct {
+	Position
+	Name  string                   `json:"name"`
+	Image *positionImage           `json:"image"`
+	Ports []positionPort           `json:"ports"`
+	Env   map[string]*positionEnv  `json:"env"`
+	Other map[string]positionEmpty `json:"other"`
+}
+
+type positionImage struct {
+	Position
+	Repo string `json:"repo"`
+}
+
+type positionPort struct {
+	Position
+	Port int `json:"port"`
+}
+
+type positionEnv struct {
+	Position
+	Value string `json:"value"`
+}
+
+type positionEmpty struct {
+	Position
+}
+
+// positionTag records positions without embedding Position.
+type positionTag struct {
+	Value        string
+	Line, Column int
+}
+
+func (t *positionTag) UnmarshalJSON(b []byte) error {
+	t.Value = string(b)
+	return nil
+}
+
+func (t *positionTag) SetYAMLPosition(line, column int) {
+	t.Line, t.Column = line, column
+}
+
+func TestPositions(t *testing.T) {
+	y := []byte(`# container
+name: web
+image:
+  repo: nginx
+ports:
+  - port: 80
+  - {port: 443}
+env:
+  DEBUG:
+    value: "1"
+other:
+  x: {}
+`)
+	var c positionContainer
+	if err := Unmarshal(y, &c); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	checks := []struct {
+		name         string
+		got          Position
+		line, column int
+	}{
+		{"container", c.Position, 2, 1},
+		{"image", c.Image.Position, 4, 3},
+		{"ports[0]", c.Ports[0].Position, 6, 5},
+		{"ports[1]", c.Ports[1].Position, 7, 5},
+		{"env.DEBUG", c.Env["DEBUG"].Position, 10, 5},
+		{"other.x", c.Other["x"].Position, 0, 0},
+	}
+	for _, ch := range checks {
+		if ch.got.Line != ch.line || ch.got.Column != ch.column {
+			t.Errorf("%s position = %d:%d; want %d:%d", ch.name, ch.got.Line, ch.got.Column, ch.line, ch.column)
+		}
+	}
+
+	// The position fields are not part of the JSON.
+	out, err := Marshal(positionImage{Position{1, 2}, "nginx"})
+	if err != nil || string(out) != "repo: nginx\n" {
+		t.Errorf("Marshal() = %q, %v; want %q", out, err, "repo: nginx\n")
+	}
+}
+
+func TestPositionsDecoder(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte("a: 1\n---\n\n  tags: [x, y]\n")))
+	var first struct{ A positionTag }
+	if err := d.Decode(&first); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if first.A.Line != 1 || first.A.Column != 4 {
+		t.Errorf("Decode() A position = %d:%d; want 1:4", first.A.Line, first.A.Column)
+	}
+	var second struct {
+		Tags []positionTag `json:"tags"`
+	}
+	if err := d.Decode(&second); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if tag := second.Tags[1]; tag.Line != 4 || tag.Column != 13 || tag.Value != `"y"` {
+		t.Errorf("Decode() tags[1] = %+v; want \"y\" at 4:13", tag)
+	}
+}
@@ -0,0 +1,275 @@
+package yaml
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pathSegment is a single step in a path expression.
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// Query evaluates the path expression against the first document in the
+// YAML data and returns every matching value, in the same form YAMLToJSON
+// would produce them. Path expressions use dot and bracket notation with
+// optional wildcards, for example:
+//
+//	spec.containers[*].image
+//	metadata.labels["app.kubernetes.io/name"]
+//	items[0].*
+//
+// A leading "$" or "." is optional. An empty result is not an error.
+func Query(y []byte, path string) ([]interface{}, error) {
+	doc, err := parseDocument(y)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing YAML: %v", err)
+	}
+	nodes, err := QueryNodes(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]interface{}, len(nodes))
+	for i, n := range nodes {
+		if out[i], err = nodeToJSONable(n); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// QueryNodes evaluates the path expression against a node tree, returning
+// the matching nodes so that they may be inspected or modified in place.
+func QueryNodes(n *yaml.Node, path string) ([]*yaml.Node, error) {
+	segs, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		n = n.Content[0]
+	}
+	return matchPath(n, segs), nil
+}
+
+// Query returns the nodes in the document matching the path expression,
+// ready to be modified before writing the document back out.
+func (d *Document) Query(path string) ([]*yaml.Node, error) {
+	return QueryNodes(d.root, path)
+}
+
+// matchPath returns all the nodes under n that match the segments.
+func matchPath(n *yaml.Node, segs []pathSegment) []*yaml.Node {
+	nodes := []*yaml.Node{n}
+	for _, seg := range segs {
+		var next []*yaml.Node
+		for _, n := range nodes {
+			next = append(next, matchSegment(resolveAlias(n), seg)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func matchSegment(n *yaml.Node, seg pathSegment) []*yaml.Node {
+	switch n.Kind {
+	case yaml.MappingNode:
+		var out []*yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if seg.wildcard || (!seg.isIndex && n.Content[i].Value == seg.key) {
+				out = append(out, n.Content[i+1])
+			}
+		}
+		return out
+	case yaml.SequenceNode:
+		if seg.wildcard {
+			return append([]*yaml.Node(nil), n.Content...)
+		}
+		if !seg.isIndex {
+			return nil
+		}
+		i := seg.index
+		if i < 0 {
+			i += len(n.Content)
+		}
+		if i >= 0 && i < len(n.Content) {
+			return []*yaml.Node{n.Content[i]}
+		}
+	}
+	return nil
+}
+
+// parsePath splits a dot and bracket notation path expression into
+// segments.
+func parsePath(path string) ([]pathSegment, error) { //nolint:gocyclo
+	p := strings.TrimPrefix(path, "$")
+	var segs []pathSegment
+	for i := 0; i < len(p); {
+		switch p[i] {
+		case '.':
+			i++
+			if i == len(p) || p[i] == '.' || p[i] == '[' {
+				if i == 1 && i < len(p) {
+					continue // allow a leading "." before brackets
+				}
+				return nil, fmt.Errorf("invalid path %q: empty key at offset %d", path, i)
+			}
+		case '[':
+			end, seg, err := parseBracket(p, i)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path %q: %v", path, err)
+			}
+			segs = append(segs, seg)
+			i = end
+			continue
+		}
+		j := i
+		for j < len(p) && p[j] != '.' && p[j] != '[' {
+			j++
+		}
+		if j == i {
+			continue
+		}
+		key := p[i:j]
+		if key == "*" {
+			segs = append(segs, pathSegment{wildcard: true})
+		} else {
+			segs = append(segs, pathSegment{key: key})
+		}
+		i = j
+	}
+	return segs, nil
+}
+
+// parseBracket parses the bracket expression starting at offset i, returning
+// the offset after the closing bracket.
+func parseBracket(p string, i int) (int, pathSegment, error) {
+	if i+1 < len(p) && p[i+1] == '"' {
+		// Double-quoted keys use Go syntax, matching formatPath.
+		for j := i + 2; j < len(p); j++ {
+			switch p[j] {
+			case '\\':
+				j++
+			case '"':
+				key, err := strconv.Unquote(p[i+1 : j+1])
+				if err != nil {
+					return 0, pathSegment{}, fmt.Errorf("invalid string at offset %d: %v", i+1, err)
+				}
+				if j+1 >= len(p) || p[j+1] != ']' {
+					return 0, pathSegment{}, fmt.Errorf("expected ] at offset %d", j+1)
+				}
+				return j + 2, pathSegment{key: key}, nil
+			}
+		}
+		return 0, pathSegment{}, fmt.Errorf("unterminated string at offset %d", i+1)
+	}
+	if i+1 < len(p) && p[i+1] == '\'' {
+		// Single-quoted keys are literal apart from backslash escapes.
+		var b strings.Builder
+		for j := i + 2; j < len(p); j++ {
+			switch {
+			case p[j] == '\\' && j+1 < len(p):
+				j++
+				b.WriteByte(p[j])
+			case p[j] == '\'':
+				if j+1 >= len(p) || p[j+1] != ']' {
+					return 0, pathSegment{}, fmt.Errorf("expected ] at offset %d", j+1)
+				}
+				return j + 2, pathSegment{key: b.String()}, nil
+			default:
+				b.WriteByte(p[j])
+			}
+		}
+		return 0, pathSegment{}, fmt.Errorf("unterminated string at offset %d", i+1)
+	}
+	end := strings.IndexByte(p[i:], ']')
+	if end < 0 {
+		return 0, pathSegment{}, fmt.Errorf("unterminated bracket at offset %d", i)
+	}
+	inner := strings.TrimSpace(p[i+1 : i+end])
+	if inner == "*" {
+		return i + end + 1, pathSegment{wildcard: true}, nil
+	}
+	idx, err := strconv.Atoi(inner)
+	if err != nil {
+		return 0, pathSegment{}, fmt.Errorf("invalid index %q at offset %d", inner, i+1)
+	}
+	return i + end + 1, pathSegment{index: idx, isIndex: true}, nil
+}
+
+// formatPath builds a path expression from keys and sequence indexes, as
+// accepted by Query.
+func formatPath(tokens []interface{}) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		switch v := t.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(v) + "]")
+		case string:
+			if isSimpleKey(v) {
+				if b.Len() > 0 {
+					b.WriteByte('.')
+				}
+				b.WriteString(v)
+			} else {
+				b.WriteString("[" + strconv.Quote(v) + "]")
+			}
+		}
+	}
+	return b.String()
+}
+
+// isSimpleKey returns true if the key can be used in a path without
+// brackets.
+func isSimpleKey(k string) bool {
+	if k == "" || k == "*" || k == "$" {
+		return false
+	}
+	if strings.ContainsAny(k, ".[]\"'\\ ") || k[0] == '$' {
+		return false
+	}
+	return strings.IndexFunc(k, func(r rune) bool { return !strconv.IsPrint(r) }) < 0
+}
+
+// UnmarshalPath decodes only the value found at the path expression into
+// the object, using the same rules as Unmarshal. The rest of the document
+// is parsed but never converted, making this much cheaper than a full
+// Unmarshal when only a small part of a large document is needed. The path
+// must match exactly one value, otherwise an error is returned.
+func UnmarshalPath(y []byte, path string, o interface{}, opts ...JSONOpt) error {
+	doc, err := parseDocument(y)
+	if err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %v", err)
+	}
+	return decodePath(doc, path, o, opts)
+}
+
+// Decode stores the value found at the path expression in the object, using
+// the same rules as Unmarshal. Only that value is converted, so a large
+// document can be parsed once and the few values needed decoded as they are
+// accessed. The path must match exactly one value.
+func (d *Document) Decode(path string, o interface{}, opts ...JSONOpt) error {
+	return decodePath(d.root, path, o, opts)
+}
+
+func decodePath(doc *yaml.Node, path string, o interface{}, opts []JSONOpt) error {
+	nodes, err := QueryNodes(doc, path)
+	if err != nil {
+		return err
+	}
+	switch len(nodes) {
+	case 0:
+		return fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	case 1:
+		return NodeToStruct(nodes[0], o, opts...)
+	}
+	return fmt.Errorf("path %s matched %d values, expected one", path, len(nodes))
+}
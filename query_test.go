@@ -0,0 +1,159 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const queryDoc = `
+metadata:
+  name: web
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  containers:
+    - name: app
+      image: app:1.0
+    - name: proxy
+      image: envoy:1.2
+`
+
+func TestQuery(t *testing.T) {
+	for _, tc := range []struct {
+		path string
+		want []interface{}
+	}{
+		{"metadata.name", []interface{}{"web"}},
+		{"$.metadata.name", []interface{}{"web"}},
+		{"spec.containers[*].image", []interface{}{"app:1.0", "envoy:1.2"}},
+		{"spec.containers[1].name", []interface{}{"proxy"}},
+		{"spec.containers[-1].name", []interface{}{"proxy"}},
+		{`metadata.labels["app.kubernetes.io/name"]`, []interface{}{"web"}},
+		{`metadata.labels['app.kubernetes.io/name']`, []interface{}{"web"}},
+		{"spec.containers.*.name", []interface{}{"app", "proxy"}},
+		{"spec.missing", nil},
+		{"spec.containers[5]", nil},
+	} {
+		got, err := Query([]byte(queryDoc), tc.path)
+		if err != nil {
+			t.Errorf("Query(%q) = %v", tc.path, err)
+			continue
+		}
+		if len(got) == 0 && len(tc.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Query(%q) = %#v; want %#v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	for _, path := range []string{"a..b", "a[", "a[x]", `a["b]`, "a.", `a["b"x]`} {
+		if _, err := Query([]byte(queryDoc), path); err == nil {
+			t.Errorf("Query(%q) = nil; want error", path)
+		}
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	got := formatPath([]interface{}{"spec", "containers", 0, "app.io/name"})
+	if want := `spec.containers[0]["app.io/name"]`; got != want {
+		t.Errorf("formatPath() = %q; want %q", got, want)
+	}
+	segs, err := parsePath(got)
+	if err != nil || len(segs) != 4 || segs[3].key != "app.io/name" {
+		t.Errorf("parsePath(%q) = %+v, %v", got, segs, err)
+	}
+}
+
+func TestQueryWalkPaths(t *testing.T) {
+	src := "\"a\\nb\": 1\n'say \"hi\"': 2\n\"tab\\there\": 3\n\"x]y\": 4\n\"\\u00e9\": 5\n"
+	d, err := ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	var paths []string
+	err = d.Walk(func(path string, n *yaml.Node) error {
+		if n.Kind == yaml.ScalarNode {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() = %v", err)
+	}
+	want := []string{`["a\nb"]`, `["say \"hi\""]`, `["tab\there"]`, `["x]y"]`, "é"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Walk() paths = %q; want %q", paths, want)
+	}
+	for i, path := range paths {
+		got, err := Query([]byte(src), path)
+		if err != nil {
+			t.Errorf("Query(%q) = %v", path, err)
+			continue
+		}
+		if want := []interface{}{i + 1}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Query(%q) = %#v; want %#v", path, got, want)
+		}
+	}
+}
+
+func TestUnmarshalPath(t *testing.T) {
+	type Container struct {
+		Name  string `json:"name"`
+		Image string `json:"image"`
+	}
+	var c Container
+	if err := UnmarshalPath([]byte(queryDoc), "spec.containers[1]", &c); err != nil {
+		t.Fatalf("UnmarshalPath() = %v", err)
+	}
+	if want := (Container{Name: "proxy", Image: "envoy:1.2"}); c != want {
+		t.Errorf("UnmarshalPath() = %+v; want %+v", c, want)
+	}
+
+	var cs []Container
+	if err := UnmarshalPath([]byte(queryDoc), "spec.containers", &cs); err != nil {
+		t.Fatalf("UnmarshalPath() = %v", err)
+	}
+	if len(cs) != 2 {
+		t.Errorf("UnmarshalPath() = %+v; want 2 containers", cs)
+	}
+
+	var s string
+	if err := UnmarshalPath([]byte(queryDoc), "spec.missing", &s); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("UnmarshalPath(missing) = %v; want ErrPathNotFound", err)
+	}
+	if err := UnmarshalPath([]byte(queryDoc), "spec.containers[*].name", &s); err == nil {
+		t.Errorf("UnmarshalPath(wildcard) = nil; want error")
+	}
+	if err := UnmarshalPath([]byte(queryDoc), "spec.containers[0]", &c, DisallowUnknownFields); err != nil {
+		t.Errorf("UnmarshalPath(strict) = %v", err)
+	}
+}
+
+func TestDocumentDecode(t *testing.T) {
+	doc, err := ParseDocument([]byte(queryDoc))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	var first, second string
+	if err := doc.Decode("spec.containers[0].name", &first); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if err := doc.Decode("spec.containers[1].image", &second); err != nil {
+		t.Fatalf("Decode() = %v", err)
+	}
+	if first != "app" || second != "envoy:1.2" {
+		t.Errorf("Decode() = %q, %q; want %q, %q", first, second, "app", "envoy:1.2")
+	}
+	if err := doc.Decode("spec.missing", &first); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Decode(missing) = %v; want ErrPathNotFound", err)
+	}
+	if doc.Modified() {
+		t.Errorf("Modified() = true after decoding")
+	}
+}
@@ -0,0 +1,43 @@
+package yaml
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRedaction is the placeholder used by RedactSecrets when none is
+// provided.
+const DefaultRedaction = "[REDACTED]"
+
+// RedactSecrets replaces the values of struct fields marked as secret with
+// the placeholder, so that the output can be safely logged. Fields are
+// marked using the "secret" tag option in either the json or yaml tags, for
+// example `json:"password,secret"` or `yaml:",secret"`. Field names are
+// always taken from the json tag. Empty fields removed with omitempty are
+// not added.
+func RedactSecrets(placeholder string) EncodeOpt {
+	if placeholder == "" {
+		placeholder = DefaultRedaction
+	}
+	return func(e *encoder) {
+		e.redact = placeholder
+	}
+}
+
+// redactSecrets replaces the nodes of any secret fields found in the value.
+func redactSecrets(n *yaml.Node, v reflect.Value, placeholder string) {
+	walkValue(v, func(path []string, _ reflect.Value, f *field) bool {
+		if f == nil || !f.secret {
+			return true
+		}
+		target := n
+		for _, p := range path {
+			if target, _ = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		replaceNode(target, newScalarNode(placeholder))
+		return false
+	})
+}
@@ -0,0 +1,51 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestMarshalRedactSecrets(t *testing.T) {
+	type Database struct {
+		User     string `json:"user"`
+		Password string `json:"password" yaml:",secret"`
+	}
+	type Config struct {
+		Name     string            `json:"name"`
+		Token    string            `json:"token,omitempty,secret"`
+		Keys     map[string]string `json:"keys,secret"`
+		Database *Database         `json:"database"`
+	}
+	c := Config{
+		Name:     "app",
+		Keys:     map[string]string{"a": "1"},
+		Database: &Database{User: "root", Password: "hunter2"},
+	}
+
+	y, err := MarshalWithOptions(c, RedactSecrets(""))
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := "database:\n    password: '[REDACTED]'\n    user: root\nkeys: '[REDACTED]'\nname: app\n"
+	if string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+
+	y, err = MarshalWithOptions(c, RedactSecrets("***"))
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want = "database:\n    password: '***'\n    user: root\nkeys: '***'\nname: app\n"
+	if string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+
+	// Without the option, secrets are included.
+	y, err = Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want = "database:\n    password: hunter2\n    user: root\nkeys:\n    a: \"1\"\nname: app\n"
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+}
@@ -0,0 +1,121 @@
+package yaml
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// retagOpts holds the decoder options that change how scalars are
+// resolved for the type they are decoded into.
+type retagOpts struct {
+	exact        bool        // numbers keep their text in strings
+	leadingZeros bool        // numbers with leading zeros stay strings
+	integers     IntegerMode // how prefixed integers are read
+	timestamps   bool        // timestamps keep their text outside time.Time
+}
+
+// retagTarget retags the scalars in the node tree that need a different
+// tag to be decoded into the type: dates in fields with a format tag,
+// numbers decoded into a Number, and others according to the options. The
+// returned function restores the original tags and values.
+func retagTarget(n *yaml.Node, t reflect.Type, opts retagOpts) func() {
+	if n == nil || t == nil {
+		return func() {}
+	}
+	plan := cachedTypePlan(t)
+	if !plan.formats && !plan.numbers && opts == (retagOpts{}) {
+		return func() {}
+	}
+	return retagNodes(n, t, func(n *yaml.Node, t reflect.Type, f *field) (string, string) {
+		if t != nil {
+			if tag := formatTag(n, t, f); tag != "" {
+				return tag, n.Value
+			}
+			if opts.exact {
+				if tag := exactTag(n, t, f); tag != "" {
+					return tag, n.Value
+				}
+			} else if opts.leadingZeros {
+				if tag := leadingZerosTag(n, t, f); tag != "" {
+					return tag, n.Value
+				}
+			}
+			if tag := numberTag(n, t); tag != "" {
+				return tag, n.Value
+			}
+		}
+		if opts.timestamps {
+			if tag := timestampTag(n, t); tag != "" {
+				return tag, n.Value
+			}
+		}
+		return integerTag(n, opts.integers)
+	})
+}
+
+// retagNodes walks the scalars in the node tree along with the type they
+// will be decoded into, if known, and the struct field holding them, if
+// any, setting the tag and value returned by fn when the tag isn't empty.
+// The returned function restores the original tags and values.
+func retagNodes(n *yaml.Node, t reflect.Type, fn func(n *yaml.Node, t reflect.Type, f *field) (string, string)) func() {
+	type scalar struct {
+		n          *yaml.Node
+		tag, value string
+	}
+	var changed []scalar
+	var visit func(n *yaml.Node, t reflect.Type, f *field)
+	visit = func(n *yaml.Node, t reflect.Type, f *field) {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		var kind reflect.Kind
+		if t != nil {
+			kind = t.Kind()
+		}
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				visit(c, t, f)
+			}
+		case yaml.ScalarNode:
+			if tag, value := fn(n, t, f); tag != "" {
+				changed = append(changed, scalar{n, n.Tag, n.Value})
+				n.Tag, n.Value = tag, value
+			}
+		case yaml.MappingNode:
+			var plan *typePlan
+			if kind == reflect.Struct && t != timeType {
+				plan = cachedTypePlan(t)
+			}
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				switch {
+				case plan != nil:
+					if ff := plan.field(n.Content[i].Value); ff != nil {
+						visit(n.Content[i+1], ff.typ, ff)
+					} else {
+						visit(n.Content[i+1], nil, nil)
+					}
+				case kind == reflect.Map:
+					visit(n.Content[i+1], t.Elem(), nil)
+				default:
+					visit(n.Content[i+1], nil, nil)
+				}
+			}
+		case yaml.SequenceNode:
+			var et reflect.Type
+			if kind == reflect.Slice || kind == reflect.Array {
+				et = t.Elem()
+			}
+			for _, c := range n.Content {
+				visit(c, et, nil)
+			}
+		}
+	}
+	visit(n, t, nil)
+	return func() {
+		for _, c := range changed {
+			c.n.Tag, c.n.Value = c.tag, c.value
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package yaml
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScalarLengthError is returned when a scalar in the document is longer
+// than allowed by MaxScalarLength.
+type ScalarLengthError struct {
+	Limit  int
+	Length int
+	Line   int
+	Column int
+}
+
+func (e *ScalarLengthError) Error() string {
+	return positionPrefix(e.Line, e.Column) + fmt.Sprintf("scalar of %d bytes exceeds the limit of %d bytes", e.Length, e.Limit)
+}
+
+// MaxScalarLength configures the decoder to reject documents holding a
+// scalar, whether a key or a value, longer than the given number of bytes
+// with a *ScalarLengthError. The check is made once the document has been
+// parsed, before its values are converted and copied into the object, so
+// it should be combined with MemoryBudget to also limit the memory used
+// while parsing.
+func MaxScalarLength(n int) DecodeOpt {
+	return func(d *Decoder) {
+		d.maxScalar = n
+	}
+}
+
+// checkScalarLength returns a *ScalarLengthError for the first scalar in
+// the tree longer than the limit.
+func checkScalarLength(n *yaml.Node, limit int) error {
+	if n.Kind == yaml.ScalarNode && len(n.Value) > limit {
+		return &ScalarLengthError{Limit: limit, Length: len(n.Value), Line: n.Line, Column: n.Column}
+	}
+	for _, c := range n.Content {
+		if err := checkScalarLength(c, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMaxScalarLength(t *testing.T) {
+	tests := []struct {
+		name string
+		y    string
+		line int // of the error, or 0 for none
+	}{
+		{"short", "a: hello\nb: [x, y]\n", 0},
+		{"value", "a: 1\nb:\n  - |\n    " + strings.Repeat("x", 40) + "\n", 3},
+		{"key", strings.Repeat("k", 20) + ": 1\n", 1},
+		{"quoted", "a: \"" + strings.Repeat("\\u00e9", 9) + "\"\n", 1},
+	}
+	for _, test := range tests {
+		var v interface{}
+		err := UnmarshalWithOptions([]byte(test.y), &v, MaxScalarLength(16))
+		if test.line == 0 {
+			if err != nil {
+				t.Errorf("%s: UnmarshalWithOptions() = %v; want nil", test.name, err)
+			}
+			continue
+		}
+		var se *ScalarLengthError
+		if !errors.As(err, &se) {
+			t.Errorf("%s: UnmarshalWithOptions() = %v; want *ScalarLengthError", test.name, err)
+			continue
+		}
+		if se.Line != test.line || se.Limit != 16 || se.Length <= 16 {
+			t.Errorf("%s: error = %+v; want line %d", test.name, se, test.line)
+		}
+	}
+
+	var v interface{}
+	err := UnmarshalWithOptions([]byte("a: "+strings.Repeat("x", 20)+"\n"), &v, MaxScalarLength(16))
+	if want := "line 1, column 4: scalar of 20 bytes exceeds the limit of 16 bytes"; err == nil || err.Error() != want {
+		t.Errorf("UnmarshalWithOptions() = %v; want %q", err, want)
+	}
+}
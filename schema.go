@@ -0,0 +1,500 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is a JSON Schema used to validate YAML documents. The most common
+// validation keywords are supported: type, enum, const, properties,
+// required, additionalProperties, items, length, range, and pattern
+// constraints, the allOf, anyOf, oneOf, and not combinators, and local
+// references to "#/$defs/..." or "#/definitions/...".
+type Schema struct {
+	ID          string             `json:"$id,omitempty"`
+	SchemaURI   string             `json:"$schema,omitempty"`
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+
+	Type  SchemaTypes   `json:"type,omitempty"`
+	Enum  []interface{} `json:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+
+	MinLength        *int     `json:"minLength,omitempty"`
+	MaxLength        *int     `json:"maxLength,omitempty"`
+	Pattern          string   `json:"pattern,omitempty"`
+	Format           string   `json:"format,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+	MinItems         *int     `json:"minItems,omitempty"`
+	MaxItems         *int     `json:"maxItems,omitempty"`
+	UniqueItems      bool     `json:"uniqueItems,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+
+	// boolean is set for the "true" and "false" schemas.
+	boolean *bool
+	root    *Schema
+}
+
+// SchemaTypes holds the list of types accepted by a schema, encoded as a
+// single string when there is only one.
+type SchemaTypes []string
+
+// MarshalJSON outputs a single type as a string.
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts either a string or a list of strings.
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = SchemaTypes{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// MarshalJSON handles the boolean schemas.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.boolean != nil {
+		return json.Marshal(*s.boolean)
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// UnmarshalJSON handles the boolean schemas.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{boolean: &b}
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// ParseSchema parses a JSON Schema provided in either JSON or YAML.
+func ParseSchema(data []byte) (*Schema, error) {
+	s := new(Schema)
+	if err := Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("error parsing schema: %v", err)
+	}
+	return s, nil
+}
+
+// SchemaViolation describes a single place where a document does not
+// match the schema.
+type SchemaViolation struct {
+	Path    string // JSON Pointer to the value
+	Line    int
+	Column  int
+	Message string
+}
+
+// String provides the violation with its position.
+func (v SchemaViolation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", v.Line, v.Column, path, v.Message)
+}
+
+// SchemaError is returned when a document does not match the schema, and
+// contains every violation found.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error lists the violations.
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "schema validation failed: " + strings.Join(msgs, "; ")
+}
+
+// ValidateSchema configures the Decoder to validate each document against
+// the schema before decoding it, returning a *SchemaError with the YAML
+// positions of any violations.
+func ValidateSchema(s *Schema) DecodeOpt {
+	return func(d *Decoder) {
+		d.schema = s
+	}
+}
+
+// Validate checks the first document of the YAML data against the schema,
+// returning a *SchemaError if there are any violations.
+func (s *Schema) Validate(y []byte) error {
+	doc, err := parseDocument(y)
+	if err != nil {
+		return fmt.Errorf("error parsing YAML: %v", err)
+	}
+	return s.ValidateNode(doc)
+}
+
+// ValidateNode checks the node tree against the schema.
+func (s *Schema) ValidateNode(n *yaml.Node) error {
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			n = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Line: n.Line, Column: n.Column}
+		} else {
+			n = n.Content[0]
+		}
+	}
+	s.setRoot(s)
+	v := s.validate(n, nil)
+	if len(v) > 0 {
+		sort.SliceStable(v, func(i, j int) bool {
+			if v[i].Line != v[j].Line {
+				return v[i].Line < v[j].Line
+			}
+			return v[i].Column < v[j].Column
+		})
+		return &SchemaError{Violations: v}
+	}
+	return nil
+}
+
+// setRoot stores the root schema in every sub-schema so that references
+// can be resolved.
+func (s *Schema) setRoot(root *Schema) {
+	if s == nil || s.root == root {
+		return
+	}
+	s.root = root
+	for _, c := range s.children() {
+		c.setRoot(root)
+	}
+}
+
+func (s *Schema) children() []*Schema {
+	var out []*Schema
+	for _, m := range []map[string]*Schema{s.Defs, s.Definitions, s.Properties} {
+		for _, c := range m {
+			out = append(out, c)
+		}
+	}
+	out = append(out, s.AdditionalProperties, s.Items, s.Not)
+	out = append(out, s.AllOf...)
+	out = append(out, s.AnyOf...)
+	return append(out, s.OneOf...)
+}
+
+// resolve follows the schema's reference, if any.
+func (s *Schema) resolve() (*Schema, error) {
+	for depth := 0; s.Ref != ""; depth++ {
+		if depth > 32 {
+			return nil, fmt.Errorf("too many nested references")
+		}
+		tokens, err := parsePointer(strings.TrimPrefix(s.Ref, "#"))
+		if err != nil || len(tokens) != 2 {
+			return nil, fmt.Errorf("unsupported reference %q", s.Ref)
+		}
+		var defs map[string]*Schema
+		switch tokens[0] {
+		case "$defs":
+			defs = s.root.Defs
+		case "definitions":
+			defs = s.root.Definitions
+		}
+		t, ok := defs[tokens[1]]
+		if !ok {
+			return nil, fmt.Errorf("unknown reference %q", s.Ref)
+		}
+		s = t
+	}
+	return s, nil
+}
+
+func (s *Schema) validate(n *yaml.Node, path []string) []SchemaViolation { //nolint:gocyclo
+	violation := func(format string, args ...interface{}) []SchemaViolation {
+		return []SchemaViolation{{
+			Path:    formatPointer(path),
+			Line:    n.Line,
+			Column:  n.Column,
+			Message: fmt.Sprintf(format, args...),
+		}}
+	}
+	if s == nil {
+		return nil
+	}
+	if s.boolean != nil {
+		if !*s.boolean {
+			return violation("no value is allowed")
+		}
+		return nil
+	}
+	s, err := s.resolve()
+	if err != nil {
+		return violation("%v", err)
+	}
+	n = resolveAlias(n)
+	value, err := nodeToJSONable(n)
+	if err != nil {
+		return violation("%v", err)
+	}
+
+	var out []SchemaViolation
+	if len(s.Type) > 0 && !s.Type.matches(value) {
+		return violation("expected %s but found %s", strings.Join(s.Type, " or "), jsonType(value))
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if jsonEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, violation("value must be one of %s", summarizeValue(s.Enum))...)
+		}
+	}
+	if s.Const != nil && !jsonEqual(s.Const, value) {
+		out = append(out, violation("value must be %s", summarizeValue(s.Const))...)
+	}
+
+	switch v := value.(type) {
+	case string:
+		l := utf8.RuneCountInString(v)
+		if s.MinLength != nil && l < *s.MinLength {
+			out = append(out, violation("length must be at least %d", *s.MinLength)...)
+		}
+		if s.MaxLength != nil && l > *s.MaxLength {
+			out = append(out, violation("length must be at most %d", *s.MaxLength)...)
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				out = append(out, violation("invalid pattern %q: %v", s.Pattern, err)...)
+			} else if !re.MatchString(v) {
+				out = append(out, violation("value must match %q", s.Pattern)...)
+			}
+		}
+	case map[string]interface{}:
+		out = append(out, s.validateObject(n, path, violation)...)
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			out = append(out, violation("must have at least %d items", *s.MinItems)...)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			out = append(out, violation("must have at most %d items", *s.MaxItems)...)
+		}
+		if s.UniqueItems {
+			for i := range v {
+				for j := i + 1; j < len(v); j++ {
+					if jsonEqual(v[i], v[j]) {
+						out = append(out, violation("items %d and %d are equal", i, j)...)
+					}
+				}
+			}
+		}
+		if s.Items != nil {
+			for i, c := range n.Content {
+				out = append(out, s.Items.validate(c, append(path, strconv.Itoa(i)))...)
+			}
+		}
+	default:
+		if f, ok := jsonNumber(v); ok {
+			out = append(out, s.validateNumber(f, violation)...)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		out = append(out, sub.validate(n, path)...)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := false
+		for _, sub := range s.AnyOf {
+			if len(sub.validate(n, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out = append(out, violation("value must match at least one schema in anyOf")...)
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			if len(sub.validate(n, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			out = append(out, violation("value must match exactly one schema in oneOf, matched %d", matched)...)
+		}
+	}
+	if s.Not != nil && len(s.Not.validate(n, path)) == 0 {
+		out = append(out, violation("value must not match the schema in not")...)
+	}
+	return out
+}
+
+func (s *Schema) validateObject(n *yaml.Node, path []string, violation func(string, ...interface{}) []SchemaViolation) []SchemaViolation {
+	var out []SchemaViolation
+	for _, r := range s.Required {
+		if v, _ := childNode(n, r); v == nil {
+			out = append(out, violation("missing required property %q", r)...)
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		p := append(path, k.Value)
+		if ps, ok := s.Properties[k.Value]; ok {
+			out = append(out, ps.validate(v, p)...)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if ap := s.AdditionalProperties; ap.boolean != nil && !*ap.boolean {
+				out = append(out, SchemaViolation{
+					Path:    formatPointer(p),
+					Line:    k.Line,
+					Column:  k.Column,
+					Message: fmt.Sprintf("property %q is not allowed", k.Value),
+				})
+				continue
+			}
+			out = append(out, s.AdditionalProperties.validate(v, p)...)
+		}
+	}
+	return out
+}
+
+func (s *Schema) validateNumber(f float64, violation func(string, ...interface{}) []SchemaViolation) []SchemaViolation {
+	var out []SchemaViolation
+	if s.Minimum != nil && f < *s.Minimum {
+		out = append(out, violation("must be greater than or equal to %v", *s.Minimum)...)
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		out = append(out, violation("must be less than or equal to %v", *s.Maximum)...)
+	}
+	if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+		out = append(out, violation("must be greater than %v", *s.ExclusiveMinimum)...)
+	}
+	if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+		out = append(out, violation("must be less than %v", *s.ExclusiveMaximum)...)
+	}
+	return out
+}
+
+// matches returns true if the value is one of the types.
+func (t SchemaTypes) matches(v interface{}) bool {
+	vt := jsonType(v)
+	for _, typ := range t {
+		if typ == vt || (typ == "number" && vt == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the name of the JSON Schema type of the value.
+func jsonType(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		if f, ok := jsonNumber(t); ok {
+			if f == math.Trunc(f) && !math.IsInf(f, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return reflect.TypeOf(v).String()
+}
+
+// jsonNumber converts any of the number types produced by go-yaml or
+// encoding/json into a float.
+func jsonNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonEqual compares two values as they would be represented in JSON.
+func jsonEqual(a, b interface{}) bool {
+	if af, ok := jsonNumber(a); ok {
+		bf, ok := jsonNumber(b)
+		return ok && af == bf
+	}
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for k, v := range at {
+			if bv, ok := bt[k]; !ok || !jsonEqual(v, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok || len(at) != len(bt) {
+			return false
+		}
+		for i := range at {
+			if !jsonEqual(at[i], bt[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
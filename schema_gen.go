@@ -0,0 +1,151 @@
+package yaml
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+	emptyInterfaceT = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// GenerateSchema produces a JSON Schema describing the documents that can
+// be decoded into the type of v, following the exact same field rules as
+// Unmarshal and Marshal: names from json tags, embedded structs promoted,
+// and "-" fields skipped. Fields without omitempty are always output by
+// Marshal, so they're listed as required. Named struct types are placed
+// in "$defs" and referenced, allowing for recursive types. A nil pointer
+// of the type, such as (*Config)(nil), may be provided.
+func GenerateSchema(v interface{}) *Schema {
+	g := &schemaGenerator{defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+	s := g.schemaFor(reflect.TypeOf(v), false)
+	if len(g.defs) > 0 {
+		// Move the root definition up if it was referenced.
+		if s.Ref != "" {
+			name := s.Ref[len("#/$defs/"):]
+			root := g.defs[name]
+			if !g.referenced[name] {
+				delete(g.defs, name)
+				s = root
+			}
+		}
+		if len(g.defs) > 0 {
+			s.Defs = g.defs
+		}
+	}
+	s.SchemaURI = "https://json-schema.org/draft/2020-12/schema"
+	return s
+}
+
+type schemaGenerator struct {
+	defs       map[string]*Schema
+	names      map[reflect.Type]string
+	referenced map[string]bool
+}
+
+func (g *schemaGenerator) schemaFor(t reflect.Type, quoted bool) *Schema { //nolint:gocyclo
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaTypes{"string"}, Format: "date-time"}
+	case t == rawMessageType || t == emptyInterfaceT:
+		return &Schema{}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType),
+		implementsMarshalerTo(t) || implementsMarshalerTo(reflect.PtrTo(t)):
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: SchemaTypes{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if quoted {
+			return &Schema{Type: SchemaTypes{"string"}, Enum: []interface{}{"true", "false"}}
+		}
+		return &Schema{Type: SchemaTypes{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if quoted {
+			return &Schema{Type: SchemaTypes{"string"}, Pattern: "^-?[0-9]+$"}
+		}
+		s := &Schema{Type: SchemaTypes{"integer"}}
+		if t.Kind() >= reflect.Uint && t.Kind() <= reflect.Uintptr {
+			zero := 0.0
+			s.Minimum = &zero
+		}
+		return s
+	case reflect.Float32, reflect.Float64:
+		if quoted {
+			return &Schema{Type: SchemaTypes{"string"}}
+		}
+		return &Schema{Type: SchemaTypes{"number"}}
+	case reflect.String:
+		return &Schema{Type: SchemaTypes{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: SchemaTypes{"string"}, Format: "byte"}
+		}
+		s := &Schema{Type: SchemaTypes{"array"}, Items: g.schemaFor(t.Elem(), false)}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			s.MinItems, s.MaxItems = &n, &n
+		}
+		return s
+	case reflect.Map:
+		return &Schema{Type: SchemaTypes{"object"}, AdditionalProperties: g.schemaFor(t.Elem(), false)}
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	return &Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.structProperties(t)
+	}
+	name, ok := g.names[t]
+	if ok {
+		if g.referenced == nil {
+			g.referenced = make(map[string]bool)
+		}
+		g.referenced[name] = true
+		return &Schema{Ref: "#/$defs/" + name}
+	}
+	name = t.Name()
+	for i := 2; g.defs[name] != nil; i++ {
+		name = t.Name() + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	g.defs[name] = &Schema{} // placeholder for recursive types
+	*g.defs[name] = *g.structProperties(t)
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+func (g *schemaGenerator) structProperties(t reflect.Type) *Schema {
+	f := false
+	s := &Schema{
+		Type:                 SchemaTypes{"object"},
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &Schema{boolean: &f},
+	}
+	for _, field := range cachedTypeFields(t) {
+		ft := t.FieldByIndex(field.index).Type
+		s.Properties[field.name] = g.schemaFor(ft, field.quoted)
+		if p := s.Properties[field.name]; field.format != "" && len(p.Type) == 1 && p.Type[0] == "string" {
+			p.Format = field.format
+		}
+		if !field.omitEmpty {
+			s.Required = append(s.Required, field.name)
+		}
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package yaml
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type GenEmbedded struct {
+	ID string `json:"id"`
+}
+
+type genNode struct {
+	Name     string     `json:"name"`
+	Children []*genNode `json:"children,omitempty"`
+}
+
+type genConfig struct {
+	GenEmbedded
+	Name    string            `json:"name"`
+	Count   uint              `json:"count,omitempty"`
+	Ratio   float64           `json:"ratio,string"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Created time.Time         `json:"created"`
+	Tree    *genNode          `json:"tree,omitempty"`
+	Ignored string            `json:"-"`
+	Any     interface{}       `json:"any,omitempty"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	s := GenerateSchema((*genConfig)(nil))
+	j, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","$defs":{"genNode":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/genNode"}},"name":{"type":"string"}},"required":["name"],"additionalProperties":false}},"type":"object","properties":{"any":{},"count":{"type":"integer","minimum":0},"created":{"type":"string","format":"date-time"},"id":{"type":"string"},"labels":{"type":"object","additionalProperties":{"type":"string"}},"name":{"type":"string"},"ratio":{"type":"string"},"tree":{"$ref":"#/$defs/genNode"}},"required":["id","name","ratio","created"],"additionalProperties":false}`
+	if string(j) != want {
+		t.Errorf("GenerateSchema() =\n%s\nwant\n%s", j, want)
+	}
+
+	// The generated schema should accept the output of Marshal.
+	y, err := Marshal(genConfig{Name: "x", Tree: &genNode{Name: "root", Children: []*genNode{{Name: "leaf"}}}})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if err := s.Validate(y); err != nil {
+		t.Errorf("Validate(%s) = %v", y, err)
+	}
+	if err := s.Validate([]byte("name: x\nunknown: 1\n")); err == nil {
+		t.Errorf("Validate() = nil; want error")
+	}
+}
@@ -0,0 +1,108 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const testSchema = `
+type: object
+required: [name, replicas]
+additionalProperties: false
+properties:
+  name:
+    type: string
+    pattern: "^[a-z]+$"
+  replicas:
+    type: integer
+    minimum: 1
+  mode:
+    enum: [fast, slow]
+  ports:
+    type: array
+    maxItems: 2
+    items:
+      $ref: "#/$defs/port"
+$defs:
+  port:
+    type: integer
+    exclusiveMaximum: 65536
+`
+
+func TestSchemaValidate(t *testing.T) {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema() = %v", err)
+	}
+	if err := s.Validate([]byte("name: web\nreplicas: 2\nports: [80, 443]\n")); err != nil {
+		t.Errorf("Validate(valid) = %v", err)
+	}
+
+	err = s.Validate([]byte(`name: Web
+replicas: 0
+mode: medium
+ports:
+  - 80
+  - 70000
+  - x
+extra: true
+`))
+	var se *SchemaError
+	if !errors.As(err, &se) {
+		t.Fatalf("Validate() = %v; want *SchemaError", err)
+	}
+	var got []string
+	for _, v := range se.Violations {
+		got = append(got, v.String())
+	}
+	want := []string{
+		`1:7: /name: value must match "^[a-z]+$"`,
+		`2:11: /replicas: must be greater than or equal to 1`,
+		`3:7: /mode: value must be one of ["fast","slow"]`,
+		`5:3: /ports: must have at most 2 items`,
+		`6:5: /ports/1: must be less than 65536`,
+		`7:5: /ports/2: expected integer but found string`,
+		`8:1: /extra: property "extra" is not allowed`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() =\n%q\nwant\n%q", got, want)
+	}
+
+	if err := s.Validate([]byte("name: web\n")); err == nil || err.Error() != `schema validation failed: 1:1: /: missing required property "replicas"` {
+		t.Errorf("Validate(missing) = %v", err)
+	}
+}
+
+func TestSchemaCombinators(t *testing.T) {
+	s, err := ParseSchema([]byte(`{"oneOf": [{"type": "string"}, {"type": "number", "not": {"const": 0}}], "anyOf": [true]}`))
+	if err != nil {
+		t.Fatalf("ParseSchema() = %v", err)
+	}
+	for y, ok := range map[string]bool{"x": true, "3.5": true, "0": false, "[1]": false} {
+		if err := s.Validate([]byte(y)); (err == nil) != ok {
+			t.Errorf("Validate(%q) = %v; want valid %v", y, err, ok)
+		}
+	}
+}
+
+func TestDecodeValidateSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema() = %v", err)
+	}
+	var out map[string]interface{}
+	err = UnmarshalWithOptions([]byte("name: web\nreplicas: x\n"), &out, ValidateSchema(s))
+	var se *SchemaError
+	if !errors.As(err, &se) || se.Violations[0].Line != 2 {
+		t.Errorf("UnmarshalWithOptions() = %v; want schema error on line 2", err)
+	}
+
+	j, err := YAMLToJSONWithOptions([]byte("name: web\nreplicas: 1\n"), ValidateSchema(s))
+	if err != nil || string(j) != `{"name":"web","replicas":1}` {
+		t.Errorf("YAMLToJSONWithOptions() = %s, %v", j, err)
+	}
+	if _, err := YAMLToJSONWithOptions([]byte("name: web\n"), ValidateSchema(s)); !errors.As(err, &se) {
+		t.Errorf("YAMLToJSONWithOptions() = %v; want schema error", err)
+	}
+}
@@ -0,0 +1,167 @@
+package yaml
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaStrings configures the output so that values declared as strings
+// by the schema are always read back as strings, even by YAML 1.1 parsers
+// that take values like yes, 0755 or 1:30 for booleans and numbers. Such
+// strings are double-quoted, and numbers and booleans found where the
+// schema expects a string are written as quoted strings. Values are
+// matched with their schemas through properties, additionalProperties,
+// items, allOf and local references.
+func SchemaStrings(s *Schema) EncodeOpt {
+	return func(e *encoder) {
+		e.stringSchema = s
+	}
+}
+
+// StringPaths configures the output as SchemaStrings does for the values
+// found at the paths, for when no schema is available. Paths use the same
+// syntax as Query, including wildcards, but negative indexes are not
+// supported.
+func StringPaths(paths ...string) EncodeOpt {
+	return func(e *encoder) {
+		for _, p := range paths {
+			segs, err := parsePath(p)
+			if err != nil {
+				e.err = err
+				return
+			}
+			e.stringPaths = append(e.stringPaths, segs)
+		}
+	}
+}
+
+// JSONToYAMLWithOptions converts JSON to YAML like JSONToYAML, configuring
+// the output with the options that apply to the document rather than to
+// Go values, such as SchemaStrings, StringPaths, QuoteBoolKeys or
+// DiffFriendly.
+func JSONToYAMLWithOptions(j []byte, opts ...EncodeOpt) ([]byte, error) {
+	e := newEncoder(opts)
+	n, err := jsonToNode(j)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.apply(n, nil); err != nil {
+		return nil, err
+	}
+	w := new(appendWriter)
+	if err := encodeYAML(w, n); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+// applyStringTypes quotes the values of the tree declared as strings by
+// the schema or the paths.
+func applyStringTypes(n *yaml.Node, s *Schema, paths [][]pathSegment) {
+	if s != nil {
+		s.setRoot(s)
+	}
+	if n.Kind == yaml.DocumentNode {
+		for _, c := range n.Content {
+			applyStringTypes(c, s, paths)
+		}
+		return
+	}
+	stringTypesAt(n, s, paths, nil)
+}
+
+func stringTypesAt(n *yaml.Node, s *Schema, paths [][]pathSegment, path []string) {
+	if s != nil {
+		var err error
+		if s, err = s.resolve(); err != nil {
+			s = nil
+		}
+	}
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if s.declaresString() || matchesAnyPath(paths, path) {
+			quoteString(n)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k := n.Content[i].Value
+			stringTypesAt(n.Content[i+1], s.property(k), paths, append(path[:len(path):len(path)], k))
+		}
+	case yaml.SequenceNode:
+		var items *Schema
+		if s != nil {
+			items = s.Items
+		}
+		for i, c := range n.Content {
+			stringTypesAt(c, items, paths, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+	}
+}
+
+// declaresString reports whether the schema only accepts strings, or
+// strings and null.
+func (s *Schema) declaresString() bool {
+	if s == nil {
+		return false
+	}
+	for _, a := range s.AllOf {
+		if a, err := a.resolve(); err == nil && a.declaresString() {
+			return true
+		}
+	}
+	str := false
+	for _, t := range s.Type {
+		switch t {
+		case "string":
+			str = true
+		case "null":
+		default:
+			return false
+		}
+	}
+	return str
+}
+
+// property returns the schema of the object's property, or nil.
+func (s *Schema) property(k string) *Schema {
+	if s == nil {
+		return nil
+	}
+	if p, ok := s.Properties[k]; ok {
+		return p
+	}
+	for _, a := range s.AllOf {
+		if a, err := a.resolve(); err == nil {
+			if p := a.property(k); p != nil {
+				return p
+			}
+		}
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.boolean == nil {
+		return s.AdditionalProperties
+	}
+	return nil
+}
+
+func matchesAnyPath(paths [][]pathSegment, path []string) bool {
+	for _, p := range paths {
+		if matchesPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteString makes sure the scalar is read back as a string.
+func quoteString(n *yaml.Node) {
+	switch n.ShortTag() {
+	case "!!str":
+		if n.Style&^yaml.TaggedStyle == 0 && ambiguousScalar(n) != "" {
+			n.Style = n.Style&yaml.TaggedStyle | yaml.DoubleQuotedStyle
+		}
+	case "!!int", "!!float", "!!bool":
+		n.Tag = "!!str"
+		n.Style = yaml.DoubleQuotedStyle
+	}
+}
@@ -0,0 +1,95 @@
+package yaml
+
+import (
+	"testing"
+)
+
+const stringSchema = `
+type: object
+properties:
+  version: {type: string}
+  enabled: {type: boolean}
+  mode: {$ref: "#/$defs/code"}
+  tags:
+    type: array
+    items: {type: [string, "null"]}
+additionalProperties:
+  allOf:
+    - type: string
+$defs:
+  code: {type: string}
+`
+
+func TestSchemaStrings(t *testing.T) {
+	s, err := ParseSchema([]byte(stringSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := `{"version":1.5,"enabled":true,"mode":"0755","tags":["yes","no","x",null,"1:30"],"zip":"01234","count":"3"}`
+	got, err := JSONToYAMLWithOptions([]byte(j), SchemaStrings(s))
+	if err != nil {
+		t.Fatalf("JSONToYAMLWithOptions() = %v", err)
+	}
+	want := `count: "3"
+enabled: true
+mode: "0755"
+tags:
+    - "yes"
+    - "no"
+    - x
+    - null
+    - "1:30"
+version: "1.5"
+zip: "01234"
+`
+	if string(got) != want {
+		t.Errorf("JSONToYAMLWithOptions() =\n%s\nwant\n%s", got, want)
+	}
+
+	if plain, _ := JSONToYAML([]byte(j)); string(plain) == want {
+		t.Errorf("JSONToYAML() = %s; want unquoted values", plain)
+	}
+}
+
+func TestStringPaths(t *testing.T) {
+	type config struct {
+		Version float64           `json:"version"`
+		Labels  map[string]string `json:"labels"`
+		Ports   []string          `json:"ports"`
+	}
+	v := config{Version: 2, Labels: map[string]string{"on": "on", "octal": "0644"}, Ports: []string{"22:22"}}
+	got, err := MarshalWithOptions(v, StringPaths("version", "labels.*", "ports[0]"))
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := "labels:\n    octal: \"0644\"\n    \"on\": \"on\"\nports:\n    - \"22:22\"\nversion: \"2\"\n"
+	if string(got) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", got, want)
+	}
+
+	if _, err := MarshalWithOptions(v, StringPaths("a[")); err == nil {
+		t.Errorf("MarshalWithOptions() = nil error; want invalid path")
+	}
+}
+
+func TestJSONToYAMLWithOptions(t *testing.T) {
+	j := `{"on":1,"list":[{"a":"x\ny"}],"s":"it's"}`
+	got, err := JSONToYAMLWithOptions([]byte(j), QuoteBoolKeys(), DiffFriendly())
+	if err != nil {
+		t.Fatalf("JSONToYAMLWithOptions() = %v", err)
+	}
+	want := "list:\n    - a: |-\n        x\n        y\n\n\"on\": 1\ns: it's\n"
+	if string(got) != want {
+		t.Errorf("JSONToYAMLWithOptions() = %q; want %q", got, want)
+	}
+
+	// Options that depend on Go values have no effect.
+	opts := []EncodeOpt{KeepEmpty(), FloatPrecision(2), IntegerBase(16), RedactSecrets("***")}
+	got, err = JSONToYAMLWithOptions([]byte(`{"n":255,"f":1.234}`), opts...)
+	if want := "f: 1.234\n\"n\": 255\n"; err != nil || string(got) != want {
+		t.Errorf("JSONToYAMLWithOptions() = %q, %v; want %q", got, err, want)
+	}
+	if _, err := JSONToYAMLWithOptions([]byte(`{"a":`)); err == nil {
+		t.Errorf("JSONToYAMLWithOptions() = nil error; want error")
+	}
+}
@@ -0,0 +1,137 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Skeleton generates a template document for the object, usually a struct
+// holding the default values, as used by commands that write an initial
+// configuration file. Unlike Marshal, every field is listed, in the order
+// they are declared, including those marked omitempty and those behind nil
+// pointers, and slices of structs are given one element so that their
+// fields are listed too. Where the object holds a zero value, the field's
+// example tag is used instead when present, parsed as YAML, such as
+// `example:"[a, b]"`. Field documentation from the doc tag is written as
+// comments, as with FieldDocs.
+//
+// The object may be a nil pointer, such as (*Config)(nil), to generate the
+// template from the type alone.
+func Skeleton(o interface{}) ([]byte, error) {
+	if o == nil {
+		return nil, fmt.Errorf("yaml: no type for skeleton")
+	}
+	n, err := skeletonNode(reflect.TypeOf(o), reflect.ValueOf(o), "", make(map[reflect.Type]bool))
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	if err := encodeYAML(&b, &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{n}}); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// skeletonNode returns the node for the value, which is invalid when there
+// is none, of the type. The example is used for zero values, and seen
+// holds the structs being generated to stop recursive types.
+func skeletonNode(t reflect.Type, v reflect.Value, example string, seen map[reflect.Type]bool) (*yaml.Node, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() && !v.IsNil() {
+			v = v.Elem()
+		} else {
+			v = reflect.Value{}
+		}
+	}
+	if !v.IsValid() {
+		v = reflect.Zero(t)
+	}
+	if example != "" && v.IsZero() {
+		var n yaml.Node
+		if err := yaml.Unmarshal([]byte(example), &n); err != nil {
+			return nil, fmt.Errorf("yaml: invalid example %q: %w", example, err)
+		}
+		if len(n.Content) > 0 {
+			return n.Content[0], nil
+		}
+	}
+	if marshalsItself(t) {
+		return StructToNode(v.Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if seen[t] {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, f := range cachedTypeFields(t) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok {
+				fv = reflect.Value{}
+			}
+			// The field's own type, as f.typ omits unnamed pointers.
+			ft := t.FieldByIndex(f.index).Type
+			c, err := skeletonNode(ft, fv, f.example, seen)
+			if err != nil {
+				return nil, err
+			}
+			k := newScalarNode(f.name)
+			k.HeadComment = f.doc
+			m.Content = append(m.Content, k, c)
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			break // encoded as base64
+		}
+		s := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i := 0; i < v.Len(); i++ {
+			c, err := skeletonNode(t.Elem(), v.Index(i), "", seen)
+			if err != nil {
+				return nil, err
+			}
+			s.Content = append(s.Content, c)
+		}
+		et := t.Elem()
+		for et.Kind() == reflect.Ptr {
+			et = et.Elem()
+		}
+		if v.Len() == 0 && et.Kind() == reflect.Struct && !seen[et] && !marshalsItself(et) {
+			c, err := skeletonNode(et, reflect.Value{}, "", seen)
+			if err != nil {
+				return nil, err
+			}
+			s.Content = append(s.Content, c)
+		}
+		if len(s.Content) == 0 {
+			s.Style = yaml.FlowStyle
+		}
+		return s, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+	}
+	n, err := StructToNode(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" && t.Kind() == reflect.Map {
+		// Nil maps are listed as empty ones.
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: yaml.FlowStyle}, nil
+	}
+	return n, nil
+}
+
+// marshalsItself reports whether values of the type provide their own JSON
+// or text encoding.
+func marshalsItself(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || implementsMarshalerTo(t) || t.Implements(textMarshalerType)
+}
@@ -0,0 +1,98 @@
+package yaml
+
+import (
+	"testing"
+	"time"
+)
+
+type skeletonConfig struct {
+	Name     string            `json:"name" doc:"Name of the service."`
+	Port     int               `json:"port,omitempty" example:"8080"`
+	Tags     []string          `json:"tags,omitempty" example:"[web, api]"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Timeout  time.Duration     `json:"timeout"`
+	Started  time.Time         `json:"started"`
+	TLS      *skeletonTLS      `json:"tls,omitempty"`
+	Backends []skeletonBackend `json:"backends"`
+	Parent   *skeletonConfig   `json:"parent,omitempty"`
+	Extra    interface{}       `json:"extra"`
+	Hidden   string            `json:"-"`
+}
+
+type skeletonTLS struct {
+	Cert string `json:"cert" doc:"Path to the certificate."`
+	Key  string `json:"key"`
+}
+
+type skeletonBackend struct {
+	URL    string `json:"url" example:"http://localhost"`
+	Weight int    `json:"weight"`
+}
+
+func TestSkeleton(t *testing.T) {
+	want := `# Name of the service.
+name: ""
+port: 8080
+tags: [web, api]
+labels: {}
+timeout: 0
+started: "0001-01-01T00:00:00Z"
+tls:
+    # Path to the certificate.
+    cert: ""
+    key: ""
+backends:
+    - url: http://localhost
+      weight: 0
+parent: null
+extra: null
+`
+	for _, o := range []interface{}{(*skeletonConfig)(nil), skeletonConfig{}} {
+		y, err := Skeleton(o)
+		if err != nil {
+			t.Fatalf("Skeleton(%T) = %v", o, err)
+		}
+		if string(y) != want {
+			t.Errorf("Skeleton(%T) = %s; want %s", o, y, want)
+		}
+	}
+
+	y, err := Skeleton(&skeletonConfig{Name: "web", Port: 80, Backends: []skeletonBackend{{URL: "http://a"}, {Weight: 2}}})
+	if err != nil {
+		t.Fatalf("Skeleton() = %v", err)
+	}
+	want = `# Name of the service.
+name: web
+port: 80
+tags: [web, api]
+labels: {}
+timeout: 0
+started: "0001-01-01T00:00:00Z"
+tls:
+    # Path to the certificate.
+    cert: ""
+    key: ""
+backends:
+    - url: http://a
+      weight: 0
+    - url: http://localhost
+      weight: 2
+parent: null
+extra: null
+`
+	if string(y) != want {
+		t.Errorf("Skeleton() = %s; want %s", y, want)
+	}
+
+	var out skeletonConfig
+	if err := Unmarshal(y, &out); err != nil || out.Backends[1].Weight != 2 {
+		t.Errorf("Unmarshal() = %+v, %v", out, err)
+	}
+
+	type bad struct {
+		X int `json:"x" example:"[unclosed"`
+	}
+	if _, err := Skeleton(bad{}); err == nil {
+		t.Errorf("Skeleton() = nil; want example error")
+	}
+}
@@ -0,0 +1,103 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrorSnippets configures the decoder to include an excerpt of the
+// offending line, with a caret under the column when it is known, in the
+// messages of errors that refer to a position in the source.
+func ErrorSnippets() DecodeOpt {
+	return func(d *Decoder) {
+		d.snippets = true
+	}
+}
+
+// Snippet returns an excerpt of the line in the source with a caret under
+// the column, which may be zero to omit the caret. Lines and columns start
+// at 1, and an empty string is returned when the line does not exist.
+func Snippet(src []byte, line, column int) string {
+	if line < 1 {
+		return ""
+	}
+	lines := bytes.Split(src, []byte("\n"))
+	if line > len(lines) || (line == len(lines) && len(lines[line-1]) == 0) {
+		return ""
+	}
+	text := strings.TrimRight(string(lines[line-1]), "\r")
+	num := strconv.Itoa(line)
+	gutter := strings.Repeat(" ", len(num))
+
+	var b strings.Builder
+	b.WriteString(" " + num + " | " + text)
+	if column > 0 {
+		b.WriteString("\n " + gutter + " | ")
+		// Keep tabs so the caret lines up with the text above it.
+		for i, r := range text {
+			if utf8.RuneCountInString(text[:i]) >= column-1 {
+				break
+			}
+			if r == '\t' {
+				b.WriteByte('\t')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('^')
+	}
+	return b.String()
+}
+
+// snippetError adds a source excerpt to the message of the error.
+type snippetError struct {
+	err     error
+	snippet string
+}
+
+func (e *snippetError) Error() string {
+	return e.err.Error() + "\n" + e.snippet
+}
+
+func (e *snippetError) Unwrap() error {
+	return e.err
+}
+
+// syntaxLine matches the line number in go-yaml syntax errors.
+var syntaxLine = regexp.MustCompile(`yaml: line (\d+):`)
+
+// withSnippet wraps the error with an excerpt of the source at the
+// position it refers to, if any.
+func withSnippet(err error, src []byte) error {
+	var line, column int
+	var te *TypeError
+	var ue *UnknownFieldError
+	var de *DuplicateKeyError
+	var se *SchemaError
+	var ae *AnchorError
+	switch {
+	case errors.As(err, &te):
+		line, column = te.Line, te.Column
+	case errors.As(err, &ue):
+		line, column = ue.Line, ue.Column
+	case errors.As(err, &de):
+		line, column = de.Line, de.Column
+	case errors.As(err, &ae):
+		line, column = ae.Line, ae.Column
+	case errors.As(err, &se) && len(se.Violations) > 0:
+		line, column = se.Violations[0].Line, se.Violations[0].Column
+	default:
+		if m := syntaxLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+	}
+	s := Snippet(src, line, column)
+	if s == "" {
+		return err
+	}
+	return &snippetError{err: err, snippet: s}
+}
@@ -0,0 +1,52 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	src := []byte("a: 1\nb:\n\t- x\nc: é: z\n")
+	cases := []struct {
+		line, column int
+		want         string
+	}{
+		{1, 4, " 1 | a: 1\n   |    ^"},
+		{3, 2, " 3 | \t- x\n   | \t^"},
+		{4, 7, " 4 | c: é: z\n   |       ^"},
+		{2, 0, " 2 | b:"},
+		{5, 1, ""},
+		{0, 1, ""},
+	}
+	for _, c := range cases {
+		if got := Snippet(src, c.line, c.column); got != c.want {
+			t.Errorf("Snippet(%d, %d) = %q; want %q", c.line, c.column, got, c.want)
+		}
+	}
+}
+
+func TestErrorSnippets(t *testing.T) {
+	y := []byte("name: web\nspec:\n  replicas: three\n")
+	var s errorsTarget
+	err := UnmarshalWithOptions(y, &s, ErrorSnippets())
+	want := "\n 3 |   replicas: three\n   |             ^"
+	if err == nil || !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("UnmarshalWithOptions() = %v; want suffix %q", err, want)
+	}
+	var te *TypeError
+	if !errors.As(err, &te) {
+		t.Errorf("UnmarshalWithOptions() = %v; want *TypeError", err)
+	}
+
+	// Syntax errors only refer to a line.
+	err = UnmarshalWithOptions([]byte("a: 1\nb: [\n"), &s, ErrorSnippets())
+	if err == nil || !strings.Contains(err.Error(), "\n 2 | b: [") {
+		t.Errorf("UnmarshalWithOptions(syntax) = %v; want snippet", err)
+	}
+
+	// Without the option, messages are unchanged.
+	if err := UnmarshalWithOptions(y, &s); err == nil || strings.Contains(err.Error(), "\n") {
+		t.Errorf("UnmarshalWithOptions() = %v; want single line error", err)
+	}
+}
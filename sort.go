@@ -0,0 +1,185 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SortKeys sorts the keys of every mapping in each document of the YAML
+// stream, recursively. Keys included in the priority list are placed first
+// in the order provided, for example "apiVersion", "kind", "metadata", with
+// the rest following in alphabetical order. Comments move together with
+// the keys and values they belong to, and the source's indentation is
+// maintained.
+func SortKeys(src []byte, priority ...string) ([]byte, error) {
+	return transformStream(src, detectIndent(src), func(n *yaml.Node) error {
+		SortNodeKeys(n, priority...)
+		return nil
+	})
+}
+
+// SortNodeKeys sorts the mapping keys in the node tree in place using the
+// same rules as SortKeys.
+func SortNodeKeys(n *yaml.Node, priority ...string) {
+	rank := make(map[string]int, len(priority))
+	for i, p := range priority {
+		if _, ok := rank[p]; !ok {
+			rank[p] = i
+		}
+	}
+	sortNodeKeys(n, rank)
+}
+
+func sortNodeKeys(n *yaml.Node, rank map[string]int) {
+	for _, c := range n.Content {
+		sortNodeKeys(c, rank)
+	}
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	pairs := make([][2]*yaml.Node, len(n.Content)/2)
+	for i := range pairs {
+		pairs[i] = [2]*yaml.Node{n.Content[2*i], n.Content[2*i+1]}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ki, kj := pairs[i][0].Value, pairs[j][0].Value
+		ri, iok := rank[ki]
+		rj, jok := rank[kj]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		}
+		return ki < kj
+	})
+	for i, p := range pairs {
+		n.Content[2*i], n.Content[2*i+1] = p[0], p[1]
+	}
+}
+
+// SortDocuments reorders the documents of the YAML stream by the scalar
+// values found at the path expressions, as used by Query, for example
+// "kind" then "metadata.name", comparing the values as strings. Documents
+// without a value at a path are placed first, and documents with equal
+// values keep their order. The text of each document is kept as it is,
+// with "---" added between documents where it is missing. Comments before
+// the first document stay at the top.
+func SortDocuments(src []byte, paths ...string) ([]byte, error) {
+	segs := make([][]pathSegment, len(paths))
+	for i, p := range paths {
+		var err error
+		if segs[i], err = parsePath(p); err != nil {
+			return nil, err
+		}
+	}
+	type document struct {
+		text   []byte
+		values map[string]string
+	}
+	var (
+		preamble []byte
+		docs     []document
+	)
+	for _, chunk := range splitDocuments(src) {
+		var n yaml.Node
+		err := yaml.NewDecoder(bytes.NewReader(chunk)).Decode(&n)
+		switch {
+		case errors.Is(err, io.EOF):
+			// Only comments, which stay with the text before them.
+			if len(docs) == 0 {
+				preamble = append(preamble, chunk...)
+			} else {
+				d := &docs[len(docs)-1]
+				d.text = append(d.text, chunk...)
+			}
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("error parsing YAML document %d: %w", len(docs), err)
+		}
+		docs = append(docs, document{text: chunk, values: peekNode(&n, paths, segs)})
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		for _, p := range paths {
+			if vi, vj := docs[i].values[p], docs[j].values[p]; vi != vj {
+				return vi < vj
+			}
+		}
+		return false
+	})
+
+	out := preamble
+	for i, d := range docs {
+		if i > 0 && !startsDocument(d.text) {
+			out = append(out, "---\n"...)
+		}
+		out = append(out, d.text...)
+		if len(d.text) > 0 && d.text[len(d.text)-1] != '\n' {
+			out = append(out, '\n')
+		}
+	}
+	return out, nil
+}
+
+// splitDocuments splits the stream into the text of each document, which
+// starts with its directives and "---" line, and ends after its "..." line.
+// Document markers can't appear within a document, as they are only
+// recognised at the start of a line, where no content may start with them.
+func splitDocuments(src []byte) [][]byte {
+	var (
+		chunks     [][]byte
+		start      int
+		directives bool // true while reading the directives of a document
+	)
+	split := func(at int) {
+		if at > start {
+			chunks = append(chunks, src[start:at])
+		}
+		start = at
+	}
+	for i := 0; i < len(src); {
+		end := bytes.IndexByte(src[i:], '\n') + 1
+		if end == 0 {
+			end = len(src) - i
+		}
+		line := src[i : i+end]
+		switch {
+		case line[0] == '%':
+			if !directives {
+				split(i)
+				directives = true
+			}
+		case isDocumentMarker(line, "---"):
+			if !directives {
+				split(i)
+			}
+			directives = false
+		case isDocumentMarker(line, "..."):
+			split(i + end)
+			directives = false
+		}
+		i += end
+	}
+	split(len(src))
+	return chunks
+}
+
+// startsDocument reports whether the text starts with directives or a
+// "---" line.
+func startsDocument(text []byte) bool {
+	return len(text) > 0 && text[0] == '%' || isDocumentMarker(text, "---")
+}
+
+// isDocumentMarker reports whether the line starts with the marker.
+func isDocumentMarker(line []byte, marker string) bool {
+	if !bytes.HasPrefix(line, []byte(marker)) {
+		return false
+	}
+	rest := line[len(marker):]
+	return len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r'
+}
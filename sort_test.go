@@ -0,0 +1,117 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestSortKeys(t *testing.T) {
+	src := `metadata:
+  name: web
+  # labels for routing
+  labels:
+    z: 1
+    a: 2 # first
+kind: Service
+spec:
+  - b: 1
+    a: 2
+apiVersion: v1
+---
+z: 1
+a: 2
+`
+	want := `apiVersion: v1
+kind: Service
+metadata:
+  # labels for routing
+  labels:
+    a: 2 # first
+    z: 1
+  name: web
+spec:
+  - a: 2
+    b: 1
+---
+a: 2
+z: 1
+`
+	got, err := SortKeys([]byte(src), "apiVersion", "kind", "metadata")
+	if err != nil {
+		t.Fatalf("SortKeys() = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("SortKeys() = %q; want %q", got, want)
+	}
+}
+
+func TestSortDocuments(t *testing.T) {
+	src := `# bundle
+---
+kind: Service
+metadata:
+  name: web
+---
+kind: Deployment
+metadata: {name: web}   # flow
+...
+# trailing comment
+%YAML 1.1
+---
+kind: Service
+metadata:
+  name: api
+---
+note: no kind
+---
+kind: Deployment
+metadata:
+  name: api`
+	want := `# bundle
+---
+note: no kind
+---
+kind: Deployment
+metadata:
+  name: api
+---
+kind: Deployment
+metadata: {name: web}   # flow
+...
+# trailing comment
+%YAML 1.1
+---
+kind: Service
+metadata:
+  name: api
+---
+kind: Service
+metadata:
+  name: web
+`
+	out, err := SortDocuments([]byte(src), "kind", "metadata.name")
+	if err != nil {
+		t.Fatalf("SortDocuments() = %v", err)
+	}
+	if string(out) != want {
+		t.Errorf("SortDocuments() =\n%s\nwant\n%s", out, want)
+	}
+
+	out, err = SortDocuments([]byte("b: 1\n---\na: 1\n"), "a")
+	if err != nil {
+		t.Fatalf("SortDocuments() = %v", err)
+	}
+	if want := "b: 1\n---\na: 1\n"; string(out) != want {
+		t.Errorf("SortDocuments() = %q; want %q", out, want)
+	}
+	out, err = SortDocuments([]byte("n: b\n---\nn: a"), "n")
+	if err != nil {
+		t.Fatalf("SortDocuments() = %v", err)
+	}
+	if want := "---\nn: a\n---\nn: b\n"; string(out) != want {
+		t.Errorf("SortDocuments() = %q; want %q", out, want)
+	}
+
+	if _, err := SortDocuments([]byte("a: 1\n---\n[b\n"), "a"); err == nil {
+		t.Errorf("SortDocuments() = nil; want error")
+	}
+}
@@ -0,0 +1,73 @@
+package yaml
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DiffFriendly configures the output for files kept under version control,
+// so that files regenerated from similar values differ by as few lines as
+// possible. Collections are always written in block style, one entry per
+// line; strings are either plain or double-quoted, never single-quoted;
+// strings with line breaks are written as literal blocks, one line each;
+// and top-level entries holding collections are separated from the others
+// by a blank line, so that changes in one section are not mixed with the
+// next.
+func DiffFriendly() EncodeOpt {
+	return func(e *encoder) {
+		e.diffFriendly = true
+	}
+}
+
+// applyDiffFriendly sets the styles of the node tree for DiffFriendly.
+func applyDiffFriendly(n *yaml.Node) {
+	diffFriendlyStyles(n)
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	if n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 2; i+1 < len(n.Content); i += 2 {
+		if !isBlockCollection(n.Content[i-1]) && !isBlockCollection(n.Content[i+1]) {
+			continue
+		}
+		if k := n.Content[i]; !strings.HasPrefix(k.HeadComment, "\n") {
+			k.HeadComment = "\n" + k.HeadComment
+		}
+	}
+}
+
+func diffFriendlyStyles(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.ShortTag() != "!!str" || n.Style&(yaml.LiteralStyle|yaml.FoldedStyle|yaml.DoubleQuotedStyle) != 0 {
+			return
+		}
+		switch {
+		case strings.Contains(n.Value, "\n"):
+			n.Style = n.Style&yaml.TaggedStyle | yaml.LiteralStyle
+		case n.Style&yaml.SingleQuotedStyle != 0 || singleQuoted(n.Value):
+			n.Style = n.Style&yaml.TaggedStyle | yaml.DoubleQuotedStyle
+		}
+	case yaml.DocumentNode, yaml.MappingNode, yaml.SequenceNode:
+		n.Style &^= yaml.FlowStyle
+		for _, c := range n.Content {
+			diffFriendlyStyles(c)
+		}
+	}
+}
+
+// singleQuoted reports whether go-yaml writes the string single-quoted
+// when no style is given, because it cannot be plain.
+func singleQuoted(s string) bool {
+	b, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s})
+	return err == nil && len(b) > 0 && b[0] == '\''
+}
+
+// isBlockCollection reports whether the node is written over several
+// lines.
+func isBlockCollection(n *yaml.Node) bool {
+	return (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode) && len(n.Content) > 0
+}
@@ -0,0 +1,49 @@
+package yaml
+
+import "testing"
+
+func TestDiffFriendly(t *testing.T) {
+	v := map[string]interface{}{
+		"name":    "web",
+		"version": "1.10",
+		"padded":  " x ",
+		"note":    "it's: fine",
+		"script":  "echo a\necho b\n",
+		"ports":   []int{80, 443},
+		"env":     map[string]string{"A": "1"},
+		"empty":   []string{},
+		"z":       CommentedValue{Value: 1, Head: "last"},
+	}
+	y, err := MarshalWithOptions(v, DiffFriendly())
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := `empty: []
+
+env:
+    A: "1"
+
+name: web
+note: "it's: fine"
+padded: " x "
+
+ports:
+    - 80
+    - 443
+
+script: |
+    echo a
+    echo b
+version: "1.10"
+# last
+z: 1
+`
+	if string(y) != want {
+		t.Errorf("Marshal() = %s; want %s", y, want)
+	}
+
+	var back map[string]interface{}
+	if err := Unmarshal(y, &back); err != nil || back["padded"] != " x " || back["script"] != "echo a\necho b\n" {
+		t.Errorf("Unmarshal() = %v, %v", back, err)
+	}
+}
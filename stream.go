@@ -0,0 +1,205 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StreamOpt is an option for the streaming conversions.
+type StreamOpt func(*streamer)
+
+// Progress describes how far a streaming conversion has got.
+type Progress struct {
+	Bytes     int64 // bytes read from the input so far
+	Documents int   // documents written to the output so far
+}
+
+// OnProgress registers a function that is called each time more of the
+// input is read and after each document is written. Returning an error
+// stops the conversion, even part way through a document, and the error is
+// returned unchanged, so conversions can be cancelled.
+func OnProgress(fn func(Progress) error) StreamOpt {
+	return func(s *streamer) {
+		s.progress = fn
+	}
+}
+
+// streamer reads the input for a streaming conversion, reporting progress.
+type streamer struct {
+	r        io.Reader
+	progress func(Progress) error
+	p        Progress
+	err      error // error returned by progress, if any
+}
+
+func newStreamer(r io.Reader, opts []StreamOpt) *streamer {
+	s := &streamer{r: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *streamer) Read(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	n, err := s.r.Read(p)
+	s.p.Bytes += int64(n)
+	if n > 0 && s.report() != nil {
+		return n, s.err
+	}
+	return n, err
+}
+
+// done records a document as written.
+func (s *streamer) done() error {
+	s.p.Documents++
+	return s.report()
+}
+
+func (s *streamer) report() error {
+	if s.progress != nil && s.err == nil {
+		s.err = s.progress(s.p)
+	}
+	return s.err
+}
+
+// YAMLToJSONStream reads every YAML document from r and writes each one to
+// w as JSON on its own line, so streams with several documents become JSON
+// Lines. Documents are converted and written one at a time, straight from
+// the parsed tree without building the intermediate objects, so memory use
+// is bounded by the largest document rather than the size of the stream.
+func YAMLToJSONStream(w io.Writer, r io.Reader, opts ...StreamOpt) error {
+	s := newStreamer(r, opts)
+	dec := yaml.NewDecoder(s)
+	b := getBuffer()
+	defer putBuffer(b)
+	for {
+		n, err := decodeYAMLNode(dec)
+		if s.err != nil {
+			return s.err
+		}
+		if err != nil {
+			return fmt.Errorf("error converting YAML to JSON: %w", err)
+		}
+		if n == nil {
+			return nil
+		}
+		b.Reset()
+		if err := writeNodeJSON(b, n, nil); err != nil {
+			return fmt.Errorf("error converting YAML to JSON: %w", err)
+		}
+		b.WriteByte('\n')
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+		if err := s.done(); err != nil {
+			return err
+		}
+	}
+}
+
+// JSONToYAMLStream reads a sequence of JSON values from r, such as JSON
+// Lines, and writes each one to w as a separate YAML document. Values are
+// converted one at a time, so memory use is bounded by the largest value.
+func JSONToYAMLStream(w io.Writer, r io.Reader, opts ...StreamOpt) error {
+	s := newStreamer(r, opts)
+	dec := json.NewDecoder(s)
+	for i := 0; ; i++ {
+		var j json.RawMessage
+		err := dec.Decode(&j)
+		if s.err != nil {
+			return s.err
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("error reading JSON: %w", err)
+		}
+		if i > 0 {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if err := jsonToYAML(w, j); err != nil {
+			return fmt.Errorf("error converting JSON to YAML: %w", err)
+		}
+		if err := s.done(); err != nil {
+			return err
+		}
+	}
+}
+
+// appendNodeJSON writes the same JSON as the conversion through go-yaml
+// and json.Marshal directly from a node tree accepted by directNode.
+func appendNodeJSON(b *bytes.Buffer, n *yaml.Node) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			b.WriteString("null")
+			return
+		}
+		appendNodeJSON(b, n.Content[0])
+	case yaml.MappingNode:
+		order := sortedKeys(n)
+		b.WriteByte('{')
+		for i := 0; i < len(n.Content)/2; i++ {
+			p := i
+			if order != nil {
+				p = order[i]
+			}
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			appendJSONString(b, n.Content[2*p].Value)
+			b.WriteByte(':')
+			appendNodeJSON(b, n.Content[2*p+1])
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			appendNodeJSON(b, c)
+		}
+		b.WriteByte(']')
+	default:
+		switch l := literalOf(n); l.kind {
+		case nullLiteral:
+			b.WriteString("null")
+		case boolLiteral:
+			if l.b {
+				b.WriteString("true")
+			} else {
+				b.WriteString("false")
+			}
+		case stringLiteral:
+			appendJSONString(b, l.s)
+		default:
+			b.WriteString(l.number())
+		}
+	}
+}
+
+// appendJSONString writes the string quoted as by json.Marshal.
+func appendJSONString(b *bytes.Buffer, s string) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			// Leave escaping to encoding/json.
+			_ = encodeJSON(b, s)
+			return
+		}
+	}
+	b.WriteByte('"')
+	b.WriteString(s)
+	b.WriteByte('"')
+}
@@ -0,0 +1,154 @@
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAppendNodeJSON(t *testing.T) {
+	tests := append([]string{
+		"a: \"<tag> & \\\"quotes\\\"\"\nb: \"tab\\there\"\nc: é\n",
+		"- 1e3\n- -0.0\n- 1.0e-7\n- 123456789012345678901\n- 0b101\n",
+		"z: 1\ny: {c: [], b: {}, a: null}\n",
+	}, directTests...)
+	for _, y := range tests {
+		n, err := decodeYAMLNode(yaml.NewDecoder(strings.NewReader(y)))
+		if err != nil || n == nil || !directNode(n) {
+			continue
+		}
+		var obj interface{}
+		if err := n.Decode(&obj); err != nil {
+			t.Fatalf("Decode(%q) = %v", y, err)
+		}
+		obj, err = convertToJSONableObject(obj, nil)
+		if err != nil {
+			t.Fatalf("convertToJSONableObject(%q) = %v", y, err)
+		}
+		want, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("json.Marshal(%q) = %v", y, err)
+		}
+		var b bytes.Buffer
+		appendNodeJSON(&b, n)
+		if b.String() != string(want) {
+			t.Errorf("appendNodeJSON(%q) = %s; want %s", y, b.String(), want)
+		}
+	}
+}
+
+func TestYAMLToJSONStream(t *testing.T) {
+	tests := []struct {
+		y, want string
+	}{
+		{"", ""},
+		{"a: 1\n", "{\"a\":1}\n"},
+		{"a: 1\n---\n- x\n- &y y\n- *y\n---\n", "{\"a\":1}\n[\"x\",\"y\",\"y\"]\nnull\n"},
+		{"b: 2\na: {c: true}\n", "{\"a\":{\"c\":true},\"b\":2}\n"},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := YAMLToJSONStream(&b, strings.NewReader(test.y)); err != nil {
+			t.Errorf("YAMLToJSONStream(%q) = %v", test.y, err)
+		} else if b.String() != test.want {
+			t.Errorf("YAMLToJSONStream(%q) = %q; want %q", test.y, b.String(), test.want)
+		}
+	}
+
+	var b bytes.Buffer
+	err := YAMLToJSONStream(&b, strings.NewReader("a: 1\n---\na: 1\na: 2\n"))
+	if err == nil || !strings.Contains(err.Error(), "error converting YAML to JSON") {
+		t.Errorf("YAMLToJSONStream() = %v; want duplicate key error", err)
+	}
+	if b.String() != "{\"a\":1}\n" {
+		t.Errorf("YAMLToJSONStream() wrote %q; want the first document", b.String())
+	}
+}
+
+func TestJSONToYAMLStream(t *testing.T) {
+	tests := []struct {
+		j, want string
+	}{
+		{"", ""},
+		{"{\"a\":1}", "a: 1\n"},
+		{"{\"a\":1}\n[\"x\", 2]\nnull\n", "a: 1\n---\n- x\n- 2\n---\nnull\n"},
+		{"{\"b\":\"yes\"} {\"a\":1.5}", "b: \"yes\"\n---\na: 1.5\n"},
+	}
+	for _, test := range tests {
+		var b bytes.Buffer
+		if err := JSONToYAMLStream(&b, strings.NewReader(test.j)); err != nil {
+			t.Errorf("JSONToYAMLStream(%q) = %v", test.j, err)
+		} else if b.String() != test.want {
+			t.Errorf("JSONToYAMLStream(%q) = %q; want %q", test.j, b.String(), test.want)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := JSONToYAMLStream(&b, strings.NewReader("{\"a\":1}\n{")); err == nil {
+		t.Errorf("JSONToYAMLStream() = nil; want error for truncated JSON")
+	}
+}
+
+func TestStreamProgress(t *testing.T) {
+	y := strings.Repeat("a: 1\n---\n", 99) + "a: 1\n"
+	var reports []Progress
+	progress := OnProgress(func(p Progress) error {
+		reports = append(reports, p)
+		return nil
+	})
+	var b bytes.Buffer
+	if err := YAMLToJSONStream(&b, strings.NewReader(y), progress); err != nil {
+		t.Fatalf("YAMLToJSONStream() = %v", err)
+	}
+	last := reports[len(reports)-1]
+	if last.Bytes != int64(len(y)) || last.Documents != 100 {
+		t.Errorf("YAMLToJSONStream() last progress = %+v; want %d bytes and 100 documents", last, len(y))
+	}
+
+	reports = nil
+	if err := JSONToYAMLStream(&b, strings.NewReader(b.String()), progress); err != nil {
+		t.Fatalf("JSONToYAMLStream() = %v", err)
+	}
+	if last := reports[len(reports)-1]; last.Documents != 100 {
+		t.Errorf("JSONToYAMLStream() last progress = %+v; want 100 documents", last)
+	}
+}
+
+func TestStreamCancel(t *testing.T) {
+	errStop := errors.New("stop")
+	var b bytes.Buffer
+	err := YAMLToJSONStream(&b, strings.NewReader("a: 1\n---\nb: 2\n---\nc: 3\n"), OnProgress(func(p Progress) error {
+		if p.Documents == 2 {
+			return errStop
+		}
+		return nil
+	}))
+	if err != errStop {
+		t.Errorf("YAMLToJSONStream() = %v; want %v", err, errStop)
+	}
+	if b.String() != "{\"a\":1}\n{\"b\":2}\n" {
+		t.Errorf("YAMLToJSONStream() wrote %q; want the first two documents", b.String())
+	}
+
+	// Cancelling while reading stops before the document is finished.
+	long := "a:\n" + strings.Repeat("- item\n", 10000)
+	b.Reset()
+	err = JSONToYAMLStream(&b, strings.NewReader("[1]"), OnProgress(func(Progress) error { return errStop }))
+	if err != errStop || b.Len() != 0 {
+		t.Errorf("JSONToYAMLStream() = %v, wrote %q; want %v and nothing written", err, b.String(), errStop)
+	}
+	b.Reset()
+	err = YAMLToJSONStream(&b, strings.NewReader(long), OnProgress(func(p Progress) error {
+		if p.Bytes > 1000 {
+			return errStop
+		}
+		return nil
+	}))
+	if err != errStop || b.Len() != 0 {
+		t.Errorf("YAMLToJSONStream() = %v, wrote %q; want %v and nothing written", err, b.String(), errStop)
+	}
+}
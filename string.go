@@ -0,0 +1,15 @@
+package yaml
+
+// MarshalString behaves like Marshal but returns the YAML as a string.
+func MarshalString(o interface{}, opts ...EncodeOpt) (string, error) {
+	y, err := MarshalWithOptions(o, opts...)
+	if err != nil {
+		return "", err
+	}
+	return string(y), nil
+}
+
+// UnmarshalString behaves like Unmarshal but reads the YAML from a string.
+func UnmarshalString(y string, o interface{}, opts ...JSONOpt) error {
+	return Unmarshal([]byte(y), o, opts...)
+}
@@ -0,0 +1,27 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestMarshalString(t *testing.T) {
+	y, err := MarshalString(map[string]interface{}{"b": []int{1}, "a": "x"})
+	if err != nil || y != "a: x\nb:\n    - 1\n" {
+		t.Errorf("MarshalString() = %q, %v", y, err)
+	}
+	if _, err := MarshalString(func() {}); err == nil {
+		t.Errorf("MarshalString() = nil; want error")
+	}
+}
+
+func TestUnmarshalString(t *testing.T) {
+	var s struct {
+		A string `json:"a"`
+	}
+	if err := UnmarshalString("a: 1\n", &s); err != nil || s.A != "1" {
+		t.Errorf("UnmarshalString() = %+v, %v", s, err)
+	}
+	if err := UnmarshalString("a: 1\nb: 2\n", &s, DisallowUnknownFields); err == nil {
+		t.Errorf("UnmarshalString() = nil; want unknown field error")
+	}
+}
@@ -0,0 +1,162 @@
+package yaml
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// SubsetOpt configures how Subset matches the items of sequences.
+type SubsetOpt func(*subsetter)
+
+// ExactLists makes Subset require sequences to have as many items in the
+// document as in the subset, reporting the extra items as additions.
+func ExactLists() SubsetOpt {
+	return func(s *subsetter) {
+		s.exact = true
+	}
+}
+
+// UnorderedLists makes Subset match each item of a sequence in the subset
+// with any item of the document's sequence not matched already, rather
+// than the item at the same index.
+func UnorderedLists() SubsetOpt {
+	return func(s *subsetter) {
+		s.lists = listUnordered
+	}
+}
+
+// MatchListsByKey makes Subset match the items of sequences of mappings
+// that share the same value for the first of the keys they contain, for
+// example "name", as with MergeListsByKey. Items without any of the keys
+// are matched by index.
+func MatchListsByKey(keys ...string) SubsetOpt {
+	return func(s *subsetter) {
+		s.lists = listByKey
+		s.keys = keys
+	}
+}
+
+type listMatch int
+
+const (
+	listByIndex listMatch = iota
+	listUnordered
+	listByKey
+)
+
+// subsetter holds the strategy used to match sequences.
+type subsetter struct {
+	lists listMatch
+	keys  []string
+	exact bool
+}
+
+// Subset checks that every value in the sub document is also found in the
+// document, for example to compare the expected state of a resource with
+// the one reported by a live system, which usually holds more fields.
+// Mappings in the document may have more keys than in the sub document,
+// and by default sequences may have more items, compared by index.
+//
+// The differences returned are those needed to turn sub
+// into the document, leaving out additions: values missing from the
+// document are removals and differing values are replacements. Paths refer
+// to the sub document. There are no differences when sub is a subset of
+// the document. To check for a superset, swap the documents.
+func Subset(sub, doc []byte, opts ...SubsetOpt) ([]Difference, error) {
+	s := new(subsetter)
+	for _, opt := range opts {
+		opt(s)
+	}
+	sv, dv, err := diffValues(sub, doc)
+	if err != nil {
+		return nil, err
+	}
+	return s.compare(nil, sv, dv, nil), nil
+}
+
+func (s *subsetter) compare(path []string, a, b interface{}, out []Difference) []Difference {
+	switch at := a.(type) {
+	case map[string]interface{}:
+		bt, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, k := range unionKeys(at, nil) {
+			p := append(path[:len(path):len(path)], k)
+			if bv, ok := bt[k]; ok {
+				out = s.compare(p, at[k], bv, out)
+			} else {
+				out = append(out, Difference{Op: "remove", Path: formatPointer(p), From: at[k]})
+			}
+		}
+		return out
+	case []interface{}:
+		bt, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		return s.compareLists(path, at, bt, out)
+	}
+	if !reflect.DeepEqual(a, b) {
+		out = append(out, Difference{Op: "replace", Path: formatPointer(path), From: a, To: b})
+	}
+	return out
+}
+
+// compareLists compares the items of the sequences, matching them
+// following the strategy.
+func (s *subsetter) compareLists(path []string, a, b []interface{}, out []Difference) []Difference {
+	used := make([]bool, len(b))
+	for i, av := range a {
+		p := append(path[:len(path):len(path)], strconv.Itoa(i))
+		j := s.match(av, i, b, used)
+		if j < 0 {
+			out = append(out, Difference{Op: "remove", Path: formatPointer(p), From: av})
+			continue
+		}
+		used[j] = true
+		out = s.compare(p, av, b[j], out)
+	}
+	if s.exact {
+		for j, bv := range b {
+			if !used[j] {
+				p := append(path[:len(path):len(path)], strconv.Itoa(j))
+				out = append(out, Difference{Op: "add", Path: formatPointer(p), To: bv})
+			}
+		}
+	}
+	return out
+}
+
+// match returns the index of the item in b matching the item at index i
+// of the sub document, or -1 if there is none.
+func (s *subsetter) match(av interface{}, i int, b []interface{}, used []bool) int {
+	switch s.lists {
+	case listUnordered:
+		for j, bv := range b {
+			if !used[j] && len(s.compare(nil, av, bv, nil)) == 0 {
+				return j
+			}
+		}
+		return -1
+	case listByKey:
+		if am, ok := av.(map[string]interface{}); ok {
+			for _, k := range s.keys {
+				id, ok := am[k]
+				if !ok {
+					continue
+				}
+				for j, bv := range b {
+					if bm, ok := bv.(map[string]interface{}); ok && !used[j] && reflect.DeepEqual(bm[k], id) {
+						return j
+					}
+				}
+				return -1
+			}
+		}
+	}
+	if i < len(b) && !used[i] {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,76 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+const subsetDoc = `
+kind: Deployment
+metadata:
+  name: web
+  uid: 1234
+spec:
+  replicas: 2
+  ports: [80, 443]
+  containers:
+    - name: sidecar
+      image: proxy:1
+    - name: app
+      image: app:2
+      env: [{name: A, value: "1"}]
+`
+
+func TestSubset(t *testing.T) {
+	tests := []struct {
+		name string
+		sub  string
+		opts []SubsetOpt
+		want []string
+	}{
+		{"equal", subsetDoc, nil, nil},
+		{"subset", "kind: Deployment\nmetadata: {name: web}\nspec:\n  ports: [80]\n", nil, nil},
+		{
+			"differences",
+			"kind: Service\nmetadata: {name: web, labels: {a: b}}\nspec:\n  ports: [443]\n  replicas: [2]\n",
+			nil,
+			[]string{
+				"~ /kind: \"Service\" -> \"Deployment\"",
+				"- /metadata/labels: {\"a\":\"b\"}",
+				"~ /spec/ports/0: 443 -> 80",
+				"~ /spec/replicas: [2] -> 2",
+			},
+		},
+		{"unordered", "spec:\n  ports: [443, 80]\n", []SubsetOpt{UnorderedLists()}, nil},
+		{"unordered missing", "spec:\n  ports: [443, 443]\n", []SubsetOpt{UnorderedLists()}, []string{"- /spec/ports/1: 443"}},
+		{"exact", "spec:\n  ports: [80]\n", []SubsetOpt{ExactLists()}, []string{"+ /spec/ports/1: 443"}},
+		{
+			"by key",
+			"spec:\n  containers:\n    - name: app\n      image: app:3\n    - name: db\n",
+			[]SubsetOpt{MatchListsByKey("name")},
+			[]string{
+				"~ /spec/containers/0/image: \"app:3\" -> \"app:2\"",
+				"- /spec/containers/1: {\"name\":\"db\"}",
+			},
+		},
+		{"by index", "spec:\n  containers:\n    - name: app\n", nil, []string{"~ /spec/containers/0/name: \"app\" -> \"sidecar\""}},
+	}
+	for _, test := range tests {
+		diffs, err := Subset([]byte(test.sub), []byte(subsetDoc), test.opts...)
+		if err != nil {
+			t.Errorf("%s: Subset() = %v", test.name, err)
+			continue
+		}
+		var got []string
+		for _, d := range diffs {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Subset() = %q; want %q", test.name, got, test.want)
+		}
+	}
+
+	if _, err := Subset([]byte("a: [1"), []byte(subsetDoc)); err == nil {
+		t.Errorf("Subset() = nil error; want error")
+	}
+}
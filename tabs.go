@@ -0,0 +1,123 @@
+package yaml
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// TabError is returned when ReportTabs is enabled and the document cannot
+// be parsed because a line is indented with a tab, which YAML does not
+// allow.
+type TabError struct {
+	Line   int
+	Column int // column of the first tab
+}
+
+func (e *TabError) Error() string {
+	return positionPrefix(e.Line, e.Column) + "tab used for indentation"
+}
+
+// ReportTabs configures the decoder to report parser errors on lines that
+// are indented with tabs with a *TabError, instead of the parser's own
+// message, which does not mention tabs.
+func ReportTabs() DecodeOpt {
+	return func(d *Decoder) {
+		d.reportTabs = true
+	}
+}
+
+// ExpandTabs configures the decoder to replace tabs in the indentation of
+// every line with spaces before parsing, moving to the next multiple of
+// width, so that documents indented with tabs can be read. Tabs in the
+// indentation of block scalars are replaced too. A width below 1 is
+// treated as 1.
+func ExpandTabs(width int) DecodeOpt {
+	if width < 1 {
+		width = 1
+	}
+	return func(d *Decoder) {
+		d.tabWidth = width
+	}
+}
+
+// errorLine matches the line given in go-yaml parser errors.
+var errorLine = regexp.MustCompile(`^yaml: line ([0-9]+):`)
+
+// tabError converts the parser error into a *TabError when the line it
+// refers to is indented with a tab, using the source read so far.
+func tabError(err error, src []byte) error {
+	m := errorLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	lines := bytes.Split(src, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return err
+	}
+	text := lines[line-1]
+	for i, c := range text {
+		switch c {
+		case ' ':
+			continue
+		case '\t':
+			return &TabError{Line: line, Column: i + 1}
+		}
+		break
+	}
+	return err
+}
+
+// tabReader replaces the tabs in the indentation of each line with spaces.
+type tabReader struct {
+	r      io.Reader
+	width  int
+	indent bool // true while reading the indentation of a line
+	col    int  // column within the indentation
+	buf    []byte
+	out    []byte // expanded text not yet returned
+}
+
+func newTabReader(r io.Reader, width int) *tabReader {
+	return &tabReader{r: r, width: width, indent: true, buf: make([]byte, 4096)}
+}
+
+func (t *tabReader) Read(p []byte) (int, error) {
+	for len(t.out) == 0 {
+		n, err := t.r.Read(t.buf)
+		t.expand(t.buf[:n])
+		if err != nil && len(t.out) == 0 {
+			return 0, err
+		}
+		if n == 0 && err == nil {
+			break
+		}
+	}
+	n := copy(p, t.out)
+	t.out = t.out[n:]
+	return n, nil
+}
+
+func (t *tabReader) expand(b []byte) {
+	t.out = t.out[:0]
+	for _, c := range b {
+		switch {
+		case c == '\n':
+			t.indent, t.col = true, 0
+		case !t.indent:
+		case c == ' ':
+			t.col++
+		case c == '\t':
+			next := (t.col/t.width + 1) * t.width
+			for ; t.col < next; t.col++ {
+				t.out = append(t.out, ' ')
+			}
+			continue
+		default:
+			t.indent = false
+		}
+		t.out = append(t.out, c)
+	}
+}
@@ -0,0 +1,54 @@
+package yaml
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReportTabs(t *testing.T) {
+	var v interface{}
+	err := UnmarshalWithOptions([]byte("a:\n  b:\n \tc: 1\n"), &v, ReportTabs())
+	var tab *TabError
+	if !errors.As(err, &tab) || tab.Line != 3 || tab.Column != 2 {
+		t.Fatalf("UnmarshalWithOptions() = %v; want tab error at line 3, column 2", err)
+	}
+	if want := "line 3, column 2: tab used for indentation"; !strings.Contains(err.Error(), want) {
+		t.Errorf("UnmarshalWithOptions() = %q; want %q", err, want)
+	}
+
+	err = UnmarshalWithOptions([]byte("a: [1\n"), &v, ReportTabs())
+	if err == nil || errors.As(err, &tab) {
+		t.Errorf("UnmarshalWithOptions() = %v; want parser error", err)
+	}
+	if err := UnmarshalWithOptions([]byte("a:\tb\n"), &v, ReportTabs()); err != nil {
+		t.Errorf("UnmarshalWithOptions() = %v", err)
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	y := []byte("a:\n\tb: 1\n\tc:\n\t\t- \"\t\"\n\td: |\n\t\t\tx\n")
+	want := map[string]interface{}{"a": map[string]interface{}{
+		"b": 1.0,
+		"c": []interface{}{"\t"},
+		"d": "x\n",
+	}}
+	for _, width := range []int{0, 2, 8} {
+		var v interface{}
+		if err := UnmarshalWithOptions(y, &v, ExpandTabs(width)); err != nil {
+			t.Fatalf("UnmarshalWithOptions(ExpandTabs(%d)) = %v", width, err)
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("UnmarshalWithOptions(ExpandTabs(%d)) = %#v; want %#v", width, v, want)
+		}
+	}
+
+	r := newTabReader(iotest.OneByteReader(strings.NewReader("\ta\n \tb\tc\n")), 4)
+	b, err := io.ReadAll(r)
+	if err != nil || string(b) != "    a\n    b\tc\n" {
+		t.Errorf("tabReader = %q, %v", b, err)
+	}
+}
@@ -0,0 +1,125 @@
+package yaml
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TransformTemplate applies a transformation, such as Format or SortKeys,
+// to a document containing Go template actions, such as an un-rendered
+// Helm chart template. Actions are replaced by placeholders before fn is
+// called and restored in its output: those that fill a line of their own,
+// such as {{- if .Values.enabled }}, are replaced by comments, and the
+// others by plain scalars, so that they may appear as values, as keys, or
+// within strings.
+//
+// Transformations may move or re-indent placeholders like any other
+// scalar or comment, while actions filling a line are restored as they
+// were written.
+func TransformTemplate(src []byte, fn func([]byte) ([]byte, error)) ([]byte, error) {
+	m := maskTemplates(src)
+	out, err := fn(m.masked)
+	if err != nil {
+		return nil, err
+	}
+	return m.restore(out), nil
+}
+
+// AllowTemplates makes Lint accept Go template actions, checking the
+// document as TransformTemplate would pass it on. Positions are reported
+// in the original text, as long as actions don't span several lines.
+func AllowTemplates() LintOpt {
+	return func(l *linter) {
+		l.templates = true
+	}
+}
+
+// templateMask holds the template actions removed from a document.
+type templateMask struct {
+	masked  []byte
+	prefix  string
+	token   *regexp.Regexp
+	actions []string        // text replaced by each placeholder
+	lines   map[string]bool // placeholders standing for a whole line
+}
+
+// maskTemplates replaces the template actions in src with placeholders.
+func maskTemplates(src []byte) *templateMask {
+	m := &templateMask{prefix: "__tmpl", lines: make(map[string]bool)}
+	for bytes.Contains(src, []byte(m.prefix)) {
+		m.prefix = "_" + m.prefix
+	}
+	// Placeholders are padded to the length of the action they replace,
+	// so that the columns of the text that follows are kept.
+	m.token = regexp.MustCompile(regexp.QuoteMeta(m.prefix) + `_*([0-9]+)__`)
+
+	var b strings.Builder
+	s := string(src)
+	for {
+		i := strings.Index(s, "{{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(s[i+2:], "}}")
+		if j < 0 {
+			break
+		}
+		end := i + 2 + j + 2
+		b.WriteString(s[:i])
+		b.WriteString(m.placeholder(s[i:end]))
+		s = s[end:]
+	}
+	b.WriteString(s)
+
+	lines := strings.SplitAfter(b.String(), "\n")
+	for i, l := range lines {
+		text := strings.TrimRight(l, " \t\r\n")
+		body := strings.TrimLeft(text, " \t")
+		if body == "" || strings.TrimSpace(m.token.ReplaceAllString(body, "")) != "" {
+			continue
+		}
+		p := m.placeholder(m.restoreTokens(text))
+		m.lines[p] = true
+		lines[i] = text[:len(text)-len(body)] + "#" + p + l[len(text):]
+	}
+	m.masked = []byte(strings.Join(lines, ""))
+	return m
+}
+
+// placeholder returns a new placeholder for the text.
+func (m *templateMask) placeholder(text string) string {
+	n := strconv.Itoa(len(m.actions))
+	m.actions = append(m.actions, text)
+	pad := len(text) - len(m.prefix) - len(n) - 2
+	if pad < 0 || strings.Contains(text, "\n") {
+		pad = 0
+	}
+	return m.prefix + strings.Repeat("_", pad) + n + "__"
+}
+
+// action returns the text replaced by the placeholder.
+func (m *templateMask) action(p string) string {
+	n, _ := strconv.Atoi(m.token.FindStringSubmatch(p)[1])
+	return m.actions[n]
+}
+
+// restoreTokens replaces the placeholders in s by their actions.
+func (m *templateMask) restoreTokens(s string) string {
+	return m.token.ReplaceAllStringFunc(s, m.action)
+}
+
+// restore puts the template actions back in the output of a
+// transformation.
+func (m *templateMask) restore(out []byte) []byte {
+	lines := strings.SplitAfter(string(out), "\n")
+	for i, l := range lines {
+		text := strings.TrimRight(l, " \t\r\n")
+		body := strings.TrimLeft(text, " \t")
+		if strings.HasPrefix(body, "#") && m.lines[body[1:]] {
+			lines[i] = m.action(body[1:]) + l[len(text):]
+		}
+	}
+	return []byte(m.restoreTokens(strings.Join(lines, "")))
+}
@@ -0,0 +1,88 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const chartTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: {{ include "app.fullname" . }}
+    labels:
+        {{- include "app.labels" . | nindent 4 }}
+spec:
+    {{- if not .Values.autoscaling.enabled }}
+    replicas: {{ .Values.replicaCount }}
+    {{- end }}
+    template:
+        spec:
+            containers:
+                - name: "{{ .Chart.Name }}"
+                  image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+                  {{ .Values.extraKey }}: true
+`
+
+func TestTransformTemplate(t *testing.T) {
+	out, err := TransformTemplate([]byte(chartTemplate), Format)
+	if err != nil {
+		t.Fatalf("TransformTemplate() = %v", err)
+	}
+	want := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+        {{- include "app.labels" . | nindent 4 }}
+spec:
+    {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+    {{- end }}
+  template:
+    spec:
+      containers:
+        - name: "{{ .Chart.Name }}"
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          {{ .Values.extraKey }}: true
+`
+	if string(out) != want {
+		t.Errorf("TransformTemplate() =\n%s\nwant\n%s", out, want)
+	}
+
+	if _, err := Format([]byte(chartTemplate)); err == nil {
+		t.Errorf("Format() = nil; want error for the template")
+	}
+}
+
+func TestMaskTemplates(t *testing.T) {
+	src := "__tmpl0__: {{ .Values.a }}\nb: {{ .Values.long }} # {{ x }}\n  {{ if }}{{ end }}\n{{ unterminated\n"
+	m := maskTemplates([]byte(src))
+	if strings.Contains(string(m.masked), "{{ .") || !strings.Contains(string(m.masked), "{{ unterminated") {
+		t.Errorf("maskTemplates() = %q", m.masked)
+	}
+	// Padded placeholders keep the columns.
+	if i := strings.Index(string(m.masked), " # "); i != strings.Index(src, " # ") {
+		t.Errorf("maskTemplates() = %q; comment moved from %d to %d", m.masked, strings.Index(src, " # "), i)
+	}
+	if got := string(m.restore(m.masked)); got != src {
+		t.Errorf("restore() = %q; want %q", got, src)
+	}
+}
+
+func TestLintTemplates(t *testing.T) {
+	var got []string
+	for _, d := range Lint([]byte(chartTemplate+"on: yes\n"), AllowTemplates()) {
+		got = append(got, d.String())
+	}
+	want := []string{
+		"17:1: \"on\" is a boolean in YAML 1.1, quote it to keep it a string (ambiguous-scalar)",
+		"17:5: \"yes\" is a boolean in YAML 1.1, quote it to keep it a string (ambiguous-scalar)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if diags := Lint([]byte(chartTemplate)); len(diags) == 0 || diags[0].Rule != RuleSyntax {
+		t.Errorf("Lint() without AllowTemplates = %v; want syntax error", diags)
+	}
+}
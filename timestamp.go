@@ -0,0 +1,100 @@
+package yaml
+
+import (
+	"reflect"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// QuoteTimestamps quotes every string that a YAML 1.1 parser would read
+// as a timestamp, including times and dates formatted from time.Time, so
+// that other parsers keep them as strings. Most are already quoted, as
+// go-yaml reads them as timestamps too, but some forms, such as those with
+// a space before the time zone, are not.
+func QuoteTimestamps() EncodeOpt {
+	return func(e *encoder) {
+		e.quoteTimestamps = true
+	}
+}
+
+// timestamp11 matches the timestamps of YAML 1.1.
+var timestamp11 = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$|` +
+	`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}([Tt]|[ \t]+)[0-9]{1,2}:[0-9]{2}:[0-9]{2}(\.[0-9]*)?([ \t]*(Z|[-+][0-9]{1,2}(:[0-9]{2})?))?$`)
+
+// quoteTimestamps quotes the plain strings in the node tree that look like
+// timestamps.
+func quoteTimestamps(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		if n.Style == 0 && n.ShortTag() == "!!str" && timestamp11.MatchString(n.Value) {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+	case yaml.DocumentNode, yaml.MappingNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			quoteTimestamps(c)
+		}
+	}
+}
+
+// ExactTimestamps keeps timestamps decoded into strings and interfaces as
+// they were written, with the same fractional seconds and time zone,
+// rather than reformatting them in RFC 3339 with trailing zeros removed
+// and UTC assumed when no zone is given. Timestamps decoded into time.Time
+// keep their zone offset regardless.
+func ExactTimestamps() DecodeOpt {
+	return func(d *Decoder) {
+		d.retag.timestamps = true
+	}
+}
+
+// timestampTag provides the tag for timestamps that are not decoded into
+// a time.Time, so that they keep their text.
+func timestampTag(n *yaml.Node, t reflect.Type) string {
+	if n.Style != 0 || n.ShortTag() != "!!timestamp" {
+		return ""
+	}
+	if t != nil && t.Kind() != reflect.String && t.Kind() != reflect.Interface {
+		return ""
+	}
+	if t != nil && t.Kind() == reflect.String && (unmarshalsJSON(t) || reflect.PtrTo(t).Implements(textUnmarshalerType)) {
+		return ""
+	}
+	return "!!str"
+}
+
+// TimestampLayout writes time.Time values using the layout, as accepted
+// by time.Format, instead of time.RFC3339Nano, for example to always give
+// three fractional digits. The time zone of each value is kept.
+func TimestampLayout(layout string) EncodeOpt {
+	return func(e *encoder) {
+		e.timeLayout = layout
+	}
+}
+
+// applyTimeLayout rewrites the time.Time values in the node tree generated
+// for the value using the layout.
+func applyTimeLayout(n *yaml.Node, v reflect.Value, layout string) {
+	walkValue(v, func(path []string, v reflect.Value, f *field) bool {
+		if v.Type() != timeType {
+			return true
+		}
+		if f != nil && f.format == FormatDate {
+			return false
+		}
+		target := n
+		for _, p := range path {
+			if target, _ = childNode(target, p); target == nil {
+				return false
+			}
+		}
+		if target.Kind == yaml.DocumentNode && len(target.Content) > 0 {
+			target = target.Content[0]
+		}
+		if target.Kind == yaml.ScalarNode {
+			target.Value = v.Interface().(time.Time).Format(layout)
+		}
+		return false
+	})
+}
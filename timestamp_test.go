@@ -0,0 +1,72 @@
+package yaml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuoteTimestamps(t *testing.T) {
+	v := map[string]interface{}{
+		"created":               time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		"spaced":                "2001-12-14 21:59:43.10 -5",
+		"lower":                 "2001-12-14t21:59:43.10-05:00",
+		"date":                  "2002-12-14",
+		"version":               "2002-12",
+		"2001-12-14 21:59:43 Z": "key",
+	}
+	y, err := MarshalWithOptions(v, QuoteTimestamps())
+	if err != nil {
+		t.Fatalf("MarshalWithOptions() = %v", err)
+	}
+	want := `"2001-12-14 21:59:43 Z": key
+created: "2024-01-02T03:04:05Z"
+date: "2002-12-14"
+lower: "2001-12-14t21:59:43.10-05:00"
+spaced: "2001-12-14 21:59:43.10 -5"
+version: 2002-12
+`
+	if string(y) != want {
+		t.Errorf("MarshalWithOptions() = %q; want %q", y, want)
+	}
+
+	y, _ = Marshal(map[string]string{"spaced": "2001-12-14 21:59:43.10 -5"})
+	if string(y) != "spaced: 2001-12-14 21:59:43.10 -5\n" {
+		t.Errorf("Marshal() = %q; want the timestamp plain without the option", y)
+	}
+}
+
+func TestExactTimestamps(t *testing.T) {
+	y := []byte("s: 2024-01-02T03:04:05.120+05:30\nlocal: 2001-12-14 21:59:43.10\nany: [2024-01-02T03:04:05.500Z]\nt: 2024-01-02T03:04:05.120+05:30\n")
+	var s struct {
+		S     string      `json:"s"`
+		Local string      `json:"local"`
+		Any   interface{} `json:"any"`
+		T     time.Time   `json:"t"`
+	}
+	if err := UnmarshalWithOptions(y, &s, ExactTimestamps()); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	if s.S != "2024-01-02T03:04:05.120+05:30" || s.Local != "2001-12-14 21:59:43.10" {
+		t.Errorf("UnmarshalWithOptions() = %q, %q; want the text kept", s.S, s.Local)
+	}
+	if a, _ := s.Any.([]interface{}); len(a) != 1 || a[0] != "2024-01-02T03:04:05.500Z" {
+		t.Errorf("UnmarshalWithOptions() any = %#v", s.Any)
+	}
+	if _, offset := s.T.Zone(); offset != 5*3600+30*60 || s.T.Nanosecond() != 120000000 {
+		t.Errorf("UnmarshalWithOptions() t = %v; want the zone and fraction kept", s.T)
+	}
+
+	out, err := MarshalWithOptions(struct {
+		T time.Time `json:"t"`
+	}{s.T}, TimestampLayout("2006-01-02T15:04:05.000Z07:00"))
+	if err != nil || string(out) != "t: \"2024-01-02T03:04:05.120+05:30\"\n" {
+		t.Errorf("MarshalWithOptions() = %q, %v", out, err)
+	}
+
+	var plain struct {
+		S string `json:"s"`
+	}
+	if err := UnmarshalWithOptions(y, &plain); err != nil || plain.S != "2024-01-02T03:04:05.12+05:30" {
+		t.Errorf("UnmarshalWithOptions() = %q, %v; want RFC 3339 without the option", plain.S, err)
+	}
+}
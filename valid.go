@@ -0,0 +1,43 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Valid reports whether the data is a syntactically valid YAML stream,
+// mirroring json.Valid. It is cheaper than a full decode and is intended
+// for screening input.
+func Valid(y []byte) bool {
+	dec := yaml.NewDecoder(bytes.NewReader(y))
+	for {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			return errors.Is(err, io.EOF)
+		}
+	}
+}
+
+// ValidStrict checks that every document in the YAML stream could be
+// converted by this package, returning the first problem found. As well as
+// syntax errors such as bad indentation or undefined aliases, this reports
+// duplicate keys and keys that can't be represented in JSON.
+func ValidStrict(y []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(y))
+	for i := 0; ; i++ {
+		var obj interface{}
+		if err := dec.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("document %d: %v", i, err)
+		}
+		if _, err := convertToJSONableObject(obj, nil); err != nil {
+			return fmt.Errorf("document %d: %v", i, err)
+		}
+	}
+}
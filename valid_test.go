@@ -0,0 +1,35 @@
+package yaml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for _, tc := range []struct {
+		y      string
+		valid  bool
+		strict string
+	}{
+		{"", true, ""},
+		{"a: 1\n---\nb: [1, 2]\n", true, ""},
+		{`{"json": true}`, true, ""},
+		{"a: 1\na: 2\n", true, `key "a" already defined`},
+		{"a: 1\n---\n? [1, 2]\n: x\n", true, "document 1: yaml: invalid map key"},
+		{"~: x\n", true, "document 0: unsupported map key"},
+		{"a: *missing\n", false, "unknown anchor"},
+		{"a:\n  b: 1\n c: 2\n", false, "document 0: yaml: line 2: did not find expected key"},
+		{"a: [1, 2\n", false, "did not find expected"},
+	} {
+		if got := Valid([]byte(tc.y)); got != tc.valid {
+			t.Errorf("Valid(%q) = %v; want %v", tc.y, got, tc.valid)
+		}
+		err := ValidStrict([]byte(tc.y))
+		switch {
+		case tc.strict == "" && err != nil:
+			t.Errorf("ValidStrict(%q) = %v; want nil", tc.y, err)
+		case tc.strict != "" && (err == nil || !strings.Contains(err.Error(), tc.strict)):
+			t.Errorf("ValidStrict(%q) = %v; want %q", tc.y, err, tc.strict)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package yaml
+
+import (
+	"errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WalkFunc is called by Walk for every value in the tree along with its
+// path expression, as accepted by Query. The node's position in the
+// source is available from its Line and Column fields. The node may be
+// modified or replaced in place (e.g. `*n = *replacement`) before Walk
+// continues into its children.
+//
+// Returning SkipNode prevents Walk from descending into the node, while
+// DeleteNode removes the node, and its key if any, from the parent. Any
+// other error stops the walk and is returned.
+type WalkFunc func(path string, n *yaml.Node) error
+
+// SkipNode may be returned by a WalkFunc to skip the node's children.
+var SkipNode = errors.New("skip this node") //nolint:revive
+
+// DeleteNode may be returned by a WalkFunc to remove the node.
+var DeleteNode = errors.New("delete this node") //nolint:revive
+
+// Walk visits every value in the document in order, starting with the
+// root, calling fn for each. Keys are not visited directly, and aliases
+// are not followed.
+func (d *Document) Walk(fn WalkFunc) error {
+	return Walk(d.root, fn)
+}
+
+// Walk visits the node tree in order, calling fn for every value. If the
+// root is deleted, document nodes will be left empty.
+func Walk(n *yaml.Node, fn WalkFunc) error {
+	if n.Kind == yaml.DocumentNode {
+		if len(n.Content) == 0 {
+			return nil
+		}
+		del, err := walkNode(n.Content[0], nil, fn)
+		if del {
+			n.Content = nil
+		}
+		return err
+	}
+	_, err := walkNode(n, nil, fn)
+	return err
+}
+
+// walkNode visits the node and its children, returning true if the node
+// should be deleted.
+func walkNode(n *yaml.Node, path []interface{}, fn WalkFunc) (bool, error) {
+	switch err := fn(formatPath(path), n); {
+	case errors.Is(err, DeleteNode):
+		return true, nil
+	case errors.Is(err, SkipNode):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		content := n.Content[:0]
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			del, err := walkNode(v, append(path, k.Value), fn)
+			if err != nil {
+				// Keep the deletions made so far, and the rest unvisited.
+				n.Content = append(content, n.Content[i:]...)
+				return false, err
+			}
+			if !del {
+				content = append(content, k, v)
+			}
+		}
+		n.Content = content
+	case yaml.SequenceNode:
+		content := n.Content[:0]
+		for i, v := range n.Content {
+			del, err := walkNode(v, append(path, i), fn)
+			if err != nil {
+				n.Content = append(content, n.Content[i:]...)
+				return false, err
+			}
+			if !del {
+				content = append(content, v)
+			}
+		}
+		n.Content = content
+	}
+	return false, nil
+}
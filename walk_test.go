@@ -0,0 +1,143 @@
+package yaml
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDocumentWalk(t *testing.T) {
+	src := `# config
+name: web # the name
+secrets:
+  token: abc
+ports:
+  - 80
+  - 8080
+  - 443
+`
+	d, err := ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	var paths []string
+	err = d.Walk(func(path string, n *yaml.Node) error {
+		paths = append(paths, path)
+		switch {
+		case path == "secrets":
+			return SkipNode
+		case path == "ports[1]":
+			return DeleteNode
+		case path == "name":
+			if n.Line != 2 || n.Column != 7 {
+				t.Errorf("position of %s = %d:%d; want 2:7", path, n.Line, n.Column)
+			}
+			n.Value = "api"
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() = %v", err)
+	}
+	if want := []string{"", "name", "secrets", "ports", "ports[0]", "ports[1]", "ports[2]"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Walk() paths = %q; want %q", paths, want)
+	}
+
+	out, err := d.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() = %v", err)
+	}
+	want := "# config\nname: api # the name\nsecrets:\n  token: abc\nports:\n  - 80\n  - 443\n"
+	if string(out) != want {
+		t.Errorf("Bytes() = %q; want %q", out, want)
+	}
+}
+
+func TestWalkError(t *testing.T) {
+	d, err := ParseDocument([]byte("a: 1\nb: 2\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	stop := errors.New("stop")
+	var count int
+	err = d.Walk(func(path string, n *yaml.Node) error {
+		count++
+		if path == "a" {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) || count != 2 {
+		t.Errorf("Walk() = %v after %d calls; want stop after 2", err, count)
+	}
+
+	// Deleting the root empties the document.
+	if err := d.Walk(func(string, *yaml.Node) error { return DeleteNode }); err != nil {
+		t.Fatalf("Walk() = %v", err)
+	}
+	if out, _ := d.Bytes(); len(out) != 0 {
+		t.Errorf("Bytes() = %q; want empty", out)
+	}
+}
+
+func TestWalkDeleteThenError(t *testing.T) {
+	d, err := ParseDocument([]byte("a: 1\nb: 2\nc: 3\nd: 4\nl: [1, 2, 3, 4]\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	stop := errors.New("stop")
+	err = d.Walk(func(path string, n *yaml.Node) error {
+		switch path {
+		case "a", "l[0]":
+			return DeleteNode
+		case "c", "l[2]":
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("Walk() = %v; want stop", err)
+	}
+	out, _ := d.Bytes()
+	if want := "b: 2\nc: 3\nd: 4\nl: [1, 2, 3, 4]\n"; string(out) != want {
+		t.Errorf("Bytes() = %q; want %q", out, want)
+	}
+
+	l, _ := ParseDocument([]byte("[1, 2, 3, 4]\n"))
+	err = l.Walk(func(path string, n *yaml.Node) error {
+		switch path {
+		case "[0]":
+			return DeleteNode
+		case "[2]":
+			return stop
+		}
+		return nil
+	})
+	out, _ = l.Bytes()
+	if want := "[2, 3, 4]\n"; !errors.Is(err, stop) || string(out) != want {
+		t.Errorf("Walk() = %v, Bytes() = %q; want stop and %q", err, out, want)
+	}
+}
+
+func TestDocumentQuery(t *testing.T) {
+	d, err := ParseDocument([]byte("items:\n  - name: a\n  - name: b\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() = %v", err)
+	}
+	nodes, err := d.Query("items[*].name")
+	if err != nil {
+		t.Fatalf("Query() = %v", err)
+	}
+	for _, n := range nodes {
+		n.Value += "x"
+	}
+	out, err := d.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() = %v", err)
+	}
+	if want := "items:\n  - name: ax\n  - name: bx\n"; string(out) != want {
+		t.Errorf("Bytes() = %q; want %q", out, want)
+	}
+}
@@ -0,0 +1,130 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Warning is a non-fatal notice about a document being decoded, such as a
+// tag that is ignored or a value that is coerced into another type.
+type Warning struct {
+	Path    string // path to the value, as used by Query
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the warning with its position.
+func (w Warning) String() string {
+	if w.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", w.Line, w.Column, w.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", w.Line, w.Column, w.Path, w.Message)
+}
+
+// Warner receives the warnings produced while decoding.
+type Warner interface {
+	Warn(w Warning)
+}
+
+// WarnerFunc allows a function to be used as a Warner.
+type WarnerFunc func(w Warning)
+
+// Warn calls the function.
+func (f WarnerFunc) Warn(w Warning) {
+	f(w)
+}
+
+// ReportWarnings configures the decoder to send warnings to the Warner,
+// which include tags that are ignored, scalars coerced into strings or
+// keys converted into strings to match JSON, and plain scalars that YAML
+// 1.1 parsers interpret differently.
+func ReportWarnings(w Warner) DecodeOpt {
+	return func(d *Decoder) {
+		d.warner = w
+	}
+}
+
+// knownTags are the tags understood by the conversion to JSON.
+var knownTags = map[string]bool{
+	"!!str": true, "!!int": true, "!!float": true, "!!bool": true, "!!null": true,
+	"!!map": true, "!!seq": true, "!!timestamp": true, "!!binary": true, "!!merge": true,
+}
+
+// warnNode reports warnings for the node, which will be decoded into a
+// value of the type if it is known.
+func warnNode(w Warner, n *yaml.Node, t reflect.Type, path []interface{}) {
+	warn := func(n *yaml.Node, format string, args ...interface{}) {
+		w.Warn(Warning{
+			Path:    formatPath(path),
+			Line:    n.Line,
+			Column:  n.Column,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && (unmarshalsJSON(t) || reflect.PtrTo(t).Implements(textUnmarshalerType)) {
+		t = nil
+	}
+	if n.Kind != yaml.DocumentNode && n.Kind != yaml.AliasNode && n.Tag != "" && !knownTags[n.Tag] {
+		warn(n, "unknown tag %s ignored", n.Tag)
+	}
+
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			warnNode(w, c, t, path)
+		}
+	case yaml.ScalarNode:
+		if msg := ambiguousScalar(n); msg != "" {
+			warn(n, "%s", msg)
+		}
+		switch n.Tag {
+		case "!!int", "!!float", "!!bool":
+			if t != nil && t.Kind() == reflect.String {
+				warn(n, "%s %s coerced to string", strings.TrimPrefix(n.Tag, "!!"), n.Value)
+			}
+		}
+	case yaml.MappingNode:
+		var plan *typePlan
+		if t != nil && t.Kind() == reflect.Struct {
+			plan = cachedTypePlan(t)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			p := append(path[:len(path):len(path)], k.Value)
+			switch k.Tag {
+			case "!!int", "!!float", "!!bool":
+				w.Warn(Warning{
+					Path:    formatPath(p),
+					Line:    k.Line,
+					Column:  k.Column,
+					Message: fmt.Sprintf("%s key %s converted to string", strings.TrimPrefix(k.Tag, "!!"), k.Value),
+				})
+			}
+			var vt reflect.Type
+			switch {
+			case plan != nil:
+				if f := plan.field(k.Value); f != nil {
+					vt = f.typ
+				}
+			case t != nil && t.Kind() == reflect.Map:
+				vt = t.Elem()
+			}
+			warnNode(w, v, vt, p)
+		}
+	case yaml.SequenceNode:
+		var et reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			et = t.Elem()
+		}
+		for i, c := range n.Content {
+			warnNode(w, c, et, append(path[:len(path):len(path)], i))
+		}
+	}
+}
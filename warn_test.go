@@ -0,0 +1,54 @@
+package yaml
+
+import (
+	"testing"
+)
+
+func TestReportWarnings(t *testing.T) {
+	y := []byte(`name: 123
+enabled: yes
+port: !port 8080
+ports:
+  1: http
+labels:
+  version: 1.5
+`)
+	var s struct {
+		Name    string            `json:"name"`
+		Enabled string            `json:"enabled"`
+		Port    string            `json:"port"`
+		Ports   map[string]string `json:"ports"`
+		Labels  map[string]string `json:"labels"`
+	}
+	var got []string
+	w := WarnerFunc(func(w Warning) {
+		got = append(got, w.String())
+	})
+	if err := UnmarshalWithOptions(y, &s, ReportWarnings(w)); err != nil {
+		t.Fatalf("UnmarshalWithOptions() = %v", err)
+	}
+	want := []string{
+		"1:7: name: int 123 coerced to string",
+		`2:10: enabled: "yes" is a boolean in YAML 1.1, quote it to keep it a string`,
+		"3:7: port: unknown tag !port ignored",
+		"5:3: ports.1: int key 1 converted to string",
+		"7:12: labels.version: float 1.5 coerced to string",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("warnings = %q; want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning %d = %q; want %q", i, got[i], want[i])
+		}
+	}
+
+	// Converting without a target only reports the tags and keys.
+	got = nil
+	if _, err := YAMLToJSONWithOptions(y, ReportWarnings(w)); err != nil {
+		t.Fatalf("YAMLToJSONWithOptions() = %v", err)
+	}
+	if len(got) != 3 {
+		t.Errorf("YAMLToJSONWithOptions() warnings = %q; want 3", got)
+	}
+}
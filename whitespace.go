@@ -0,0 +1,106 @@
+package yaml
+
+import "io"
+
+// whitespaceOpts holds the changes made to the source before parsing.
+type whitespaceOpts struct {
+	trailing    bool
+	lineEndings bool
+	formFeeds   bool
+}
+
+func (o whitespaceOpts) set() bool {
+	return o.trailing || o.lineEndings || o.formFeeds
+}
+
+// TrimTrailingSpace configures the decoder to remove spaces and tabs at the
+// end of every line before parsing, including lines inside block scalars,
+// whose content would otherwise keep them.
+func TrimTrailingSpace() DecodeOpt {
+	return func(d *Decoder) {
+		d.whitespace.trailing = true
+	}
+}
+
+// NormalizeLineEndings configures the decoder to convert Windows and old
+// Mac line endings into "\n" before parsing, so that line breaks inside
+// quoted strings are read the same way on every platform.
+func NormalizeLineEndings() DecodeOpt {
+	return func(d *Decoder) {
+		d.whitespace.lineEndings = true
+	}
+}
+
+// StripFormFeeds configures the decoder to remove form feed characters
+// before parsing, which the parser otherwise rejects as control
+// characters.
+func StripFormFeeds() DecodeOpt {
+	return func(d *Decoder) {
+		d.whitespace.formFeeds = true
+	}
+}
+
+// whitespaceReader applies the whitespace options to the source.
+type whitespaceReader struct {
+	r       io.Reader
+	opts    whitespaceOpts
+	skipLF  bool   // true after converting "\r", so that a "\n" is dropped
+	pending []byte // spaces and tabs that may be at the end of a line
+	buf     []byte
+	out     []byte // cleaned text not yet returned
+}
+
+func newWhitespaceReader(r io.Reader, opts whitespaceOpts) *whitespaceReader {
+	return &whitespaceReader{r: r, opts: opts, buf: make([]byte, 4096)}
+}
+
+func (w *whitespaceReader) Read(p []byte) (int, error) {
+	for len(w.out) == 0 {
+		n, err := w.r.Read(w.buf)
+		w.clean(w.buf[:n])
+		if err != nil && len(w.out) == 0 {
+			// Spaces left pending at the end of the input are dropped.
+			return 0, err
+		}
+		if n == 0 && err == nil {
+			break
+		}
+	}
+	n := copy(p, w.out)
+	w.out = w.out[n:]
+	return n, nil
+}
+
+func (w *whitespaceReader) clean(b []byte) {
+	w.out = w.out[:0]
+	for _, c := range b {
+		if w.opts.lineEndings {
+			if w.skipLF {
+				w.skipLF = false
+				if c == '\n' {
+					continue
+				}
+			}
+			if c == '\r' {
+				c, w.skipLF = '\n', true
+			}
+		}
+		if c == '\f' && w.opts.formFeeds {
+			continue
+		}
+		if !w.opts.trailing {
+			w.out = append(w.out, c)
+			continue
+		}
+		switch c {
+		case ' ', '\t':
+			w.pending = append(w.pending, c)
+			continue
+		case '\n', '\r':
+		default:
+			w.out = append(w.out, w.pending...)
+		}
+		w.pending = w.pending[:0]
+		w.out = append(w.out, c)
+	}
+}
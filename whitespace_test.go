@@ -0,0 +1,56 @@
+package yaml
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestWhitespaceOptions(t *testing.T) {
+	tests := []struct {
+		y    string
+		opts []DecodeOpt
+		want interface{}
+	}{
+		{"a: |\n  x  \n  y\t\n", []DecodeOpt{TrimTrailingSpace()}, map[string]interface{}{"a": "x\ny\n"}},
+		{"a: \"x\r\n  y\"\r\n", []DecodeOpt{NormalizeLineEndings()}, map[string]interface{}{"a": "x y"}},
+		{"a: |\r  x \r  y\r", []DecodeOpt{NormalizeLineEndings(), TrimTrailingSpace()}, map[string]interface{}{"a": "x\ny\n"}},
+		{"a: 1\n\f\nb: 2\f\n", []DecodeOpt{StripFormFeeds()}, map[string]interface{}{"a": 1.0, "b": 2.0}},
+	}
+	for _, test := range tests {
+		var v interface{}
+		if err := UnmarshalWithOptions([]byte(test.y), &v, test.opts...); err != nil {
+			t.Fatalf("UnmarshalWithOptions(%q) = %v", test.y, err)
+		}
+		if !reflect.DeepEqual(v, test.want) {
+			t.Errorf("UnmarshalWithOptions(%q) = %#v; want %#v", test.y, v, test.want)
+		}
+	}
+
+	var v interface{}
+	if err := UnmarshalWithOptions([]byte("a: 1\n\f\n"), &v); err == nil {
+		t.Errorf("UnmarshalWithOptions() = nil; want control character error")
+	}
+}
+
+func TestWhitespaceReader(t *testing.T) {
+	tests := []struct {
+		in   string
+		opts whitespaceOpts
+		want string
+	}{
+		{"a \t\r\nb\r\rc  ", whitespaceOpts{trailing: true}, "a\r\nb\r\rc"},
+		{"a \t\r\nb\r\rc  ", whitespaceOpts{trailing: true, lineEndings: true}, "a\nb\n\nc"},
+		{"a  b \f\n", whitespaceOpts{trailing: true, formFeeds: true}, "a  b\n"},
+		{"a\r\n\fb", whitespaceOpts{lineEndings: true}, "a\n\fb"},
+	}
+	for _, test := range tests {
+		r := newWhitespaceReader(iotest.OneByteReader(strings.NewReader(test.in)), test.opts)
+		b, err := io.ReadAll(r)
+		if err != nil || string(b) != test.want {
+			t.Errorf("whitespaceReader(%q, %+v) = %q, %v; want %q", test.in, test.opts, b, err, test.want)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"io"
 	"reflect"
 	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -23,17 +24,91 @@ import (
 // Marshal the object into JSON then converts JSON to YAML and returns the
 // YAML.
 func Marshal(o interface{}) ([]byte, error) {
-	j, err := json.Marshal(o)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling into JSON: %v", err)
+	return MarshalWithOptions(o)
+}
+
+// MarshalWithOptions behaves like Marshal but accepts options configuring
+// the output.
+func MarshalWithOptions(o interface{}, opts ...EncodeOpt) ([]byte, error) {
+	if m := currentMetrics(); m != nil {
+		start := time.Now()
+		y, err := marshal(o, opts)
+		observeEncode(m, start, y, err)
+		return y, err
+	}
+	return marshal(o, opts)
+}
+
+// MarshalAppend marshals the object like Marshal and appends the YAML to
+// dst, returning the extended slice. Reusing the slice between calls avoids
+// allocating a new one for every document.
+func MarshalAppend(dst []byte, o interface{}, opts ...EncodeOpt) ([]byte, error) {
+	if m := currentMetrics(); m != nil {
+		start := time.Now()
+		y, err := marshalAppend(dst, o, opts)
+		observeEncode(m, start, y[len(dst):], err)
+		return y, err
+	}
+	return marshalAppend(dst, o, opts)
+}
+
+func marshal(o interface{}, opts []EncodeOpt) ([]byte, error) {
+	return marshalAppend(nil, o, opts)
+}
+
+func marshalAppend(dst []byte, o interface{}, opts []EncodeOpt) ([]byte, error) {
+	e := newEncoder(opts)
+	if e.err != nil {
+		return dst, e.err
+	}
+
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := e.encodeJSON(b, o); err != nil {
+		return dst, fmt.Errorf("error marshaling into JSON: %v", err)
+	}
+	j := b.Bytes()
+
+	w := &appendWriter{b: dst}
+	if !e.needsNode(o) {
+		if err := jsonToYAML(w, j); err != nil {
+			return dst, fmt.Errorf("error converting JSON to YAML: %v", err)
+		}
+		return w.b, nil
 	}
 
-	y, err := JSONToYAML(j)
+	// Comments and other changes can only be applied to the node tree.
+	n, err := jsonToNode(j)
 	if err != nil {
-		return nil, fmt.Errorf("error converting JSON to YAML: %v", err)
+		return dst, fmt.Errorf("error converting JSON to YAML: %v", err)
+	}
+	if err := e.apply(n, o); err != nil {
+		return dst, err
+	}
+	if err := encodeYAML(w, n); err != nil {
+		return dst, err
 	}
+	return w.b, nil
+}
+
+// appendWriter appends everything written to the slice.
+type appendWriter struct {
+	b []byte
+}
 
-	return y, nil
+func (w *appendWriter) Write(p []byte) (int, error) {
+	w.b = append(w.b, p...)
+	return len(p), nil
+}
+
+// encodeYAML writes the value to w as a single YAML document, producing the
+// same output as yaml.Marshal.
+func encodeYAML(w io.Writer, v interface{}) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
 }
 
 // JSONOpt is a decoding option for decoding from JSON format.
@@ -42,56 +117,102 @@ type JSONOpt func(*json.Decoder) *json.Decoder
 // Unmarshal converts YAML to JSON then uses JSON to unmarshal into an object,
 // optionally configuring the behavior of the JSON unmarshal.
 func Unmarshal(y []byte, o interface{}, opts ...JSONOpt) error {
+	t := startDecode(currentMetrics())
 	dec := yaml.NewDecoder(bytes.NewReader(y))
-	return unmarshal(dec, o, opts)
+	err := unmarshal(dec, o, opts, t)
+	t.done(len(y), err)
+	return err
 }
 
-func unmarshal(dec *yaml.Decoder, o interface{}, opts []JSONOpt) error {
-	vo := reflect.ValueOf(o)
-	j, err := yamlToJSON(dec, &vo)
+func unmarshal(dec *yaml.Decoder, o interface{}, opts []JSONOpt, t *decodeTracker) error {
+	n, err := decodeYAMLNode(dec)
+	t.document(n)
 	if err != nil {
-		return fmt.Errorf("error converting YAML to JSON: %v", err)
+		return fmt.Errorf("error converting YAML to JSON: %w", err)
+	}
+	defer retagTarget(n, reflect.TypeOf(o), retagOpts{})()
+	if knownFields, ok := directOpts(opts); ok && canDecodeDirect(n, o) {
+		if err := decodeDirect(n, o, directConfig{knownFields: knownFields}); err != nil {
+			return fmt.Errorf("error unmarshaling JSON: %w", err)
+		}
+		setPositions(n, o)
+		return nil
+	}
+	vo := reflect.ValueOf(o)
+	b := getBuffer()
+	defer putBuffer(b)
+	if err := writeNodeJSON(b, n, &vo); err != nil {
+		return fmt.Errorf("error converting YAML to JSON: %w", err)
 	}
 
-	err = jsonUnmarshal(bytes.NewReader(j), o, opts...)
-	if err != nil {
-		return fmt.Errorf("error unmarshaling JSON: %v", err)
+	j := b.Bytes()
+	if err := jsonUnmarshal(j, n, o, opts...); err != nil {
+		return fmt.Errorf("error unmarshaling JSON: %w", err)
 	}
+	setPositions(n, o)
 
 	return nil
 }
 
-// jsonUnmarshal unmarshals the JSON byte stream from the given reader into the
+// jsonUnmarshal unmarshals the JSON converted from the node into the
 // object, optionally applying decoder options prior to decoding.  We are not
 // using json.Unmarshal directly as we want the chance to pass in non-default
-// options.
-func jsonUnmarshal(r io.Reader, o interface{}, opts ...JSONOpt) error {
-	d := json.NewDecoder(r)
+// options. Errors are located in the node before being wrapped, so that
+// their messages include the position.
+func jsonUnmarshal(j []byte, n *yaml.Node, o interface{}, opts ...JSONOpt) error {
+	d := json.NewDecoder(bytes.NewReader(j))
 	for _, opt := range opts {
 		d = opt(d)
 	}
 	if err := d.Decode(&o); err != nil {
-		return fmt.Errorf("while decoding JSON: %v", err)
+		return fmt.Errorf("while decoding JSON: %w", locateError(convertJSONError(err), n, j, reflect.TypeOf(o)))
 	}
 	return nil
 }
 
 // JSONToYAML converts JSON to YAML.
 func JSONToYAML(j []byte) ([]byte, error) {
+	w := new(appendWriter)
+	if err := jsonToYAML(w, j); err != nil {
+		return nil, err
+	}
+	return w.b, nil
+}
+
+func jsonToYAML(w io.Writer, j []byte) error {
 	// Convert the JSON to an object.
-	var jsonObj interface{}
 	// We are using yaml.Unmarshal here (instead of json.Unmarshal) because the
 	// Go JSON library doesn't try to pick the right number type (int, float,
 	// etc.) when unmarshalling to interface{}, it just picks float64
 	// universally. go-yaml does go through the effort of picking the right
 	// number type, so we can preserve number type throughout this process.
-	err := yaml.Unmarshal(j, &jsonObj)
-	if err != nil {
-		return nil, err
+	// When encoding/json/v2 is available, parseJSON follows the same rules
+	// without the YAML parser.
+	jsonObj, ok := parseJSON(j)
+	if !ok {
+		if err := yaml.Unmarshal(j, &jsonObj); err != nil {
+			return err
+		}
 	}
 
 	// Marshal this object into YAML.
-	return yaml.Marshal(jsonObj)
+	return encodeYAML(w, jsonObj)
+}
+
+// jsonToNode converts JSON to a YAML node tree, following the same rules as
+// JSONToYAML.
+func jsonToNode(j []byte) (*yaml.Node, error) {
+	jsonObj, ok := parseJSON(j)
+	if !ok {
+		if err := yaml.Unmarshal(j, &jsonObj); err != nil {
+			return nil, err
+		}
+	}
+	n := new(yaml.Node)
+	if err := n.Encode(jsonObj); err != nil {
+		return nil, err
+	}
+	return n, nil
 }
 
 // YAMLToJSON converts YAML to JSON. Since JSON is a subset of YAML,
@@ -106,21 +227,95 @@ func JSONToYAML(j []byte) ([]byte, error) {
 //   encoded data makes it all the way through to the JSON.
 //
 func YAMLToJSON(y []byte) ([]byte, error) { //nolint:revive
+	t := startDecode(currentMetrics())
 	dec := yaml.NewDecoder(bytes.NewReader(y))
-	return yamlToJSON(dec, nil)
+	j, err := yamlToJSON(dec, nil, t)
+	t.done(len(y), err)
+	return j, err
 }
 
-func yamlToJSON(dec *yaml.Decoder, jsonTarget *reflect.Value) ([]byte, error) {
-	// Convert the YAML to an object.
-	var yamlObj interface{}
-	if err := dec.Decode(&yamlObj); err != nil {
+func yamlToJSON(dec *yaml.Decoder, jsonTarget *reflect.Value, t *decodeTracker) ([]byte, error) {
+	n, err := decodeYAMLNode(dec)
+	t.document(n)
+	if err != nil {
+		return nil, err
+	}
+	return yamlNodeToJSON(n, jsonTarget)
+}
+
+// decodeYAMLNode reads the next document from the decoder, returning nil
+// at the end of the stream. Going through the node tree means duplicate
+// keys and later conversion errors can be reported with their position.
+func decodeYAMLNode(dec *yaml.Decoder) (*yaml.Node, error) {
+	n := new(yaml.Node)
+	if err := dec.Decode(n); err != nil {
 		// Functionality changed in v3 which means we need to ignore EOF error.
 		// See https://github.com/go-yaml/yaml/issues/639
 		if !errors.Is(err, io.EOF) {
 			return nil, err
 		}
+		return nil, nil
+	}
+	if err := checkDuplicateKeys(n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// yamlNodeToJSON converts the node, which may be nil for an empty
+// document, into JSON.
+func yamlNodeToJSON(n *yaml.Node, jsonTarget *reflect.Value) ([]byte, error) {
+	if jsonTarget == nil && n != nil && directNode(n) {
+		b := getBuffer()
+		defer putBuffer(b)
+		appendNodeJSON(b, n)
+		return append([]byte(nil), b.Bytes()...), nil
+	}
+	yamlObj, err := decodeNodeObject(n)
+	if err != nil {
+		return nil, err
+	}
+	return yamlObjectToJSON(yamlObj, jsonTarget)
+}
+
+// decodeNodeObject decodes the node, which may be nil, into the value
+// go-yaml produces for an empty interface. Where possible the value is built
+// straight from the tree, with maps and slices allocated at their final size.
+func decodeNodeObject(n *yaml.Node) (interface{}, error) {
+	if n == nil {
+		return nil, nil
+	}
+	if directNode(n) {
+		return nodeObject(n), nil
+	}
+	var yamlObj interface{}
+	err := n.Decode(&yamlObj)
+	return yamlObj, err
+}
+
+// writeNodeJSON writes the JSON for the node, which may be nil for an empty
+// document, to the buffer.
+func writeNodeJSON(b *bytes.Buffer, n *yaml.Node, jsonTarget *reflect.Value) error {
+	// Without a target no values are coerced, so the JSON can be written
+	// straight from the tree.
+	if jsonTarget == nil && n != nil && directNode(n) {
+		appendNodeJSON(b, n)
+		return nil
+	}
+	yamlObj, err := decodeNodeObject(n)
+	if err != nil {
+		return err
+	}
+	jsonObj, err := convertToJSONableObject(yamlObj, jsonTarget)
+	if err != nil {
+		return err
 	}
+	return encodeJSON(b, jsonObj)
+}
 
+// yamlObjectToJSON converts a value decoded by go-yaml into JSON, using the
+// optional target to decide when scalars should be coerced into strings.
+func yamlObjectToJSON(yamlObj interface{}, jsonTarget *reflect.Value) ([]byte, error) {
 	// YAML objects are not completely compatible with JSON objects (e.g. you
 	// can have non-string keys in YAML). So, convert the YAML-compatible object
 	// to a JSON-compatible object, failing with an error if irrecoverable
@@ -145,7 +340,7 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 		ju, tu, pv := indirect(*jsonTarget, false)
 		// We have a JSON or Text Umarshaler at this level, so we can't be trying
 		// to decode into a string.
-		if ju != nil || tu != nil {
+		if ju != nil || tu != nil || unmarshalsJSON(pv.Type()) {
 			jsonTarget = nil
 		} else {
 			jsonTarget = &pv
@@ -162,7 +357,7 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 		// From my reading of go-yaml v2 (specifically the resolve function),
 		// keys can only have the types string, int, int64, float64, binary
 		// (unsupported), or null (unsupported).
-		strMap := make(map[string]interface{})
+		strMap := make(map[string]interface{}, len(typedYAMLObj))
 		for k, v := range typedYAMLObj {
 			// Resolve the key to a string first.
 			var keyString string
@@ -202,51 +397,40 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 	// field back into this function.
 	switch typedYAMLObj := yamlObj.(type) {
 	case map[string]interface{}:
+		// jsonTarget should be a struct or a map. If it's a struct, find
+		// the field each key is going to map to and pass its reflect.Value.
+		// If it's a map, pass a zero value of the map's element type, which
+		// is the same for every key. If it's neither, just pass nil - JSON
+		// conversion will error for us if it's a real issue.
+		var plan *typePlan
+		var jtv reflect.Value
+		if jsonTarget != nil {
+			switch t := *jsonTarget; t.Kind() {
+			case reflect.Struct:
+				plan = cachedTypePlan(t.Type())
+			case reflect.Map:
+				jtv = reflect.Zero(t.Type().Elem())
+			}
+		}
 		for k, v := range typedYAMLObj {
-
-			// jsonTarget should be a struct or a map. If it's a struct, find
-			// the field it's going to map to and pass its reflect.Value. If
-			// it's a map, find the element type of the map and pass the
-			// reflect.Value created from that type. If it's neither, just pass
-			// nil - JSON conversion will error for us if it's a real issue.
-			if jsonTarget != nil {
-				t := *jsonTarget
-				if t.Kind() == reflect.Struct {
-					keyBytes := []byte(k)
-					// Find the field that the JSON library would use.
-					var f *field
-					fields := cachedTypeFields(t.Type())
-					for i := range fields {
-						ff := &fields[i]
-						if bytes.Equal(ff.nameBytes, keyBytes) {
-							f = ff
-							break
-						}
-						// Do case-insensitive comparison.
-						if f == nil && ff.equalFold(ff.nameBytes, keyBytes) {
-							f = ff
-						}
-					}
-					if f != nil {
-						// Find the reflect.Value of the most preferential
-						// struct field.
-						jtf := t.Field(f.index[0])
-						typedYAMLObj[k], err = convertToJSONableObject(v, &jtf)
-						if err != nil {
-							return nil, err
-						}
-						continue
-					}
-				} else if t.Kind() == reflect.Map {
-					// Create a zero value of the map's element type to use as
-					// the JSON target.
-					jtv := reflect.Zero(t.Type().Elem())
-					typedYAMLObj[k], err = convertToJSONableObject(v, &jtv)
+			if plan != nil {
+				// Find the field that the JSON library would use.
+				if f := plan.field(k); f != nil {
+					// Find the reflect.Value of the most preferential
+					// struct field.
+					jtf := (*jsonTarget).Field(f.index[0])
+					typedYAMLObj[k], err = convertToJSONableObject(v, &jtf)
 					if err != nil {
 						return nil, err
 					}
 					continue
 				}
+			} else if jtv.IsValid() {
+				typedYAMLObj[k], err = convertToJSONableObject(v, &jtv)
+				if err != nil {
+					return nil, err
+				}
+				continue
 			}
 			typedYAMLObj[k], err = convertToJSONableObject(v, nil)
 			if err != nil {
@@ -273,15 +457,14 @@ func convertToJSONableObject(yamlObj interface{}, jsonTarget *reflect.Value) (in
 			}
 		}
 
-		// Make and use a new array.
-		arr := make([]interface{}, len(typedYAMLObj))
+		// Convert the elements in place, as is done for maps.
 		for i, v := range typedYAMLObj {
-			arr[i], err = convertToJSONableObject(v, jsonSliceElemValue)
+			typedYAMLObj[i], err = convertToJSONableObject(v, jsonSliceElemValue)
 			if err != nil {
 				return nil, err
 			}
 		}
-		return arr, nil
+		return typedYAMLObj, nil
 	default:
 		// If the target type is a string and the YAML type is a number,
 		// convert the YAML type to a string.
@@ -10,6 +10,10 @@ import (
 	"testing"
 )
 
+// Marshal keeps its original signature so it can still be used as a
+// plain function value.
+var _ func(interface{}) ([]byte, error) = Marshal
+
 type MarshalTest struct {
 	A string
 	B int64
@@ -34,7 +38,34 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
-type UnmarshalString struct {
+func TestMarshalAppend(t *testing.T) {
+	s := MarshalTest{A: "a", B: 1}
+	want, _ := MarshalWithOptions(s, RedactSecrets("***"))
+
+	buf := make([]byte, 0, 256)
+	buf = append(buf, "# header\n"...)
+	y, err := MarshalAppend(buf, s, RedactSecrets("***"))
+	if err != nil {
+		t.Fatalf("MarshalAppend() = %v", err)
+	}
+	if string(y) != "# header\n"+string(want) {
+		t.Errorf("MarshalAppend() = %q; want %q", y, "# header\n"+string(want))
+	}
+	if &y[0] != &buf[:1][0] {
+		t.Errorf("MarshalAppend() allocated a new slice despite enough capacity")
+	}
+
+	y, err = MarshalAppend(y[:0], s)
+	if want, _ := Marshal(s); err != nil || string(y) != string(want) {
+		t.Errorf("MarshalAppend() = %q, %v; want %q", y, err, want)
+	}
+
+	if _, err := MarshalAppend(buf, make(chan int)); err == nil {
+		t.Errorf("MarshalAppend(chan) = nil; want error")
+	}
+}
+
+type UnmarshalStringStruct struct {
 	A string
 	B string
 }
@@ -62,33 +93,33 @@ type NestedSlice struct {
 
 func TestUnmarshal(t *testing.T) {
 	y := []byte(``)
-	s1 := UnmarshalString{}
-	e1 := UnmarshalString{}
+	s1 := UnmarshalStringStruct{}
+	e1 := UnmarshalStringStruct{}
 	unmarshalEqual(t, y, &s1, &e1)
 
 	y = []byte(`{}`)
-	s1 = UnmarshalString{}
-	e1 = UnmarshalString{}
+	s1 = UnmarshalStringStruct{}
+	e1 = UnmarshalStringStruct{}
 	unmarshalEqual(t, y, &s1, &e1)
 
 	y = []byte("a: 1")
-	s1 = UnmarshalString{}
-	e1 = UnmarshalString{A: "1"}
+	s1 = UnmarshalStringStruct{}
+	e1 = UnmarshalStringStruct{A: "1"}
 	unmarshalEqual(t, y, &s1, &e1)
 
 	y = []byte(`a: "1"`)
-	s1 = UnmarshalString{}
-	e1 = UnmarshalString{A: "1"}
+	s1 = UnmarshalStringStruct{}
+	e1 = UnmarshalStringStruct{A: "1"}
 	unmarshalEqual(t, y, &s1, &e1)
 
 	y = []byte("a: true")
-	s1 = UnmarshalString{}
-	e1 = UnmarshalString{A: "true"}
+	s1 = UnmarshalStringStruct{}
+	e1 = UnmarshalStringStruct{A: "true"}
 	unmarshalEqual(t, y, &s1, &e1)
 
 	y = []byte("a: 1")
-	s1 = UnmarshalString{}
-	e1 = UnmarshalString{A: "1"}
+	s1 = UnmarshalStringStruct{}
+	e1 = UnmarshalStringStruct{A: "1"}
 	unmarshalEqual(t, y, &s1, &e1)
 
 	y = []byte("a:\n  a: 1")
@@ -127,34 +158,34 @@ b:
 func TestUnmarshalNonStrict(t *testing.T) {
 	for _, tc := range []struct {
 		yaml []byte
-		want UnmarshalString
+		want UnmarshalStringStruct
 	}{
 		{
 			yaml: []byte("a: 1"),
-			want: UnmarshalString{A: "1"},
+			want: UnmarshalStringStruct{A: "1"},
 		},
 		{
 			// Order does not matter.
 			yaml: []byte("b: 1\na: 2"),
-			want: UnmarshalString{A: "2", B: "1"},
+			want: UnmarshalStringStruct{A: "2", B: "1"},
 		},
 		{
 			// Unknown field get ignored.
 			yaml: []byte("a: 1\nunknownField: 2"),
-			want: UnmarshalString{A: "1"},
+			want: UnmarshalStringStruct{A: "1"},
 		},
 		{
 			// Unknown fields get ignored.
 			yaml: []byte("unknownOne: 2\na: 1\nunknownTwo: 2"),
-			want: UnmarshalString{A: "1"},
+			want: UnmarshalStringStruct{A: "1"},
 		},
 		{
 			// In YAML, `YES` is no longer Boolean true.
 			yaml: []byte("a: YES"),
-			want: UnmarshalString{A: "YES"},
+			want: UnmarshalStringStruct{A: "YES"},
 		},
 	} {
-		s := UnmarshalString{}
+		s := UnmarshalStringStruct{}
 		unmarshalEqual(t, tc.yaml, &s, &tc.want)
 	}
 }
@@ -204,7 +235,7 @@ func TestUnmarshalErrors(t *testing.T) {
 			wantErr: `key "true" already defined`,
 		},
 	} {
-		s := UnmarshalString{}
+		s := UnmarshalStringStruct{}
 		err := Unmarshal(tc.yaml, &s)
 		if tc.wantErr != "" && err == nil {
 			t.Errorf("Unmarshal(%#q, &s) = nil; want error", string(tc.yaml))
@@ -0,0 +1,190 @@
+// Package yamlbench provides benchmarks and allocation checks for the
+// conversion pipeline of the yaml package, covering small, medium and
+// pathological inputs. Dependents can run them in their own CI to catch
+// performance regressions when upgrading:
+//
+//	func BenchmarkYAML(b *testing.B) { yamlbench.Benchmark(b) }
+//	func TestYAMLAllocs(t *testing.T) { yamlbench.CheckAllocs(t) }
+package yamlbench
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/invopop/yaml"
+)
+
+// Case is an input document with the maximum number of allocations each
+// operation is expected to make when processing it. The limits leave some
+// headroom over the measured counts to allow for differences between Go
+// releases.
+type Case struct {
+	Name      string
+	Input     []byte
+	MaxAllocs map[string]float64 // by operation name
+}
+
+// Operation is a step of the conversion pipeline exercised by the cases.
+type Operation struct {
+	Name string
+	Run  func(in []byte, v interface{}) error
+}
+
+// Operations lists the operations measured for every case. Marshal encodes
+// the value produced by unmarshaling the input.
+var Operations = []Operation{
+	{"Unmarshal", func(in []byte, _ interface{}) error {
+		var v interface{}
+		return yaml.Unmarshal(in, &v)
+	}},
+	{"Marshal", func(_ []byte, v interface{}) error {
+		_, err := yaml.Marshal(v)
+		return err
+	}},
+	{"YAMLToJSON", func(in []byte, _ interface{}) error {
+		_, err := yaml.YAMLToJSON(in)
+		return err
+	}},
+}
+
+// Cases lists the inputs used by the benchmarks.
+var Cases = []Case{
+	{
+		Name:      "Small",
+		Input:     []byte("name: web\nreplicas: 2\nenabled: true\ntags: [a, b]\n"),
+		MaxAllocs: map[string]float64{"Unmarshal": 250, "Marshal": 250, "YAMLToJSON": 200},
+	},
+	{
+		Name:      "Medium",
+		Input:     medium(),
+		MaxAllocs: map[string]float64{"Unmarshal": 9500, "Marshal": 9500, "YAMLToJSON": 8000},
+	},
+	{
+		Name:      "DeeplyNested",
+		Input:     nested(100),
+		MaxAllocs: map[string]float64{"Unmarshal": 3200, "Marshal": 3200, "YAMLToJSON": 2400},
+	},
+	{
+		Name:      "Aliases",
+		Input:     aliases(4),
+		MaxAllocs: map[string]float64{"Unmarshal": 6000, "Marshal": 6500, "YAMLToJSON": 3600},
+	},
+	{
+		Name:      "LongStrings",
+		Input:     longStrings(20, 4096),
+		MaxAllocs: map[string]float64{"Unmarshal": 11500, "Marshal": 21500, "YAMLToJSON": 11000},
+	},
+}
+
+// Benchmark runs a sub-benchmark for every operation and case.
+func Benchmark(b *testing.B) {
+	for _, c := range Cases {
+		v := decode(b, c)
+		for _, op := range Operations {
+			c, op := c, op
+			b.Run(op.Name+"/"+c.Name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(len(c.Input)))
+				for i := 0; i < b.N; i++ {
+					if err := op.Run(c.Input, v); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// CheckAllocs reports an error for every operation and case that makes
+// more allocations than allowed.
+func CheckAllocs(t *testing.T) {
+	for _, c := range Cases {
+		v := decode(t, c)
+		for _, op := range Operations {
+			max, ok := c.MaxAllocs[op.Name]
+			if !ok {
+				continue
+			}
+			var err error
+			allocs := testing.AllocsPerRun(10, func() {
+				err = op.Run(c.Input, v)
+			})
+			if err != nil {
+				t.Errorf("%s/%s: %v", op.Name, c.Name, err)
+				continue
+			}
+			if allocs > max {
+				t.Errorf("%s/%s: %.0f allocations; want at most %.0f", op.Name, c.Name, allocs, max)
+			}
+		}
+	}
+}
+
+func decode(tb testing.TB, c Case) interface{} {
+	tb.Helper()
+	var v interface{}
+	if err := yaml.Unmarshal(c.Input, &v); err != nil {
+		tb.Fatalf("%s: %v", c.Name, err)
+	}
+	return v
+}
+
+// medium generates a set of deployment-like documents merged into a list.
+func medium() []byte {
+	var b strings.Builder
+	b.WriteString("services:\n")
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&b, `  - name: service-%d
+    image: registry.example.com/team/service-%d:1.%d.0
+    replicas: %d
+    resources:
+      cpu: 0.5
+      memory: 512Mi
+    env:
+      - name: LOG_LEVEL
+        value: info
+      - name: PORT
+        value: "80%02d"
+    ports: [80, 443]
+    enabled: true
+`, i, i, i, i%5+1, i)
+	}
+	return []byte(b.String())
+}
+
+// nested generates mappings nested to the depth.
+func nested(depth int) []byte {
+	var b strings.Builder
+	for i := 0; i < depth; i++ {
+		fmt.Fprintf(&b, "%sl%d:\n", strings.Repeat(" ", i), i)
+	}
+	fmt.Fprintf(&b, "%svalue: leaf\n", strings.Repeat(" ", depth))
+	return []byte(b.String())
+}
+
+// aliases generates levels of aliases that each reference the previous
+// level several times, so the expanded document is much larger than the
+// input.
+func aliases(levels int) []byte {
+	var b strings.Builder
+	b.WriteString("l0: &l0 [a, b, c]\n")
+	for i := 1; i <= levels; i++ {
+		fmt.Fprintf(&b, "l%d: &l%d [*l%d, *l%d, *l%d]\n", i, i, i-1, i-1, i-1)
+	}
+	return []byte(b.String())
+}
+
+// longStrings generates a mapping of long folded and plain strings.
+func longStrings(n, size int) []byte {
+	var b strings.Builder
+	line := strings.Repeat("lorem ipsum ", size/12)
+	for i := 0; i < n; i++ {
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "k%d: %s\n", i, line)
+		} else {
+			fmt.Fprintf(&b, "k%d: >\n  %s\n", i, line)
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,16 @@
+package yamlbench
+
+import (
+	"testing"
+)
+
+func BenchmarkPipeline(b *testing.B) {
+	Benchmark(b)
+}
+
+func TestCheckAllocs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("allocation checks are slow")
+	}
+	CheckAllocs(t)
+}
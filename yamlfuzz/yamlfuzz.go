@@ -0,0 +1,160 @@
+// Package yamlfuzz provides fuzz targets and differential checks for the
+// yaml package, so projects that depend on it can run them against their
+// own corpora of documents.
+//
+// A typical fuzz test looks like:
+//
+//	func FuzzConfig(f *testing.F) {
+//		seeds, err := yamlfuzz.LoadCorpus("testdata/configs")
+//		if err != nil {
+//			f.Fatal(err)
+//		}
+//		yamlfuzz.Run(f, yamlfuzz.RoundTrip, seeds...)
+//	}
+package yamlfuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/invopop/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Target checks a single input, returning an error when it finds a bug.
+// Inputs that are not valid YAML, or that cannot be represented as JSON,
+// are ignored.
+type Target func(data []byte) error
+
+// Targets lists the available checks by name.
+var Targets = map[string]Target{
+	"roundtrip":    RoundTrip,
+	"differential": Differential,
+}
+
+// RoundTrip ensures that converting the document to JSON, back to YAML and
+// to JSON again produces the same JSON.
+func RoundTrip(data []byte) error {
+	j1, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil
+	}
+	y, err := yaml.JSONToYAML(j1)
+	if err != nil {
+		return fmt.Errorf("JSONToYAML(%q): %v", j1, err)
+	}
+	j2, err := yaml.YAMLToJSON(y)
+	if err != nil {
+		return fmt.Errorf("YAMLToJSON(%q): %v", y, err)
+	}
+	if !jsonEqual(j1, j2, true) {
+		return fmt.Errorf("round trip mismatch:\n%s\n%s", j1, j2)
+	}
+	return nil
+}
+
+// Differential ensures that Unmarshal into an interface{} produces the same
+// value as decoding the document with go-yaml directly.
+func Differential(data []byte) error {
+	var want interface{}
+	if err := yamlv3.Unmarshal(data, &want); err != nil {
+		return nil
+	}
+	wj, err := json.Marshal(normalize(want))
+	if err != nil {
+		// Values such as NaN, binary data or complex keys cannot be
+		// compared.
+		return nil
+	}
+
+	var got interface{}
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		return nil
+	}
+	gj, err := json.Marshal(got)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(%#v): %v", got, err)
+	}
+	// Unmarshal decodes numbers into float64 as encoding/json does, so
+	// only compare them with that precision.
+	if !jsonEqual(wj, gj, false) {
+		return fmt.Errorf("differential mismatch:\ngo-yaml: %s\nyaml:    %s", wj, gj)
+	}
+	return nil
+}
+
+// Run adds the seeds to the fuzz test and fuzzes the target.
+func Run(f *testing.F, target Target, seeds ...[]byte) {
+	f.Helper()
+	for _, s := range seeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := target(data); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// LoadCorpus reads every regular file in the directory, for use as seeds.
+func LoadCorpus(dir string) ([][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var corpus [][]byte
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		corpus = append(corpus, data)
+	}
+	return corpus, nil
+}
+
+// normalize converts the maps produced by go-yaml into the string keyed
+// maps used by JSON.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = normalize(e)
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range t {
+			t[i] = normalize(e)
+		}
+	}
+	return v
+}
+
+// jsonEqual compares two JSON documents, optionally comparing numbers
+// exactly rather than as float64.
+func jsonEqual(a, b []byte, exact bool) bool {
+	var va, vb interface{}
+	da := json.NewDecoder(bytes.NewReader(a))
+	db := json.NewDecoder(bytes.NewReader(b))
+	if exact {
+		da.UseNumber()
+		db.UseNumber()
+	}
+	if da.Decode(&va) != nil || db.Decode(&vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,39 @@
+package yamlfuzz
+
+import (
+	"testing"
+)
+
+var seeds = [][]byte{
+	[]byte("a: 1\nb: [x, y]\n"),
+	[]byte("- 1.5\n- true\n- null\n- ~\n"),
+	[]byte("1: one\n2.5: two\ntrue: three\n"),
+	[]byte("base: &b {a: 1}\nc:\n  <<: *b\n  d: 2\n"),
+	[]byte("s: |\n  multi\n  line\n"),
+	[]byte("big: 12345678901234567890\n"),
+	[]byte("a: [\n"),
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	Run(f, RoundTrip, seeds...)
+}
+
+func FuzzDifferential(f *testing.F) {
+	Run(f, Differential, seeds...)
+}
+
+func TestTargets(t *testing.T) {
+	for name, target := range Targets {
+		for _, s := range seeds {
+			if err := target(s); err != nil {
+				t.Errorf("%s(%q) = %v", name, s, err)
+			}
+		}
+	}
+}
+
+func TestLoadCorpus(t *testing.T) {
+	if _, err := LoadCorpus("testdata/missing"); err == nil {
+		t.Errorf("LoadCorpus(missing) = nil; want error")
+	}
+}
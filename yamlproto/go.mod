@@ -0,0 +1,15 @@
+module github.com/invopop/yaml/yamlproto
+
+go 1.18
+
+require (
+	github.com/invopop/yaml v0.0.0
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0 // indirect
+)
+
+replace github.com/invopop/yaml => ../
@@ -0,0 +1,53 @@
+// Package yamlproto reads and writes protocol buffer messages as YAML,
+// such as configurations defined in .proto files. Messages are converted
+// using protojson, so that fields are named and enums, well-known types and
+// 64-bit integers are written as in the protobuf JSON mapping, and then
+// converted between JSON and YAML by the yaml package.
+package yamlproto
+
+import (
+	"encoding/json"
+
+	"github.com/invopop/yaml"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Marshal writes the message as YAML, with fields named by their JSON
+// names and the options applied as by yaml.MarshalWithOptions.
+func Marshal(m proto.Message, opts ...yaml.EncodeOpt) ([]byte, error) {
+	return MarshalOptions{}.Marshal(m, opts...)
+}
+
+// Unmarshal reads the YAML document into the message, which is reset
+// first. Fields may be given by their JSON or their proto names, and enums
+// by their names or numbers. Unknown fields are rejected.
+func Unmarshal(y []byte, m proto.Message, opts ...yaml.DecodeOpt) error {
+	return UnmarshalOptions{}.Unmarshal(y, m, opts...)
+}
+
+// MarshalOptions configures the conversion of messages to JSON before
+// they are written as YAML.
+type MarshalOptions protojson.MarshalOptions
+
+// Marshal writes the message as YAML using the options.
+func (o MarshalOptions) Marshal(m proto.Message, opts ...yaml.EncodeOpt) ([]byte, error) {
+	j, err := protojson.MarshalOptions(o).Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.MarshalWithOptions(json.RawMessage(j), opts...)
+}
+
+// UnmarshalOptions configures the conversion of messages from the JSON
+// that YAML documents are converted into.
+type UnmarshalOptions protojson.UnmarshalOptions
+
+// Unmarshal reads the YAML document into the message using the options.
+func (o UnmarshalOptions) Unmarshal(y []byte, m proto.Message, opts ...yaml.DecodeOpt) error {
+	j, err := yaml.YAMLToJSONWithOptions(y, opts...)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions(o).Unmarshal(j, m)
+}
@@ -0,0 +1,78 @@
+package yamlproto
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestMarshal(t *testing.T) {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("parent"),
+		Number:   proto.Int32(2),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		TypeName: proto.String(".app.Node"),
+	}
+	y, err := Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := "label: LABEL_OPTIONAL\nname: parent\nnumber: 2\ntype: TYPE_MESSAGE\ntypeName: .app.Node\n"
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+
+	y, err = MarshalOptions{UseProtoNames: true, UseEnumNumbers: true}.Marshal(f)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want = "label: 1\nname: parent\nnumber: 2\ntype: 11\ntype_name: .app.Node\n"
+	if string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+
+	y, err = Marshal(durationpb.New(90 * time.Second))
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if want := "90s\n"; string(y) != want {
+		t.Errorf("Marshal() = %q; want %q", y, want)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var f descriptorpb.FieldDescriptorProto
+	y := "name: parent\nnumber: 2\nlabel: LABEL_REPEATED\ntype: 11\ntype_name: .app.Node\n"
+	if err := Unmarshal([]byte(y), &f); err != nil {
+		t.Fatalf("Unmarshal() = %v", err)
+	}
+	want := &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String("parent"),
+		Number:   proto.Int32(2),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+		TypeName: proto.String(".app.Node"),
+	}
+	if !proto.Equal(&f, want) {
+		t.Errorf("Unmarshal() = %v; want %v", &f, want)
+	}
+
+	if err := Unmarshal([]byte("name: x\nunknown: 1\n"), &f); err == nil {
+		t.Errorf("Unmarshal(unknown field) = nil; want error")
+	}
+	if err := (UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte("name: x\nunknown: 1\n"), &f); err != nil {
+		t.Errorf("Unmarshal(DiscardUnknown) = %v", err)
+	}
+	if f.GetName() != "x" || f.Number != nil {
+		t.Errorf("Unmarshal() did not reset the message: %v", &f)
+	}
+
+	var d durationpb.Duration
+	if err := Unmarshal([]byte("1.5s\n"), &d); err != nil || d.AsDuration() != 1500*time.Millisecond {
+		t.Errorf("Unmarshal(duration) = %v, %v", d.AsDuration(), err)
+	}
+}
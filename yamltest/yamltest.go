@@ -0,0 +1,117 @@
+// Package yamltest provides helpers for testing code that produces YAML,
+// comparing documents by their content rather than their formatting.
+package yamltest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/invopop/yaml"
+)
+
+// Update causes Golden to write the output to the golden files instead of
+// comparing against them. It is enabled by setting the YAMLTEST_UPDATE
+// environment variable.
+var Update = os.Getenv("YAMLTEST_UPDATE") != ""
+
+// Equal reports an error if the two documents do not contain the same
+// data, ignoring formatting, comments and key order.
+func Equal(t testing.TB, want, got []byte) bool {
+	t.Helper()
+	diffs, err := yaml.Diff(want, got)
+	if err != nil {
+		t.Errorf("yamltest: %v", err)
+		return false
+	}
+	if len(diffs) > 0 {
+		lines := make([]string, len(diffs))
+		for i, d := range diffs {
+			lines[i] = "\t" + d.String()
+		}
+		t.Errorf("yamltest: documents differ:\n%s\ngot:\n%s", strings.Join(lines, "\n"), got)
+		return false
+	}
+	return true
+}
+
+// Subset reports an error if a value in the expected document is missing
+// from or different in the other, as checked by yaml.Subset with the
+// options. The other document may hold more values.
+func Subset(t testing.TB, want, got []byte, opts ...yaml.SubsetOpt) bool {
+	t.Helper()
+	diffs, err := yaml.Subset(want, got, opts...)
+	if err != nil {
+		t.Errorf("yamltest: %v", err)
+		return false
+	}
+	if len(diffs) > 0 {
+		lines := make([]string, len(diffs))
+		for i, d := range diffs {
+			lines[i] = "\t" + d.String()
+		}
+		t.Errorf("yamltest: document is not a superset:\n%s\ngot:\n%s", strings.Join(lines, "\n"), got)
+		return false
+	}
+	return true
+}
+
+// EqualValue marshals the value and compares it with the expected YAML.
+func EqualValue(t testing.TB, want []byte, v interface{}) bool {
+	t.Helper()
+	got, err := yaml.Marshal(v)
+	if err != nil {
+		t.Errorf("yamltest: %v", err)
+		return false
+	}
+	return Equal(t, want, got)
+}
+
+// Golden compares the output with the contents of the golden file at the
+// path using Equal. When Update is set the file is written instead.
+func Golden(t testing.TB, path string, got []byte) bool {
+	t.Helper()
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("yamltest: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("yamltest: %v", err)
+		}
+		return true
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Errorf("yamltest: golden file %s does not exist, set YAMLTEST_UPDATE=1 to create it", path)
+		return false
+	}
+	if err != nil {
+		t.Fatalf("yamltest: %v", err)
+	}
+	return Equal(t, want, got)
+}
+
+// RoundTrip marshals the value, unmarshals the result into a new value of
+// the same type and reports an error if the two are not deeply equal. The
+// YAML produced is returned for further checks.
+func RoundTrip(t testing.TB, v interface{}) []byte {
+	t.Helper()
+	y, err := yaml.Marshal(v)
+	if err != nil {
+		t.Errorf("yamltest: marshal: %v", err)
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	out := reflect.New(rv.Type())
+	if err := yaml.Unmarshal(y, out.Interface()); err != nil {
+		t.Errorf("yamltest: unmarshal: %v\n%s", err, y)
+		return y
+	}
+	if got := out.Elem().Interface(); !reflect.DeepEqual(v, got) {
+		t.Errorf("yamltest: round trip mismatch:\nwant: %#v\ngot:  %#v\nyaml:\n%s", v, got, y)
+	}
+	return y
+}
@@ -0,0 +1,98 @@
+package yamltest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/invopop/yaml"
+)
+
+// recorder captures failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestEqual(t *testing.T) {
+	r := &recorder{TB: t}
+	if !Equal(r, []byte("a: 1\nb: [x]\n"), []byte("# comment\nb:\n  - x\na: 1\n")) {
+		t.Errorf("Equal() = false; want true: %v", r.errors)
+	}
+	r = &recorder{TB: t}
+	if Equal(r, []byte("a: 1\n"), []byte("a: 2\n")) {
+		t.Errorf("Equal() = true; want false")
+	}
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "~ /a: 1 -> 2") {
+		t.Errorf("Equal() errors = %q", r.errors)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	got := []byte("name: web\nports: [80, 443]\nstatus: {ready: true}\n")
+	r := &recorder{TB: t}
+	if !Subset(r, []byte("ports: [443]\nstatus: {}\n"), got, yaml.UnorderedLists()) {
+		t.Errorf("Subset() = false; want true: %v", r.errors)
+	}
+	r = &recorder{TB: t}
+	if Subset(r, []byte("name: api\n"), got) {
+		t.Errorf("Subset() = true; want false")
+	}
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "~ /name: \"api\" -> \"web\"") {
+		t.Errorf("Subset() errors = %q", r.errors)
+	}
+}
+
+func TestEqualValue(t *testing.T) {
+	v := struct {
+		Name string `json:"name"`
+	}{"web"}
+	EqualValue(t, []byte("name: web"), v)
+}
+
+type roundTrip struct {
+	Name  string            `json:"name"`
+	Ports []int             `json:"ports"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+type lossy struct {
+	Value float64 `json:"-"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	y := RoundTrip(t, roundTrip{Name: "web", Ports: []int{80}})
+	if string(y) != "name: web\nports:\n    - 80\n" {
+		t.Errorf("RoundTrip() = %q", y)
+	}
+	r := &recorder{TB: t}
+	RoundTrip(r, lossy{Value: 1})
+	if len(r.errors) != 1 {
+		t.Errorf("RoundTrip(lossy) errors = %q; want 1", r.errors)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.yaml")
+	r := &recorder{TB: t}
+	if Golden(r, path, []byte("a: 1\n")) {
+		t.Errorf("Golden(missing) = true; want false")
+	}
+
+	Update = true
+	defer func() { Update = false }()
+	Golden(t, path, []byte("a: 1\n"))
+	Update = false
+	Golden(t, path, []byte("a:   1 # same\n"))
+}